@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"taboo-game/types"
+)
+
+// SessionAuthenticator admits a connection or request only with a valid
+// session token for a player who is still a member of the game
+type SessionAuthenticator struct {
+	signer      *TokenSigner
+	gameService types.GameServiceInterface
+}
+
+func NewSessionAuthenticator(signer *TokenSigner, gameService types.GameServiceInterface) *SessionAuthenticator {
+	return &SessionAuthenticator{
+		signer:      signer,
+		gameService: gameService,
+	}
+}
+
+// Authenticate reads the token from an "Authorization: Bearer" header or,
+// since browsers cannot set headers on a WebSocket, the ?token= parameter.
+// It returns the HTTP status to reject the request with.
+func (a *SessionAuthenticator) Authenticate(r *http.Request, gameID, playerID string) (int, error) {
+	caller, status, err := a.Identify(r, gameID)
+	if err != nil {
+		return status, err
+	}
+	if caller != playerID {
+		return http.StatusForbidden, errors.New("session token is for another player")
+	}
+	return http.StatusOK, nil
+}
+
+// Identify returns the player a request's session token was issued to,
+// once the token is found to be for the game and the player is still in it.
+// It also returns the HTTP status to reject the request with.
+func (a *SessionAuthenticator) Identify(r *http.Request, gameID string) (string, int, error) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	if token == "" {
+		return "", http.StatusUnauthorized, errors.New("session token is required")
+	}
+
+	claims, err := a.signer.Verify(token)
+	if err != nil {
+		return "", http.StatusUnauthorized, err
+	}
+	if claims.GameID != gameID {
+		return "", http.StatusForbidden, errors.New("session token is for another game")
+	}
+
+	game, err := a.gameService.GetGame(gameID)
+	if err != nil {
+		return "", http.StatusNotFound, err
+	}
+	for _, team := range game.Teams {
+		for _, player := range team.Players {
+			if player.ID == claims.PlayerID {
+				return claims.PlayerID, http.StatusOK, nil
+			}
+		}
+	}
+	return "", http.StatusForbidden, errors.New("player is not in this game")
+}
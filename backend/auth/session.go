@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid session token")
+	ErrExpiredToken = errors.New("session token has expired")
+)
+
+// SessionClaims says which player of which game a token was issued to
+type SessionClaims struct {
+	GameID    string    `json:"gameId"`
+	PlayerID  string    `json:"playerId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// TokenSigner issues and verifies HMAC-signed session tokens. A token is
+// the base64url JSON claims and their base64url HMAC-SHA256, joined by a dot.
+type TokenSigner struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewTokenSigner(secret []byte, ttl time.Duration) (*TokenSigner, error) {
+	if len(secret) < 32 {
+		return nil, errors.New("session secret must be at least 32 bytes")
+	}
+	return &TokenSigner{
+		secret: secret,
+		ttl:    ttl,
+	}, nil
+}
+
+// Issue returns a token for a player who has joined a game
+func (s *TokenSigner) Issue(gameID, playerID string) (string, error) {
+	claims, err := json.Marshal(SessionClaims{
+		GameID:    gameID,
+		PlayerID:  playerID,
+		ExpiresAt: time.Now().Add(s.ttl),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error encoding session claims: %v", err)
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(claims)
+	return payload + "." + s.sign(payload), nil
+}
+
+// Verify checks a token's signature and expiry and returns its claims
+func (s *TokenSigner) Verify(token string) (*SessionClaims, error) {
+	payload, signature, found := strings.Cut(token, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(s.sign(payload))) {
+		return nil, ErrInvalidToken
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims SessionClaims
+	if err := json.Unmarshal(decoded, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().After(claims.ExpiresAt) {
+		return nil, ErrExpiredToken
+	}
+	return &claims, nil
+}
+
+func (s *TokenSigner) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,32 @@
+// Command protocolgen writes the WebSocket protocol's TypeScript definitions
+// and JSON Schema for the frontend. Run it with go generate ./websocket.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"taboo-game/protocolgen"
+)
+
+func main() {
+	tsPath := flag.String("ts", "../frontend/src/types/protocol.ts", "TypeScript output file")
+	schemaPath := flag.String("schema", "../frontend/src/types/protocol.schema.json", "JSON Schema output file")
+	flag.Parse()
+
+	ts, err := protocolgen.TypeScript()
+	if err != nil {
+		log.Fatalf("Failed to generate TypeScript: %v", err)
+	}
+	if err := os.WriteFile(*tsPath, []byte(ts), 0644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *tsPath, err)
+	}
+
+	schema, err := protocolgen.JSONSchema()
+	if err != nil {
+		log.Fatalf("Failed to generate JSON Schema: %v", err)
+	}
+	if err := os.WriteFile(*schemaPath, schema, 0644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *schemaPath, err)
+	}
+}
@@ -14,7 +14,838 @@ const docTemplate = `{
     },
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
-    "paths": {}
+    "paths": {
+        "/games": {
+            "post": {
+                "description": "Create a new game session with specified team size",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Create a new game",
+                "parameters": [
+                    {
+                        "description": "teamSize: 3 or 4",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Game"
+                        }
+                    }
+                }
+            }
+        },
+        "/games/{gameId}": {
+            "get": {
+                "description": "Get details of a specific game",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Get game details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Game"
+                        }
+                    }
+                }
+            }
+        },
+        "/games/{gameId}/join": {
+            "post": {
+                "description": "Add a new player to an existing game",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Join a game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "playerName",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Player"
+                        }
+                    }
+                }
+            }
+        },
+        "/games/{gameId}/matches/{matchId}/guess": {
+            "post": {
+                "description": "Correct guesses are judged by the server from MAKE_GUESS messages, and violations are reported through the violations endpoint. Both are rejected here.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Record a missed guess on the current card",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "matchId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Guess attempt details",
+                        "name": "attempt",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GuessAttempt"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                }
+            }
+        },
+        "/games/{gameId}/matches/{matchId}/score": {
+            "post": {
+                "description": "Let the game's host award a point to one team of a running match",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Score a point",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "matchId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "isTeamA: whether teamA scores",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MatchDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/games/{gameId}/matches/{matchId}/start": {
+            "post": {
+                "description": "Start a new match in a game",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Start a match",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "matchId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "teamAssignments: player IDs of teamA and teamB",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MatchDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/games/{gameId}/matches/{matchId}/teams/switch/{playerId}": {
+            "post": {
+                "description": "Move a player from one team to another",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Switch player team",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "matchId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.ClueEvidence": {
+            "type": "object",
+            "properties": {
+                "cardWord": {
+                    "type": "string"
+                },
+                "clue": {
+                    "type": "string"
+                },
+                "clueWord": {
+                    "type": "string"
+                },
+                "match": {
+                    "$ref": "#/definitions/models.ClueMatch"
+                },
+                "reason": {
+                    "description": "Human-readable explanation of the match",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ClueMatch": {
+            "type": "string",
+            "enum": [
+                "exact",
+                "stem",
+                "compound",
+                "part",
+                "near",
+                "sounds_like",
+                "rhyme"
+            ],
+            "x-enum-comments": {
+                "ClueMatchCompound": "Card word inside a clue word, or split across clue words",
+                "ClueMatchNear": "Spelled almost like a card word",
+                "ClueMatchPart": "Clue word is part of a card word",
+                "ClueMatchRhyme": "Same ending from the last vowel sounds",
+                "ClueMatchSoundsLike": "Same Double Metaphone code as a card word",
+                "ClueMatchStem": "Another form of the card word"
+            },
+            "x-enum-varnames": [
+                "ClueMatchExact",
+                "ClueMatchStem",
+                "ClueMatchCompound",
+                "ClueMatchPart",
+                "ClueMatchNear",
+                "ClueMatchSoundsLike",
+                "ClueMatchRhyme"
+            ]
+        },
+        "models.ClueRules": {
+            "type": "object",
+            "properties": {
+                "soundsLike": {
+                    "$ref": "#/definitions/models.SoundsLikeSensitivity"
+                }
+            }
+        },
+        "models.Game": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "hostId": {
+                    "description": "First player to join",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "matches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Match"
+                    }
+                },
+                "rules": {
+                    "$ref": "#/definitions/models.GameRules"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.GameStatus"
+                },
+                "teams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Team"
+                    }
+                },
+                "version": {
+                    "description": "Number of changes to the game, used as its ETag",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GameRules": {
+            "type": "object",
+            "properties": {
+                "clues": {
+                    "$ref": "#/definitions/models.ClueRules"
+                },
+                "guessing": {
+                    "$ref": "#/definitions/models.GuessRules"
+                },
+                "violationRules": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.ViolationRule"
+                    }
+                }
+            }
+        },
+        "models.GameStatus": {
+            "type": "string",
+            "enum": [
+                "waiting",
+                "in_progress",
+                "completed"
+            ],
+            "x-enum-varnames": [
+                "GameStatusWaiting",
+                "GameStatusInProgress",
+                "GameStatusCompleted"
+            ]
+        },
+        "models.GuessAttempt": {
+            "type": "object",
+            "properties": {
+                "cardId": {
+                    "type": "string"
+                },
+                "correct": {
+                    "type": "boolean"
+                },
+                "guess": {
+                    "description": "Typed guess the server judged",
+                    "type": "string"
+                },
+                "playerId": {
+                    "type": "string"
+                },
+                "stageId": {
+                    "type": "string"
+                },
+                "teamId": {
+                    "type": "string"
+                },
+                "timestampMs": {
+                    "type": "integer"
+                },
+                "verdict": {
+                    "$ref": "#/definitions/models.GuessVerdict"
+                },
+                "violation": {
+                    "type": "boolean"
+                },
+                "violationType": {
+                    "$ref": "#/definitions/models.ViolationType"
+                }
+            }
+        },
+        "models.GuessRules": {
+            "type": "object",
+            "properties": {
+                "closeDistance": {
+                    "description": "Edits within which a wrong guess is hinted as close",
+                    "type": "integer"
+                },
+                "maxTypos": {
+                    "description": "Edits still accepted as correct, for answers of 4 or more letters",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GuessVerdict": {
+            "type": "string",
+            "enum": [
+                "correct",
+                "close",
+                "wrong"
+            ],
+            "x-enum-comments": {
+                "GuessClose": "Wrong, but within the close distance"
+            },
+            "x-enum-varnames": [
+                "GuessCorrect",
+                "GuessClose",
+                "GuessWrong"
+            ]
+        },
+        "models.Match": {
+            "type": "object",
+            "properties": {
+                "endedAt": {
+                    "type": "string"
+                },
+                "gameId": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "stages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Stage"
+                    }
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.MatchStatus"
+                }
+            }
+        },
+        "models.MatchDetails": {
+            "type": "object",
+            "properties": {
+                "currentStage": {
+                    "$ref": "#/definitions/models.MatchStage"
+                },
+                "currentWord": {
+                    "type": "string"
+                },
+                "gameId": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.MatchStatus"
+                },
+                "teamAPlayers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "teamAScore": {
+                    "type": "integer"
+                },
+                "teamATurn": {
+                    "type": "boolean"
+                },
+                "teamBPlayers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "teamBScore": {
+                    "type": "integer"
+                },
+                "violationCounts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.MatchStage": {
+            "type": "object",
+            "properties": {
+                "activeTeamId": {
+                    "type": "string"
+                },
+                "clueGivers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "guessers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "matchId": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "spotters": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "spottingTeamId": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "teamAScore": {
+                    "type": "integer"
+                },
+                "teamBScore": {
+                    "type": "integer"
+                },
+                "transcript": {
+                    "description": "Clue-givers' transcribed speech",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TranscriptChunk"
+                    }
+                },
+                "violationCounts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.MatchStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "in_progress",
+                "completed"
+            ],
+            "x-enum-varnames": [
+                "MatchStatusPending",
+                "MatchStatusInProgress",
+                "MatchStatusCompleted"
+            ]
+        },
+        "models.Player": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "joinedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "teamId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SoundsLikeSensitivity": {
+            "type": "string",
+            "enum": [
+                "off",
+                "low",
+                "medium",
+                "high"
+            ],
+            "x-enum-comments": {
+                "SoundsLikeHigh": "Also rhymes on the last syllable alone",
+                "SoundsLikeLow": "Same primary pronunciation only",
+                "SoundsLikeMedium": "Also alternate pronunciations and rhymes on the last two syllables"
+            },
+            "x-enum-varnames": [
+                "SoundsLikeOff",
+                "SoundsLikeLow",
+                "SoundsLikeMedium",
+                "SoundsLikeHigh"
+            ]
+        },
+        "models.Stage": {
+            "type": "object",
+            "properties": {
+                "activeTeamId": {
+                    "description": "Active team details",
+                    "type": "string"
+                },
+                "clueGivers": {
+                    "description": "Player IDs of the 2 clue givers",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "duration": {
+                    "description": "180 seconds (3 minutes)",
+                    "type": "integer"
+                },
+                "endedAt": {
+                    "type": "string"
+                },
+                "guessers": {
+                    "description": "Player IDs of the guessers",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "matchId": {
+                    "type": "string"
+                },
+                "number": {
+                    "description": "1, 2, 3, or 4",
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "spotters": {
+                    "description": "Player IDs of the 2 spotters",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "spottingTeamId": {
+                    "description": "Spotting team details",
+                    "type": "string"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.StageStatus"
+                }
+            }
+        },
+        "models.StageStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "active",
+                "completed"
+            ],
+            "x-enum-varnames": [
+                "StageStatusPending",
+                "StageStatusActive",
+                "StageStatusCompleted"
+            ]
+        },
+        "models.Team": {
+            "type": "object",
+            "properties": {
+                "gameId": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Player"
+                    }
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "size": {
+                    "description": "3 or 4 players",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TranscriptChunk": {
+            "type": "object",
+            "properties": {
+                "durationMs": {
+                    "type": "integer"
+                },
+                "flags": {
+                    "description": "What the clue checks found in it",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ClueEvidence"
+                    }
+                },
+                "playerId": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "timestampMs": {
+                    "description": "When the speech started",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ViolationRule": {
+            "type": "object",
+            "properties": {
+                "offenderPenalty": {
+                    "description": "Points deducted from the clue-giving team",
+                    "type": "integer"
+                },
+                "spotterReward": {
+                    "description": "Points awarded to the spotting team",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ViolationType": {
+            "type": "string",
+            "enum": [
+                "taboo_word",
+                "partial_word",
+                "sounds_like",
+                "gesture",
+                "other"
+            ],
+            "x-enum-comments": {
+                "ViolationTypeSoundsLike": "Includes rhyming hints"
+            },
+            "x-enum-varnames": [
+                "ViolationTypeTabooWord",
+                "ViolationTypePartial",
+                "ViolationTypeSoundsLike",
+                "ViolationTypeGesture",
+                "ViolationTypeOther"
+            ]
+        }
+    }
 }`
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"taboo-game/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventLogHandler struct {
+	eventLog types.EventLogInterface
+	roles    types.RoleResolver
+}
+
+func NewEventLogHandler(eventLog types.EventLogInterface) *EventLogHandler {
+	return &EventLogHandler{
+		eventLog: eventLog,
+	}
+}
+
+// SetRoleResolver lets callers who can see the current card read the log
+// unredacted. Without it every caller gets the redacted log.
+func (h *EventLogHandler) SetRoleResolver(roles types.RoleResolver) {
+	h.roles = roles
+}
+
+// GetEvents returns the game's event log. Unless the caller may see the
+// current card, events that could give a card away are redacted.
+func (h *EventLogHandler) GetEvents(c *gin.Context) {
+	gameID := c.Param("gameId")
+
+	events, err := h.eventLog.Events(gameID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if h.roles == nil || !h.roles.PlayerRole(gameID, sessionPlayer(c)).CanSeeCard() {
+		for i := range events {
+			events[i] = events[i].Redacted()
+		}
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+// ReplayGame returns the game as it was after the event given by ?version=N
+func (h *EventLogHandler) ReplayGame(c *gin.Context) {
+	gameID := c.Param("gameId")
+
+	version, err := strconv.Atoi(c.DefaultQuery("version", "0"))
+	if err != nil || version < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version must be a non-negative integer"})
+		return
+	}
+
+	game, err := h.eventLog.ReplayGame(gameID, version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, game)
+}
@@ -1,39 +0,0 @@
-package handlers
-
-import (
-	"taboo-game/services"
-)
-
-type GameEventsHandler struct {
-	gameService *services.GameService
-}
-
-func NewGameEventsHandler(gameService *services.GameService) *GameEventsHandler {
-	return &GameEventsHandler{
-		gameService: gameService,
-	}
-}
-
-// StartStage implements GameEventsServiceInterface
-func (h *GameEventsHandler) StartStage(gameID string, stageNum int) error {
-	// Implement game start logic
-	return nil
-}
-
-// HandleClue implements GameEventsServiceInterface
-func (h *GameEventsHandler) HandleClue(gameID string, playerID string, clue string) error {
-	// Implement clue handling logic
-	return nil
-}
-
-// HandleGuess implements GameEventsServiceInterface
-func (h *GameEventsHandler) HandleGuess(gameID string, playerID string, guess string) error {
-	// Implement guess handling logic
-	return nil
-}
-
-// HandleViolation implements GameEventsServiceInterface
-func (h *GameEventsHandler) HandleViolation(gameID string, reporterID string, violationType string) error {
-	// Implement violation handling logic
-	return nil
-}
@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"taboo-game/models"
 	"taboo-game/types"
 
 	"github.com/gin-gonic/gin"
@@ -9,6 +14,7 @@ import (
 
 type GameHandler struct {
 	gameService types.GameServiceInterface
+	sessions    types.SessionIssuer
 }
 
 func NewGameHandler(gameService types.GameServiceInterface) *GameHandler {
@@ -17,6 +23,20 @@ func NewGameHandler(gameService types.GameServiceInterface) *GameHandler {
 	}
 }
 
+// SetSessionIssuer makes joining a game return a session token for
+// connecting to it
+func (h *GameHandler) SetSessionIssuer(sessions types.SessionIssuer) {
+	h.sessions = sessions
+}
+
+// @Summary Create a new game
+// @Description Create a new game session with specified team size
+// @Tags games
+// @Accept json
+// @Produce json
+// @Param request body object true "teamSize: 3 or 4"
+// @Success 201 {object} models.Game
+// @Router /games [post]
 func (h *GameHandler) CreateGame(c *gin.Context) {
 	var req struct {
 		TeamSize int `json:"teamSize" binding:"required,oneof=3 4"`
@@ -33,56 +53,207 @@ func (h *GameHandler) CreateGame(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", gameETag(game))
 	c.JSON(http.StatusCreated, game)
 }
 
+// @Summary Join a game
+// @Description Add a new player to an existing game
+// @Tags players
+// @Accept json
+// @Produce json
+// @Param gameId path string true "Game ID"
+// @Param request body object true "playerName"
+// @Success 200 {object} models.Player
+// @Router /games/{gameId}/join [post]
 func (h *GameHandler) JoinGame(c *gin.Context) {
 	var req struct {
 		PlayerName string `json:"playerName" binding:"required"`
 	}
 
-	gameID := c.Param("id")
+	gameID := c.Param("gameId")
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	player, err := h.gameService.AddPlayer(gameID, req.PlayerName)
+	player, err := h.gameService.AddPlayer(gameID, req.PlayerName, ifMatchVersions(c))
+	if errors.Is(err, models.ErrVersionConflict) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if h.sessions == nil {
+		c.JSON(http.StatusOK, player)
+		return
+	}
+	token, err := h.sessions.Issue(gameID, player.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, player)
+	c.JSON(http.StatusOK, struct {
+		*models.Player
+		SessionToken string `json:"sessionToken"`
+	}{player, token})
 }
 
+// @Summary Get game details
+// @Description Get details of a specific game
+// @Tags games
+// @Produce json
+// @Param gameId path string true "Game ID"
+// @Success 200 {object} models.Game
+// @Router /games/{gameId} [get]
 func (h *GameHandler) GetGame(c *gin.Context) {
-	gameID := c.Param("id")
+	gameID := c.Param("gameId")
 	game, err := h.gameService.GetGame(gameID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
+
+	etag := gameETag(game)
+	c.Header("ETag", etag)
+	if etagListContains(c.GetHeader("If-None-Match"), etag) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return
+	}
 	c.JSON(http.StatusOK, game)
 }
 
 func (h *GameHandler) StartGame(c *gin.Context) {
-	gameID := c.Param("id")
-	game, err := h.gameService.StartGame(gameID)
-	if err != nil {
+	gameID := c.Param("gameId")
+	game, err := h.gameService.StartGame(gameID, ifMatchVersions(c))
+	if errors.Is(err, models.ErrVersionConflict) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	c.Header("ETag", gameETag(game))
 	c.JSON(http.StatusOK, game)
 }
 
 func (h *GameHandler) EndGame(c *gin.Context) {
-	gameID := c.Param("id")
-	game, err := h.gameService.EndGame(gameID)
-	if err != nil {
+	gameID := c.Param("gameId")
+	game, err := h.gameService.EndGame(gameID, ifMatchVersions(c))
+	if errors.Is(err, models.ErrVersionConflict) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	c.Header("ETag", gameETag(game))
 	c.JSON(http.StatusOK, game)
 }
+
+func (h *GameHandler) UpdateRules(c *gin.Context) {
+	gameID := c.Param("gameId")
+	var rules models.GameRules
+
+	if err := c.ShouldBindJSON(&rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := rules.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	game, err := h.gameService.GetGame(gameID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if game.HostID == "" || sessionPlayer(c) != game.HostID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the host can change the rules"})
+		return
+	}
+	ifMatch := c.GetHeader("If-Match")
+	if !ifMatchVersions(c).Allows(game.Version) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": models.ErrVersionConflict.Error()})
+		return
+	}
+
+	if game.Status != models.GameStatusWaiting {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rules can only be changed before the game starts"})
+		return
+	}
+
+	// Categories left out keep their current rule
+	if game.Rules.ViolationRules == nil {
+		game.Rules = models.DefaultGameRules()
+	}
+	for violationType, rule := range rules.ViolationRules {
+		game.Rules.ViolationRules[violationType] = rule
+	}
+	if rules.Guessing != nil {
+		game.Rules.Guessing = rules.Guessing
+	}
+	if rules.Clues != nil {
+		game.Rules.Clues = rules.Clues
+	}
+
+	// The game may still change between reading and writing it
+	if err := h.gameService.UpdateGame(game); errors.Is(err, models.ErrVersionConflict) {
+		status := http.StatusConflict
+		if ifMatch != "" {
+			status = http.StatusPreconditionFailed
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", gameETag(game))
+	c.JSON(http.StatusOK, game.Rules)
+}
+
+// ifMatchVersions reads the game versions a write's If-Match header accepts.
+// Writes without the header, or with "*", accept any version. The service
+// compares them with the game while holding its lock.
+func ifMatchVersions(c *gin.Context) models.VersionMatch {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		return nil
+	}
+	versions := models.VersionMatch{}
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil
+		}
+		if version, err := strconv.Atoi(strings.Trim(tag, `"`)); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+// gameETag is the ETag of a game, its version in quotes
+func gameETag(game *models.Game) string {
+	return fmt.Sprintf(`"%d"`, game.Version)
+}
+
+// etagListContains reports whether an If-Match or If-None-Match header
+// lists the ETag, or is "*"
+func etagListContains(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
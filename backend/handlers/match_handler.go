@@ -17,6 +17,16 @@ func NewMatchHandler(matchService types.MatchServiceInterface) *MatchHandler {
 	}
 }
 
+// @Summary Start a match
+// @Description Start a new match in a game
+// @Tags matches
+// @Accept json
+// @Produce json
+// @Param gameId path string true "Game ID"
+// @Param matchId path string true "Match ID"
+// @Param request body object true "teamAssignments: player IDs of teamA and teamB"
+// @Success 200 {object} models.MatchDetails
+// @Router /games/{gameId}/matches/{matchId}/start [post]
 func (h *MatchHandler) StartMatch(c *gin.Context) {
 	gameID := c.Param("gameId")
 	matchID := c.Param("matchId")
@@ -36,10 +46,21 @@ func (h *MatchHandler) StartMatch(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, match)
+	c.JSON(http.StatusOK, redactMatch(c, match))
 }
 
+// @Summary Score a point
+// @Description Let the game's host award a point to one team of a running match
+// @Tags matches
+// @Accept json
+// @Produce json
+// @Param gameId path string true "Game ID"
+// @Param matchId path string true "Match ID"
+// @Param request body object true "isTeamA: whether teamA scores"
+// @Success 200 {object} models.MatchDetails
+// @Router /games/{gameId}/matches/{matchId}/score [post]
 func (h *MatchHandler) ScorePoint(c *gin.Context) {
+	gameID := c.Param("gameId")
 	matchID := c.Param("matchId")
 	var req struct {
 		IsTeamA bool `json:"isTeamA"`
@@ -49,14 +70,18 @@ func (h *MatchHandler) ScorePoint(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if _, err := h.matchService.GetMatch(gameID, matchID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
 
-	match, err := h.matchService.ScorePoint(matchID, req.IsTeamA)
+	match, err := h.matchService.ScorePoint(matchID, sessionPlayer(c), req.IsTeamA)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, match)
+	c.JSON(http.StatusOK, redactMatch(c, match))
 }
 
 func (h *MatchHandler) EndMatch(c *gin.Context) {
@@ -68,7 +93,7 @@ func (h *MatchHandler) EndMatch(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, match)
+	c.JSON(http.StatusOK, redactMatch(c, match))
 }
 
 func (h *MatchHandler) CreateStage(c *gin.Context) {
@@ -100,9 +125,19 @@ func (h *MatchHandler) GetMatch(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, match)
+	c.JSON(http.StatusOK, redactMatch(c, match))
 }
 
+// @Summary Record a missed guess on the current card
+// @Description Correct guesses are judged by the server from MAKE_GUESS messages, and violations are reported through the violations endpoint. Both are rejected here.
+// @Tags matches
+// @Accept json
+// @Produce json
+// @Param gameId path string true "Game ID"
+// @Param matchId path string true "Match ID"
+// @Param attempt body models.GuessAttempt true "Guess attempt details"
+// @Success 200 {object} object
+// @Router /games/{gameId}/matches/{matchId}/guess [post]
 func (h *MatchHandler) ProcessGuessAttempt(c *gin.Context) {
 	gameID := c.Param("gameId")
 	matchID := c.Param("matchId")
@@ -113,6 +148,18 @@ func (h *MatchHandler) ProcessGuessAttempt(c *gin.Context) {
 		return
 	}
 
+	// Correct guesses are judged by the server from MAKE_GUESS messages
+	if attempt.Correct {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "correct guesses are checked by the server; send the guess as a MAKE_GUESS message"})
+		return
+	}
+	// Violations go through the report, dispute and vote workflow
+	if attempt.Violation {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "violations are reported through POST /api/v1/games/:gameId/violations"})
+		return
+	}
+	attempt.Guess, attempt.Verdict = "", ""
+
 	err := h.matchService.ProcessGuessAttempt(gameID, matchID, &attempt)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -122,19 +169,27 @@ func (h *MatchHandler) ProcessGuessAttempt(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Guess processed successfully"})
 }
 
+// @Summary Switch player team
+// @Description Move a player from one team to another
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param gameId path string true "Game ID"
+// @Param matchId path string true "Match ID"
+// @Param playerId path string true "Player ID"
+// @Success 200 {object} object
+// @Router /games/{gameId}/matches/{matchId}/teams/switch/{playerId} [post]
 func (h *MatchHandler) SwitchTeam(c *gin.Context) {
+	gameID := c.Param("gameId")
 	matchID := c.Param("matchId")
-	var req struct {
-		PlayerID string `json:"playerId"`
-		NewTeam  string `json:"newTeam"`
-	}
+	playerID := c.Param("playerId")
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if _, err := h.matchService.GetMatch(gameID, matchID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	_, err := h.matchService.SwitchTeam(matchID, req.PlayerID)
+	_, err := h.matchService.SwitchTeam(matchID, playerID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -142,3 +197,26 @@ func (h *MatchHandler) SwitchTeam(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Team switched successfully"})
 }
+
+func (h *MatchHandler) GetGameStats(c *gin.Context) {
+	gameID := c.Param("gameId")
+
+	stats, err := h.matchService.GetGameStats(gameID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *MatchHandler) GetScoreLedger(c *gin.Context) {
+	gameID := c.Param("gameId")
+	c.JSON(http.StatusOK, h.matchService.GetScoreLedger(gameID))
+}
+
+// redactMatch hides the current word unless the caller is a clue-giver or
+// spotter in the current stage
+func redactMatch(c *gin.Context, match *models.MatchDetails) *models.MatchDetails {
+	return match.RedactedFor(sessionPlayer(c))
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"taboo-game/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PresenceHandler struct {
+	presence    types.PresenceTracker
+	gameService types.GameServiceInterface
+}
+
+func NewPresenceHandler(presence types.PresenceTracker, gameService types.GameServiceInterface) *PresenceHandler {
+	return &PresenceHandler{
+		presence:    presence,
+		gameService: gameService,
+	}
+}
+
+// GetPresence lists every player that has connected to the game
+func (h *PresenceHandler) GetPresence(c *gin.Context) {
+	gameID := c.Param("gameId")
+	c.JSON(http.StatusOK, h.presence.GamePresence(gameID))
+}
+
+// GetSpectators returns the spectator count to the game's host
+func (h *PresenceHandler) GetSpectators(c *gin.Context) {
+	gameID := c.Param("gameId")
+
+	game, err := h.gameService.GetGame(gameID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if game.HostID == "" || sessionPlayer(c) != game.HostID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the host can see spectators"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"count": h.presence.SpectatorCount(gameID)})
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"taboo-game/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context key of the player a request's session token was issued to
+const sessionPlayerKey = "sessionPlayerID"
+
+// RequireSession admits a request only with a session token for a player
+// still in the game of the path, and makes that player the caller. The
+// token is read like the WebSocket's, from a Bearer header or ?token=.
+func RequireSession(sessions types.SessionIdentifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		playerID, status, err := sessions.Identify(c.Request, c.Param("gameId"))
+		if err != nil {
+			c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.Set(sessionPlayerKey, playerID)
+		c.Next()
+	}
+}
+
+// sessionPlayer returns the caller RequireSession verified
+func sessionPlayer(c *gin.Context) string {
+	return c.GetString(sessionPlayerKey)
+}
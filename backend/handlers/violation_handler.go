@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"taboo-game/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ViolationHandler struct {
+	violationService types.ViolationServiceInterface
+}
+
+func NewViolationHandler(violationService types.ViolationServiceInterface) *ViolationHandler {
+	return &ViolationHandler{
+		violationService: violationService,
+	}
+}
+
+func (h *ViolationHandler) ReportViolation(c *gin.Context) {
+	gameID := c.Param("gameId")
+	var req struct {
+		Type   string `json:"type" binding:"required"`
+		CardID string `json:"cardId"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	violation, err := h.violationService.ReportViolation(gameID, sessionPlayer(c), req.Type, req.CardID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, violation)
+}
+
+func (h *ViolationHandler) GetViolations(c *gin.Context) {
+	gameID := c.Param("gameId")
+	c.JSON(http.StatusOK, h.violationService.GetViolations(gameID))
+}
+
+func (h *ViolationHandler) DisputeViolation(c *gin.Context) {
+	gameID := c.Param("gameId")
+	violationID := c.Param("violationId")
+
+	violation, err := h.violationService.DisputeViolation(gameID, violationID, sessionPlayer(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, violation)
+}
+
+func (h *ViolationHandler) VoteOnViolation(c *gin.Context) {
+	gameID := c.Param("gameId")
+	violationID := c.Param("violationId")
+	var req struct {
+		Uphold bool `json:"uphold"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	violation, err := h.violationService.VoteOnViolation(gameID, violationID, sessionPlayer(c), req.Uphold)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, violation)
+}
+
+func (h *ViolationHandler) ResolveViolation(c *gin.Context) {
+	gameID := c.Param("gameId")
+	violationID := c.Param("violationId")
+	var req struct {
+		Uphold bool `json:"uphold"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	violation, err := h.violationService.ResolveViolation(gameID, violationID, sessionPlayer(c), req.Uphold)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, violation)
+}
+
+func (h *ViolationHandler) SetReferee(c *gin.Context) {
+	gameID := c.Param("gameId")
+	var req struct {
+		PlayerID string `json:"playerId" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.violationService.SetReferee(gameID, sessionPlayer(c), req.PlayerID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Referee assigned successfully"})
+}
@@ -0,0 +1,496 @@
+package helpers
+
+import "strings"
+
+// maxMetaphoneLength is the code length Double Metaphone traditionally stops at
+const maxMetaphoneLength = 4
+
+// DoubleMetaphone returns the primary and alternate Double Metaphone codes
+// of a tokenized word. Words that sound alike share a code, e.g. "dessert"
+// and "desert" both give "TSRT". This covers the English rules and the
+// common loanword spellings; the alternate code differs from the primary
+// one only where a spelling has two usual pronunciations.
+func DoubleMetaphone(word string) (primary, alternate string) {
+	m := &metaphone{word: strings.ToUpper(word)}
+	m.encode()
+	return m.primary.String(), m.alternate.String()
+}
+
+type metaphone struct {
+	word      string
+	primary   strings.Builder
+	alternate strings.Builder
+}
+
+func (m *metaphone) add(primary, alternate string) {
+	if m.primary.Len() < maxMetaphoneLength {
+		m.primary.WriteString(primary)
+	}
+	if m.alternate.Len() < maxMetaphoneLength {
+		m.alternate.WriteString(alternate)
+	}
+}
+
+func (m *metaphone) done() bool {
+	return m.primary.Len() >= maxMetaphoneLength && m.alternate.Len() >= maxMetaphoneLength
+}
+
+// at returns the letter at i, or 0 outside the word
+func (m *metaphone) at(i int) byte {
+	if i < 0 || i >= len(m.word) {
+		return 0
+	}
+	return m.word[i]
+}
+
+// stringAt reports whether any of the options starts at i
+func (m *metaphone) stringAt(i int, options ...string) bool {
+	if i < 0 {
+		return false
+	}
+	for _, option := range options {
+		if strings.HasPrefix(m.word[min(i, len(m.word)):], option) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *metaphone) isVowel(i int) bool {
+	return strings.IndexByte("AEIOUY", m.at(i)) >= 0
+}
+
+// germanic reports spellings whose CH, G, TH and W keep their hard sounds
+func (m *metaphone) germanic() bool {
+	return m.stringAt(0, "SCH")
+}
+
+func (m *metaphone) encode() {
+	last := len(m.word) - 1
+	i := 0
+
+	// Silent first letters and a leading X
+	switch {
+	case m.stringAt(0, "GN", "KN", "PN", "WR", "PS"):
+		i = 1
+	case m.at(0) == 'X':
+		m.add("S", "S")
+		i = 1
+	}
+
+	for i <= last && !m.done() {
+		c := m.at(i)
+		switch c {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if i == 0 {
+				m.add("A", "A")
+			}
+			i++
+
+		case 'B':
+			m.add("P", "P")
+			i += m.skipDouble(i, 'B')
+
+		case 'C':
+			i += m.encodeC(i)
+
+		case 'D':
+			switch {
+			case m.stringAt(i, "DG") && strings.IndexByte("IEY", m.at(i+2)) >= 0:
+				m.add("J", "J")
+				i += 3
+			case m.stringAt(i, "DG"):
+				m.add("TK", "TK")
+				i += 2
+			case m.stringAt(i, "DT", "DD"):
+				m.add("T", "T")
+				i += 2
+			default:
+				m.add("T", "T")
+				i++
+			}
+
+		case 'F':
+			m.add("F", "F")
+			i += m.skipDouble(i, 'F')
+
+		case 'G':
+			i += m.encodeG(i)
+
+		case 'H':
+			// Only sounded between vowels or at the start before one
+			if (i == 0 || m.isVowel(i-1)) && m.isVowel(i+1) {
+				m.add("H", "H")
+				i += 2
+			} else {
+				i++
+			}
+
+		case 'J':
+			switch {
+			case m.stringAt(i, "JOSE"):
+				m.add("H", "H")
+			case i == 0:
+				m.add("J", "A")
+			case i == last:
+				m.add("J", "")
+			case m.isVowel(i-1) && (m.at(i+1) == 'A' || m.at(i+1) == 'O'):
+				m.add("J", "H")
+			default:
+				m.add("J", "J")
+			}
+			i += m.skipDouble(i, 'J')
+
+		case 'K':
+			m.add("K", "K")
+			i += m.skipDouble(i, 'K')
+
+		case 'L':
+			m.add("L", "L")
+			i += m.skipDouble(i, 'L')
+
+		case 'M':
+			m.add("M", "M")
+			// The silent B of "dumb" and "thumber"
+			if m.stringAt(i-1, "UMB") && (i+1 == last || m.stringAt(i+2, "ER")) {
+				i += 2
+			} else {
+				i += m.skipDouble(i, 'M')
+			}
+
+		case 'N':
+			m.add("N", "N")
+			i += m.skipDouble(i, 'N')
+
+		case 'P':
+			switch {
+			case m.at(i+1) == 'H':
+				m.add("F", "F")
+				i += 2
+			case m.at(i+1) == 'P' || m.at(i+1) == 'B':
+				m.add("P", "P")
+				i += 2
+			default:
+				m.add("P", "P")
+				i++
+			}
+
+		case 'Q':
+			m.add("K", "K")
+			i += m.skipDouble(i, 'Q')
+
+		case 'R':
+			// French endings such as "Rogier"
+			if i == last && m.stringAt(i-2, "IE") && !m.stringAt(i-4, "ME", "MA") {
+				m.add("", "R")
+			} else {
+				m.add("R", "R")
+			}
+			i += m.skipDouble(i, 'R')
+
+		case 'S':
+			i += m.encodeS(i, last)
+
+		case 'T':
+			i += m.encodeT(i)
+
+		case 'V':
+			m.add("F", "F")
+			i += m.skipDouble(i, 'V')
+
+		case 'W':
+			i += m.encodeW(i, last)
+
+		case 'X':
+			// Silent at the end of French words such as "bordeaux"
+			if !(i == last && (m.stringAt(i-3, "IAU", "EAU") || m.stringAt(i-2, "AU", "OU"))) {
+				m.add("KS", "KS")
+			}
+			if m.at(i+1) == 'C' || m.at(i+1) == 'X' {
+				i += 2
+			} else {
+				i++
+			}
+
+		case 'Z':
+			switch {
+			case m.at(i+1) == 'H':
+				m.add("J", "J")
+				i += 2
+			case m.stringAt(i+1, "ZO", "ZI", "ZA"):
+				m.add("S", "TS")
+				i += 2
+			default:
+				m.add("S", "S")
+				i += m.skipDouble(i, 'Z')
+			}
+
+		default:
+			i++
+		}
+	}
+}
+
+// skipDouble returns how far to advance past a letter and its double
+func (m *metaphone) skipDouble(i int, letter byte) int {
+	if m.at(i+1) == letter {
+		return 2
+	}
+	return 1
+}
+
+func (m *metaphone) encodeC(i int) int {
+	switch {
+	case m.stringAt(i, "CHAE"):
+		m.add("K", "X")
+		return 2
+	case i == 0 && (m.stringAt(i+1, "HARAC", "HARIS") || m.stringAt(i+1, "HOR", "HYM", "HIA", "HEM")) && !m.stringAt(0, "CHORE"):
+		// Greek roots: "character", "chorus", "chemistry"
+		m.add("K", "K")
+		return 2
+	case m.stringAt(i, "CH"):
+		switch {
+		case m.germanic() || m.stringAt(i-2, "ORCHES", "ARCHIT", "ORCHID") ||
+			strings.IndexByte("LRNMBHFVW", m.at(i+2)) >= 0 || m.stringAt(0, "MC"):
+			m.add("K", "K")
+		case i > 0:
+			m.add("X", "K")
+		default:
+			m.add("X", "X")
+		}
+		return 2
+	case m.stringAt(i, "CZ") && !m.stringAt(i-2, "WICZ"):
+		m.add("S", "X")
+		return 2
+	case m.stringAt(i, "CIA"):
+		m.add("X", "X")
+		return 3
+	case m.stringAt(i, "CC") && !(i == 1 && m.at(0) == 'M'):
+		// "accident" and "success", but not "McCall" or "bacchus"
+		if strings.IndexByte("IEH", m.at(i+2)) >= 0 && !m.stringAt(i+2, "HU") {
+			if (i == 1 && m.at(0) == 'A') || m.stringAt(i-1, "UCCEE", "UCCES") {
+				m.add("KS", "KS")
+			} else {
+				m.add("X", "X")
+			}
+			return 3
+		}
+		m.add("K", "K")
+		return 2
+	case m.stringAt(i, "CK", "CG", "CQ"):
+		m.add("K", "K")
+		return 2
+	case m.stringAt(i, "CI", "CE", "CY"):
+		if m.stringAt(i, "CIO", "CIE", "CIA") {
+			m.add("S", "X")
+		} else {
+			m.add("S", "S")
+		}
+		return 2
+	}
+
+	m.add("K", "K")
+	if strings.IndexByte("CKQ", m.at(i+1)) >= 0 && !m.stringAt(i+1, "CE", "CI") {
+		return 2
+	}
+	return 1
+}
+
+func (m *metaphone) encodeG(i int) int {
+	next := m.at(i + 1)
+	switch {
+	case next == 'H':
+		switch {
+		case i > 0 && !m.isVowel(i-1):
+			m.add("K", "K")
+		case i == 0:
+			if m.at(i+2) == 'I' {
+				m.add("J", "J")
+			} else {
+				m.add("K", "K")
+			}
+		case strings.IndexByte("BHD", m.at(i-2)) >= 0,
+			strings.IndexByte("BHD", m.at(i-3)) >= 0,
+			strings.IndexByte("BH", m.at(i-4)) >= 0:
+			// Silent in "bough", "hugh" and "broughton"
+		case i > 2 && m.at(i-1) == 'U' && strings.IndexByte("CGLRT", m.at(i-3)) >= 0:
+			// "laugh", "tough", "cough"
+			m.add("F", "F")
+		case i > 0 && m.at(i-1) != 'I':
+			m.add("K", "K")
+		}
+		return 2
+
+	case next == 'N':
+		if i == 1 && m.isVowel(0) {
+			m.add("KN", "N")
+		} else if !m.stringAt(i+2, "EY") && m.at(i+1) != 'Y' {
+			m.add("N", "KN")
+		} else {
+			m.add("KN", "KN")
+		}
+		return 2
+
+	case m.stringAt(i+1, "LI"):
+		m.add("KL", "L")
+		return 2
+
+	case i == 0 && (next == 'Y' || m.stringAt(i+1, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		m.add("K", "J")
+		return 2
+
+	case (m.stringAt(i+1, "ER") || next == 'Y') && !m.stringAt(0, "DANGER", "RANGER", "MANGER") &&
+		m.at(i-1) != 'E' && m.at(i-1) != 'I' && !m.stringAt(i-1, "RGY", "OGY"):
+		m.add("K", "J")
+		return 2
+
+	case next == 'E' || next == 'I' || next == 'Y' || m.stringAt(i-1, "AGGI", "OGGI"):
+		switch {
+		case m.germanic() || m.stringAt(i+1, "ET"):
+			m.add("K", "K")
+		case m.stringAt(i+1, "IER"):
+			m.add("J", "J")
+		default:
+			m.add("J", "K")
+		}
+		return 2
+	}
+
+	m.add("K", "K")
+	return m.skipDouble(i, 'G')
+}
+
+func (m *metaphone) encodeS(i, last int) int {
+	switch {
+	case m.stringAt(i-1, "ISL", "YSL"):
+		// Silent in "island" and "isle"
+		return 1
+	case i == 0 && m.stringAt(i, "SUGAR"):
+		m.add("X", "S")
+		return 1
+	case m.stringAt(i, "SH"):
+		if m.stringAt(i+1, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			m.add("S", "S")
+		} else {
+			m.add("X", "X")
+		}
+		return 2
+	case m.stringAt(i, "SIO", "SIA"):
+		m.add("S", "X")
+		return 3
+	case (i == 0 && strings.IndexByte("MNLW", m.at(i+1)) >= 0) || m.stringAt(i+1, "Z"):
+		m.add("S", "X")
+		if m.at(i+1) == 'Z' {
+			return 2
+		}
+		return 1
+	case m.stringAt(i, "SC"):
+		switch {
+		case m.at(i+2) == 'H':
+			if m.stringAt(i+3, "OO", "ER", "EN", "UY", "ED", "EM") {
+				// "school", "schooner", "schenker"
+				m.add("SK", "SK")
+			} else if i == 0 && !m.isVowel(3) && m.at(3) != 'W' {
+				m.add("X", "S")
+			} else {
+				m.add("X", "X")
+			}
+		case strings.IndexByte("IEY", m.at(i+2)) >= 0:
+			m.add("S", "S")
+		default:
+			m.add("SK", "SK")
+		}
+		return 3
+	case i == last && m.stringAt(i-2, "AI", "OI"):
+		// Silent at the end of French words such as "bois"
+		m.add("", "S")
+		return 1
+	}
+
+	m.add("S", "S")
+	if m.at(i+1) == 'S' || m.at(i+1) == 'Z' {
+		return 2
+	}
+	return 1
+}
+
+func (m *metaphone) encodeT(i int) int {
+	switch {
+	case m.stringAt(i, "TION", "TIA", "TCH"):
+		m.add("X", "X")
+		return 3
+	case m.stringAt(i, "TH", "TTH"):
+		if m.stringAt(i+2, "OM", "AM") || m.germanic() {
+			m.add("T", "T")
+		} else {
+			m.add("0", "T") // 0 stands for "th"
+		}
+		return 2
+	case m.stringAt(i, "TT", "TD"):
+		m.add("T", "T")
+		return 2
+	}
+	m.add("T", "T")
+	return 1
+}
+
+func (m *metaphone) encodeW(i, last int) int {
+	switch {
+	case m.stringAt(i, "WR"):
+		m.add("R", "R")
+		return 2
+	case i == 0 && (m.isVowel(i+1) || m.stringAt(i, "WH")):
+		if m.isVowel(i + 1) {
+			m.add("A", "F")
+		} else {
+			m.add("A", "A")
+		}
+		return 1
+	case (i == last && m.isVowel(i-1)) || m.stringAt(i-1, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || m.stringAt(0, "SCH"):
+		m.add("", "F")
+		return 1
+	case m.stringAt(i, "WICZ", "WITZ"):
+		m.add("TS", "FX")
+		return 4
+	}
+	return 1
+}
+
+// RhymeKey returns the ending of a tokenized word from the start of its
+// last vowel sounds, so words that rhyme share it: "cake" and "bake" both
+// give "ak". syllables is how many trailing vowel groups to include; a
+// word with fewer gives its whole ending from the first vowel.
+func RhymeKey(word string, syllables int) string {
+	letters := []rune(word)
+	// A silent final e only lengthens the vowel before it
+	if n := len(letters); n > 2 && letters[n-1] == 'e' && !isRhymeVowel(letters, n-2) {
+		letters = letters[:n-1]
+	}
+
+	start := -1
+	for i := len(letters) - 1; i >= 0 && syllables > 0; i-- {
+		if !isRhymeVowel(letters, i) {
+			continue
+		}
+		for i > 0 && isRhymeVowel(letters, i-1) {
+			i--
+		}
+		start = i
+		syllables--
+	}
+	if start < 0 {
+		return ""
+	}
+	return string(letters[start:])
+}
+
+// isRhymeVowel treats y as a vowel except at the start of a word
+func isRhymeVowel(letters []rune, i int) bool {
+	switch letters[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return true
+	case 'y':
+		return i > 0
+	}
+	return false
+}
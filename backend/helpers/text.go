@@ -0,0 +1,105 @@
+package helpers
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeAnswer folds a guess or an answer for comparison: lower case,
+// without diacritics or punctuation, single-spaced, every word singular
+func NormalizeAnswer(text string) string {
+	words := Tokenize(text)
+	for i, word := range words {
+		words[i] = singular(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// Tokenize splits text into lower-case words without diacritics, treating
+// anything but letters and digits as a separator
+func Tokenize(text string) []string {
+	var folded strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(text)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining mark left over from a decomposed letter
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			folded.WriteRune(r)
+		default:
+			folded.WriteRune(' ')
+		}
+	}
+	return strings.Fields(folded.String())
+}
+
+// stemSuffixes are the inflections Stem removes, longest first
+var stemSuffixes = []string{"ingly", "ness", "ment", "edly", "ing", "est", "ed", "er", "ly"}
+
+// Stem reduces a tokenized word to a crude stem so that inflected forms
+// compare equal, e.g. "running", "runner" and "runs" all become "run", and
+// "baked" and "bake" both become "bak"
+func Stem(word string) string {
+	stem := singular(word)
+	for _, suffix := range stemSuffixes {
+		trimmed := strings.TrimSuffix(stem, suffix)
+		if trimmed == stem || utf8.RuneCountInString(trimmed) < 3 {
+			continue
+		}
+		stem = trimmed
+		// Undo the doubled consonant of "running" or "stopped"
+		runes := []rune(stem)
+		if n := len(runes); runes[n-1] == runes[n-2] && !strings.ContainsRune("aeiouls", runes[n-1]) {
+			stem = string(runes[:n-1])
+		}
+		break
+	}
+	if utf8.RuneCountInString(stem) > 3 {
+		stem = strings.TrimSuffix(stem, "e")
+	}
+	return stem
+}
+
+// singular strips common English plural endings. Guesses and answers go
+// through the same rules, so they only need to be consistent.
+func singular(word string) string {
+	switch {
+	case len(word) <= 3:
+		return word
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		return word
+	case strings.HasSuffix(word, "s"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+// EditDistance returns the Levenshtein distance between a and b in runes
+func EditDistance(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(target)]
+}
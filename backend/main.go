@@ -1,15 +1,22 @@
 package main
 
 import (
+	"crypto/rand"
 	"log"
+	"os"
+	"strings"
+	"taboo-game/auth"
 	"taboo-game/docs"
 	"taboo-game/handlers"
 	"taboo-game/routes"
 	"taboo-game/services"
+	"taboo-game/types"
 	"taboo-game/websocket"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -22,17 +29,79 @@ import (
 func main() {
 	r := gin.Default()
 
+	// Browser origins allowed to call the API and connect
+	allowedOrigins := []string{"http://localhost:5173"} // Vite default port
+	if origins := os.Getenv("ALLOWED_ORIGINS"); origins != "" {
+		allowedOrigins = strings.Split(origins, ",")
+	}
+
 	// CORS configuration
 	config := cors.DefaultConfig()
-	config.AllowOrigins = []string{"http://localhost:5173"} // Vite default port
+	config.AllowOrigins = allowedOrigins
+	config.AddAllowHeaders("If-Match", "If-None-Match")
+	config.AddExposeHeaders("ETag")
 	r.Use(cors.New(config))
 
+	// Session tokens are signed with SESSION_SECRET; without it they only
+	// last until the server restarts
+	sessionSecret := []byte(os.Getenv("SESSION_SECRET"))
+	if len(sessionSecret) == 0 {
+		log.Println("SESSION_SECRET is not set, using a random secret")
+		sessionSecret = make([]byte, 32)
+		if _, err := rand.Read(sessionSecret); err != nil {
+			log.Fatalf("Failed to generate session secret: %v", err)
+		}
+	}
+	tokenSigner, err := auth.NewTokenSigner(sessionSecret, 24*time.Hour)
+	if err != nil {
+		log.Fatalf("Invalid SESSION_SECRET: %v", err)
+	}
+
 	// Swagger documentation
 	docs.SwaggerInfo.BasePath = "/api/v1"
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Choose where games, matches and the event log every game is rebuilt
+	// from are kept
+	var eventStore types.EventStoreInterface
+	var repository interface {
+		types.GameRepository
+		types.MatchRepository
+	}
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "memory":
+		eventLogDir := os.Getenv("EVENT_LOG_DIR")
+		if eventLogDir == "" {
+			eventLogDir = "data/events"
+		}
+		fileStore, err := services.NewFileEventStore(eventLogDir)
+		if err != nil {
+			log.Fatalf("Failed to initialize event store: %v", err)
+		}
+		eventStore = fileStore
+		repository = services.NewMemoryRepository()
+	case "sqlite":
+		databasePath := os.Getenv("SQLITE_PATH")
+		if databasePath == "" {
+			databasePath = "data/taboo.db"
+		}
+		sqliteStore, err := services.OpenSQLiteStore(databasePath)
+		if err != nil {
+			log.Fatalf("Failed to open SQLite database: %v", err)
+		}
+		defer sqliteStore.Close()
+		eventStore = sqliteStore
+		repository = sqliteStore
+	default:
+		log.Fatalf("Unknown STORAGE_BACKEND %q, expected memory or sqlite", backend)
+	}
+
 	// Initialize core services
-	gameService := services.NewGameService()
+	gameService := services.NewGameServiceWithStore(eventStore)
+	gameService.SetRepository(repository)
+	if err := gameService.Rebuild(); err != nil {
+		log.Fatalf("Failed to rebuild games from event log: %v", err)
+	}
 	wordService, err := services.NewWordService("data")
 	if err != nil {
 		log.Fatalf("Failed to initialize word service: %v", err)
@@ -40,23 +109,86 @@ func main() {
 
 	// Initialize handlers first
 	gameHandler := handlers.NewGameHandler(gameService)
-	gameEventsHandler := handlers.NewGameEventsHandler(gameService)
+	gameHandler.SetSessionIssuer(tokenSigner)
+	eventLogHandler := handlers.NewEventLogHandler(gameService)
 
-	// Initialize websocket with the game events handler
-	wsManager := websocket.NewManager(gameEventsHandler)
+	// Initialize websocket; game events are attached once the services exist
+	wsManager := websocket.NewManager(nil)
+	wsManager.SetAllowedOrigins(allowedOrigins)
+	sessionAuthenticator := auth.NewSessionAuthenticator(tokenSigner, gameService)
+	wsManager.SetAuthenticator(sessionAuthenticator)
 	go wsManager.Run()
 
+	// With several instances, messages reach every instance through Redis
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		redisOptions, err := redis.ParseURL(redisURL)
+		if err != nil {
+			log.Fatalf("Invalid REDIS_URL: %v", err)
+		}
+		broadcaster := websocket.NewRedisBroadcaster(redis.NewClient(redisOptions), "taboo:")
+		if err := wsManager.SetBroadcaster(broadcaster); err != nil {
+			log.Fatalf("Failed to subscribe to Redis broadcasts: %v", err)
+		}
+	}
+
 	// Initialize services that depend on websocket
 	matchService := services.NewMatchService(gameService, wsManager)
-	services.NewGameEventsService(matchService, wordService, wsManager)
+	matchService.SetEventRecorder(gameService)
+	matchService.SetRepository(repository)
+	if err := matchService.Rebuild(eventStore); err != nil {
+		log.Fatalf("Failed to rebuild matches from event log: %v", err)
+	}
+	gameEventsService := services.NewGameEventsService(matchService, wordService, wsManager)
+	if err := gameEventsService.Rebuild(eventStore); err != nil {
+		log.Fatalf("Failed to rebuild violations from event log: %v", err)
+	}
+	wsManager.SetGameEvents(gameEventsService)
+	wsManager.SetSnapshotProvider(gameEventsService)
+	wsManager.SetPresenceListener(gameEventsService)
+
+	// Active games are snapshotted every SNAPSHOT_INTERVAL and carried on
+	// from the last snapshot, timers included, after a restart
+	snapshotDir := os.Getenv("SNAPSHOT_DIR")
+	if snapshotDir == "" {
+		snapshotDir = "data/snapshots"
+	}
+	snapshotInterval := 30 * time.Second
+	if interval := os.Getenv("SNAPSHOT_INTERVAL"); interval != "" {
+		snapshotInterval, err = time.ParseDuration(interval)
+		if err != nil || snapshotInterval <= 0 {
+			log.Fatalf("Invalid SNAPSHOT_INTERVAL %q", interval)
+		}
+	}
+	recoveryService, err := services.NewRecoveryService(snapshotDir, gameService, gameEventsService)
+	if err != nil {
+		log.Fatalf("Failed to initialize recovery snapshots: %v", err)
+	}
+	restored, err := recoveryService.Restore()
+	if err != nil {
+		log.Fatalf("Failed to restore games from snapshot: %v", err)
+	}
+	if len(restored) > 0 {
+		log.Printf("Restored %d games from %s", len(restored), recoveryService.Path())
+	}
+	go recoveryService.Run(snapshotInterval)
 
 	// Initialize handlers that depend on services
 	matchHandler := handlers.NewMatchHandler(matchService)
+	eventLogHandler.SetRoleResolver(matchService)
+	violationHandler := handlers.NewViolationHandler(gameEventsService)
+	presenceHandler := handlers.NewPresenceHandler(wsManager, gameService)
+
+	// REST calls made as a player carry the same session token as the
+	// WebSocket, and the player is taken from it
+	requireSession := handlers.RequireSession(sessionAuthenticator)
 
 	// Register routes
 	routes.SetupWebSocketRoutes(r, wsManager)
-	routes.NewGameRoutes(gameHandler).RegisterRoutes(r)
-	routes.NewMatchRoutes(matchHandler).RegisterRoutes(r)
+	routes.NewGameRoutes(gameHandler, requireSession).RegisterRoutes(r)
+	routes.NewEventLogRoutes(eventLogHandler, requireSession).RegisterRoutes(r)
+	routes.NewMatchRoutes(matchHandler, requireSession).RegisterRoutes(r)
+	routes.NewViolationRoutes(violationHandler, requireSession).RegisterRoutes(r)
+	routes.NewPresenceRoutes(presenceHandler, requireSession).RegisterRoutes(r)
 
 	// Health check
 	r.GET("/ping", func(c *gin.Context) {
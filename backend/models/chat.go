@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ChatChannelGame is the chat channel of the whole game. Each team also has
+// a private channel, "teamA" for the game's first team and "teamB" for the
+// second.
+const ChatChannelGame = "game"
+
+// ChatMessage is a message sent to one chat channel of a game
+type ChatMessage struct {
+	ID       string    `json:"id"`
+	GameID   string    `json:"gameId"`
+	Channel  string    `json:"channel"`
+	PlayerID string    `json:"playerId"`
+	Text     string    `json:"text"`
+	SentAt   time.Time `json:"sentAt"`
+}
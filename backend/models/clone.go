@@ -0,0 +1,86 @@
+package models
+
+// Clone returns a deep copy of the game that can be read or changed
+// without affecting the stored game
+func (g *Game) Clone() *Game {
+	clone := *g
+	clone.Teams = make([]Team, len(g.Teams))
+	for i, team := range g.Teams {
+		team.Players = append([]Player(nil), team.Players...)
+		clone.Teams[i] = team
+	}
+	clone.Matches = make([]Match, len(g.Matches))
+	for i, match := range g.Matches {
+		match.Stages = make([]Stage, len(match.Stages))
+		for j, stage := range g.Matches[i].Stages {
+			stage.ClueGivers = append([]string(nil), stage.ClueGivers...)
+			stage.Guessers = append([]string(nil), stage.Guessers...)
+			stage.Spotters = append([]string(nil), stage.Spotters...)
+			match.Stages[j] = stage
+		}
+		clone.Matches[i] = match
+	}
+	clone.Rules = g.Rules.Clone()
+	return &clone
+}
+
+// Clone returns a deep copy of the rules
+func (r GameRules) Clone() GameRules {
+	clone := r
+	if r.ViolationRules != nil {
+		clone.ViolationRules = make(map[ViolationType]ViolationRule, len(r.ViolationRules))
+		for violationType, rule := range r.ViolationRules {
+			clone.ViolationRules[violationType] = rule
+		}
+	}
+	if r.Guessing != nil {
+		guessing := *r.Guessing
+		clone.Guessing = &guessing
+	}
+	if r.Clues != nil {
+		clues := *r.Clues
+		clone.Clues = &clues
+	}
+	return clone
+}
+
+// Clone returns a deep copy of the match that can be read or changed
+// without affecting the stored match
+func (m *MatchDetails) Clone() *MatchDetails {
+	clone := *m
+	clone.TeamAPlayers = append([]string(nil), m.TeamAPlayers...)
+	clone.TeamBPlayers = append([]string(nil), m.TeamBPlayers...)
+	clone.ViolationCounts = cloneViolationCounts(m.ViolationCounts)
+	if m.CurrentStage != nil {
+		clone.CurrentStage = m.CurrentStage.Clone()
+	}
+	return &clone
+}
+
+// Clone returns a deep copy of the stage
+func (s *MatchStage) Clone() *MatchStage {
+	clone := *s
+	clone.ClueGivers = append([]string(nil), s.ClueGivers...)
+	clone.Guessers = append([]string(nil), s.Guessers...)
+	clone.Spotters = append([]string(nil), s.Spotters...)
+	clone.ViolationCounts = cloneViolationCounts(s.ViolationCounts)
+	if s.Transcript != nil {
+		clone.Transcript = make([]TranscriptChunk, len(s.Transcript))
+		for i, chunk := range s.Transcript {
+			chunk.Flags = append([]ClueEvidence(nil), chunk.Flags...)
+			clone.Transcript[i] = chunk
+		}
+	}
+	return &clone
+}
+
+func cloneViolationCounts(counts map[ViolationType]int) map[ViolationType]int {
+	if counts == nil {
+		return nil
+	}
+	clone := make(map[ViolationType]int, len(counts))
+	for violationType, count := range counts {
+		clone[violationType] = count
+	}
+	return clone
+}
@@ -25,3 +25,48 @@ const (
 	WordCategoryCommon WordCategory = "common"
 	WordCategoryDomain WordCategory = "domain"
 )
+
+// ViolationType represents the categories of rule violations a spotter can call
+type ViolationType string
+
+const (
+	ViolationTypeTabooWord  ViolationType = "taboo_word"
+	ViolationTypePartial    ViolationType = "partial_word"
+	ViolationTypeSoundsLike ViolationType = "sounds_like" // Includes rhyming hints
+	ViolationTypeGesture    ViolationType = "gesture"
+	ViolationTypeOther      ViolationType = "other"
+)
+
+// ViolationTypes lists every violation category in display order
+var ViolationTypes = []ViolationType{
+	ViolationTypeTabooWord,
+	ViolationTypePartial,
+	ViolationTypeSoundsLike,
+	ViolationTypeGesture,
+	ViolationTypeOther,
+}
+
+// IsValid reports whether the violation type is one of the defined categories
+func (t ViolationType) IsValid() bool {
+	for _, known := range ViolationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// PlayerRole represents what a player does during the current stage
+type PlayerRole string
+
+const (
+	PlayerRoleClueGiver PlayerRole = "clue_giver"
+	PlayerRoleGuesser   PlayerRole = "guesser"
+	PlayerRoleSpotter   PlayerRole = "spotter"
+	PlayerRoleSpectator PlayerRole = "spectator" // Anyone without a role in the stage
+)
+
+// CanSeeCard reports whether the role may see the target and taboo words
+func (r PlayerRole) CanSeeCard() bool {
+	return r == PlayerRoleClueGiver || r == PlayerRoleSpotter
+}
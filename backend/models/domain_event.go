@@ -0,0 +1,169 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DomainEventType identifies a state change recorded in a game's event log
+type DomainEventType string
+
+const (
+	DomainEventGameCreated       DomainEventType = "game_created"
+	DomainEventPlayerJoined      DomainEventType = "player_joined"
+	DomainEventRulesUpdated      DomainEventType = "rules_updated"
+	DomainEventGameStarted       DomainEventType = "game_started"
+	DomainEventGameEnded         DomainEventType = "game_ended"
+	DomainEventMatchStarted      DomainEventType = "match_started"
+	DomainEventTeamSwitched      DomainEventType = "team_switched"
+	DomainEventStageStarted      DomainEventType = "stage_started"
+	DomainEventStageEnded        DomainEventType = "stage_ended"
+	DomainEventTurnChanged       DomainEventType = "turn_changed"
+	DomainEventCardDrawn         DomainEventType = "card_drawn"
+	DomainEventGuessRecorded     DomainEventType = "guess_recorded"
+	DomainEventViolationReported DomainEventType = "violation_reported"
+	DomainEventViolationDisputed DomainEventType = "violation_disputed"
+	DomainEventViolationVoted    DomainEventType = "violation_voted"
+	DomainEventViolationResolved DomainEventType = "violation_resolved"
+	DomainEventScoreRecorded     DomainEventType = "score_recorded"
+	DomainEventTranscriptAdded   DomainEventType = "transcript_added"
+	DomainEventMatchEnded        DomainEventType = "match_ended"
+)
+
+// DomainEventSchemaVersion is the payload schema written for new events.
+// Older events keep the schema version they were written with.
+const DomainEventSchemaVersion = 1
+
+// DomainEvent is a single entry in a game's append-only event log
+type DomainEvent struct {
+	GameID        string          `json:"gameId"`
+	Version       int             `json:"version"` // Position in the game's log, starting at 1
+	Type          DomainEventType `json:"type"`
+	SchemaVersion int             `json:"schemaVersion"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Data          json.RawMessage `json:"data"`
+}
+
+type GameCreatedData struct {
+	CreatedAt time.Time `json:"createdAt"`
+	Teams     []Team    `json:"teams"`
+	Rules     GameRules `json:"rules"`
+}
+
+type PlayerJoinedData struct {
+	Player Player `json:"player"`
+}
+
+type RulesUpdatedData struct {
+	Rules GameRules `json:"rules"`
+}
+
+type GameStartedData struct {
+	Matches []Match `json:"matches"`
+}
+
+type MatchStartedData struct {
+	MatchID      string   `json:"matchId"`
+	TeamAPlayers []string `json:"teamAPlayers"`
+	TeamBPlayers []string `json:"teamBPlayers"`
+}
+
+type TeamSwitchedData struct {
+	MatchID  string `json:"matchId"`
+	PlayerID string `json:"playerId"`
+}
+
+type StageStartedData struct {
+	Stage MatchStage `json:"stage"`
+}
+
+type StageEndedData struct {
+	MatchID string `json:"matchId"`
+	StageID string `json:"stageId"`
+}
+
+type TurnChangedData struct {
+	MatchID   string `json:"matchId"`
+	TeamATurn bool   `json:"teamATurn"`
+}
+
+type CardDrawnData struct {
+	StageNumber int      `json:"stageNumber"`
+	Card        WordCard `json:"card"`
+}
+
+type GuessRecordedData struct {
+	MatchID string       `json:"matchId"`
+	Attempt GuessAttempt `json:"attempt"`
+}
+
+// ViolationRecordedData carries a violation call as it stood after it was
+// reported, disputed, voted on or resolved
+type ViolationRecordedData struct {
+	Violation Violation `json:"violation"`
+}
+
+type ScoreRecordedData struct {
+	Entry ScoreEntry `json:"entry"`
+}
+
+type TranscriptAddedData struct {
+	MatchID string          `json:"matchId"`
+	StageID string          `json:"stageId"`
+	Chunk   TranscriptChunk `json:"chunk"`
+}
+
+type MatchEndedData struct {
+	MatchID string `json:"matchId"`
+}
+
+// Redacted returns a copy of the event without what could give a card away:
+// the words of a drawn card, typed guesses, transcript flags and the
+// evidence of violation calls
+func (e DomainEvent) Redacted() DomainEvent {
+	var data interface{}
+	switch e.Type {
+	case DomainEventCardDrawn:
+		var drawn CardDrawnData
+		if err := json.Unmarshal(e.Data, &drawn); err != nil {
+			return e.withoutData()
+		}
+		drawn.Card = WordCard{ID: drawn.Card.ID}
+		data = drawn
+	case DomainEventGuessRecorded:
+		var recorded GuessRecordedData
+		if err := json.Unmarshal(e.Data, &recorded); err != nil {
+			return e.withoutData()
+		}
+		recorded.Attempt.Guess = ""
+		data = recorded
+	case DomainEventTranscriptAdded:
+		var added TranscriptAddedData
+		if err := json.Unmarshal(e.Data, &added); err != nil {
+			return e.withoutData()
+		}
+		added.Chunk.Flags = nil
+		data = added
+	case DomainEventViolationReported, DomainEventViolationDisputed, DomainEventViolationVoted, DomainEventViolationResolved:
+		var recorded ViolationRecordedData
+		if err := json.Unmarshal(e.Data, &recorded); err != nil {
+			return e.withoutData()
+		}
+		recorded.Violation = recorded.Violation.WithoutEvidence()
+		data = recorded
+	default:
+		return e
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return e.withoutData()
+	}
+	e.Data = encoded
+	return e
+}
+
+func (e DomainEvent) withoutData() DomainEvent {
+	e.Data = json.RawMessage("null")
+	return e
+}
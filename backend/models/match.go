@@ -25,6 +25,8 @@ type MatchDetails struct {
 	TeamBPlayers []string    `json:"teamBPlayers"`
 	CurrentWord  string      `json:"currentWord"`
 	CurrentStage *MatchStage `json:"currentStage"`
+
+	ViolationCounts map[ViolationType]int `json:"violationCounts"`
 }
 
 type MatchStage struct {
@@ -39,6 +41,9 @@ type MatchStage struct {
 	Status         string   `json:"status"`
 	TeamAScore     int      `json:"teamAScore"`
 	TeamBScore     int      `json:"teamBScore"`
+
+	ViolationCounts map[ViolationType]int `json:"violationCounts"`
+	Transcript      []TranscriptChunk     `json:"transcript,omitempty"` // Clue-givers' transcribed speech
 }
 
 type MatchStageDetails struct {
@@ -48,3 +53,74 @@ type MatchStageDetails struct {
 	Guessers       []string `json:"guessers"`
 	Spotters       []string `json:"spotters"`
 }
+
+// GameStats aggregates scores and violation counts across the matches of a game
+type GameStats struct {
+	GameID          string                `json:"gameId"`
+	TeamAScore      int                   `json:"teamAScore"`
+	TeamBScore      int                   `json:"teamBScore"`
+	ViolationCounts map[ViolationType]int `json:"violationCounts"`
+	Matches         []MatchStats          `json:"matches"`
+}
+
+// MatchStats summarises a single match for game statistics
+type MatchStats struct {
+	MatchID         string                `json:"matchId"`
+	TeamAScore      int                   `json:"teamAScore"`
+	TeamBScore      int                   `json:"teamBScore"`
+	ViolationCounts map[ViolationType]int `json:"violationCounts"`
+}
+
+// RoleOf returns the role a player has in the stage
+func (s *MatchStage) RoleOf(playerID string) PlayerRole {
+	for _, role := range []struct {
+		players []string
+		role    PlayerRole
+	}{
+		{s.ClueGivers, PlayerRoleClueGiver},
+		{s.Guessers, PlayerRoleGuesser},
+		{s.Spotters, PlayerRoleSpotter},
+	} {
+		for _, id := range role.players {
+			if id == playerID {
+				return role.role
+			}
+		}
+	}
+	return PlayerRoleSpectator
+}
+
+// CardHolders returns the players allowed to see the stage's card
+func (s *MatchStage) CardHolders() []string {
+	holders := make([]string, 0, len(s.ClueGivers)+len(s.Spotters))
+	holders = append(holders, s.ClueGivers...)
+	return append(holders, s.Spotters...)
+}
+
+// RoleOf returns the role a player has in the current stage of the match
+func (m *MatchDetails) RoleOf(playerID string) PlayerRole {
+	if m.CurrentStage == nil {
+		return PlayerRoleSpectator
+	}
+	return m.CurrentStage.RoleOf(playerID)
+}
+
+// RedactedFor returns a copy of the match with the current word, and the
+// transcript flags that name card words, hidden unless the player's role in
+// the current stage allows seeing the card
+func (m *MatchDetails) RedactedFor(playerID string) *MatchDetails {
+	redacted := *m
+	if !m.RoleOf(playerID).CanSeeCard() {
+		redacted.CurrentWord = ""
+		if m.CurrentStage != nil && len(m.CurrentStage.Transcript) > 0 {
+			stage := *m.CurrentStage
+			stage.Transcript = make([]TranscriptChunk, len(m.CurrentStage.Transcript))
+			for i, chunk := range m.CurrentStage.Transcript {
+				chunk.Flags = nil
+				stage.Transcript[i] = chunk
+			}
+			redacted.CurrentStage = &stage
+		}
+	}
+	return &redacted
+}
@@ -1,6 +1,30 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrVersionConflict is returned when a write is based on an older version
+// of a game than the current one
+var ErrVersionConflict = errors.New("game has changed since it was read")
+
+// VersionMatch lists the game versions a write may be based on, as given
+// by an If-Match header. A nil list accepts any version.
+type VersionMatch []int
+
+// Allows reports whether a game at the version may be written
+func (m VersionMatch) Allows(version int) bool {
+	if m == nil {
+		return true
+	}
+	for _, allowed := range m {
+		if allowed == version {
+			return true
+		}
+	}
+	return false
+}
 
 // Player represents a user in the game
 type Player struct {
@@ -27,6 +51,9 @@ type Game struct {
 	Status    GameStatus `json:"status"`
 	Teams     []Team     `json:"teams"`
 	Matches   []Match    `json:"matches"`
+	Rules     GameRules  `json:"rules"`
+	HostID    string     `json:"hostId"`  // First player to join
+	Version   int        `json:"version"` // Number of changes to the game, used as its ETag
 }
 
 // Match represents one of the three matches in a game
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// PresenceState tells whether a player currently has a connection to a game
+type PresenceState string
+
+const (
+	PresenceConnected    PresenceState = "connected"
+	PresenceReconnecting PresenceState = "reconnecting" // Lost every connection, within the grace period
+	PresenceGone         PresenceState = "gone"
+)
+
+// PresenceEvent names the change announced in a presence update
+type PresenceEvent string
+
+const (
+	PresenceJoined       PresenceEvent = "joined"
+	PresenceDisconnected PresenceEvent = "disconnected" // Now reconnecting
+	PresenceReconnected  PresenceEvent = "reconnected"
+	PresenceLeft         PresenceEvent = "left" // Grace period ran out
+	PresenceIdle         PresenceEvent = "idle"
+	PresenceActive       PresenceEvent = "active"
+)
+
+// PlayerPresence is a player's connection status in a game. A player with
+// several tabs open has one entry with several connections.
+type PlayerPresence struct {
+	GameID      string        `json:"gameId"`
+	PlayerID    string        `json:"playerId"`
+	State       PresenceState `json:"state"`
+	Connections int           `json:"connections"`
+	Idle        bool          `json:"idle"`
+	Since       time.Time     `json:"since"` // When the state last changed
+	LastSeen    time.Time     `json:"lastSeen"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// RecoverySnapshot holds what is needed to carry the active games over a
+// server restart
+type RecoverySnapshot struct {
+	TakenAt     time.Time       `json:"takenAt"`
+	Games       []*Game         `json:"games"`
+	LogVersions map[string]int  `json:"logVersions"` // Version of each game's last event when taken
+	Matches     []*MatchDetails `json:"matches"`
+	Scores      []ScoreEntry    `json:"scores"`
+	Stages      []StageDeadline `json:"stages"`
+	UsedCards   []string        `json:"usedCards"` // Cards drawn since the deck was last reshuffled
+}
+
+// StageDeadline is a stage timer that was running when the snapshot was taken
+type StageDeadline struct {
+	GameID  string    `json:"gameId"`
+	StageID string    `json:"stageId,omitempty"`
+	EndTime time.Time `json:"endTime"`
+	Card    *WordCard `json:"card,omitempty"`
+}
@@ -0,0 +1,114 @@
+package models
+
+import "errors"
+
+// ViolationRule defines how a violation category is scored
+type ViolationRule struct {
+	SpotterReward   int `json:"spotterReward"`   // Points awarded to the spotting team
+	OffenderPenalty int `json:"offenderPenalty"` // Points deducted from the clue-giving team
+}
+
+// GuessRules controls how typed guesses are matched against the card
+type GuessRules struct {
+	MaxTypos      int `json:"maxTypos"`      // Edits still accepted as correct, for answers of 4 or more letters
+	CloseDistance int `json:"closeDistance"` // Edits within which a wrong guess is hinted as close
+}
+
+// SoundsLikeSensitivity sets how eagerly clues are flagged for sounding
+// like or rhyming with a card word
+type SoundsLikeSensitivity string
+
+const (
+	SoundsLikeOff    SoundsLikeSensitivity = "off"
+	SoundsLikeLow    SoundsLikeSensitivity = "low"    // Same primary pronunciation only
+	SoundsLikeMedium SoundsLikeSensitivity = "medium" // Also alternate pronunciations and rhymes on the last two syllables
+	SoundsLikeHigh   SoundsLikeSensitivity = "high"   // Also rhymes on the last syllable alone
+)
+
+// IsValid reports whether the sensitivity is one of the known levels
+func (s SoundsLikeSensitivity) IsValid() bool {
+	switch s {
+	case SoundsLikeOff, SoundsLikeLow, SoundsLikeMedium, SoundsLikeHigh:
+		return true
+	}
+	return false
+}
+
+// ClueRules controls the automatic checks on clue text
+type ClueRules struct {
+	SoundsLike SoundsLikeSensitivity `json:"soundsLike"`
+}
+
+// GameRules holds the configurable scoring rules of a game
+type GameRules struct {
+	ViolationRules map[ViolationType]ViolationRule `json:"violationRules"`
+	Guessing       *GuessRules                     `json:"guessing,omitempty"`
+	Clues          *ClueRules                      `json:"clues,omitempty"`
+}
+
+// DefaultGameRules returns the scoring rules described in the game README
+func DefaultGameRules() GameRules {
+	rules := GameRules{
+		ViolationRules: make(map[ViolationType]ViolationRule, len(ViolationTypes)),
+		Guessing: &GuessRules{
+			MaxTypos:      1,
+			CloseDistance: 3,
+		},
+		Clues: &ClueRules{
+			SoundsLike: SoundsLikeMedium,
+		},
+	}
+	for _, violationType := range ViolationTypes {
+		rules.ViolationRules[violationType] = ViolationRule{
+			SpotterReward:   PointsViolationCatch,
+			OffenderPenalty: 0,
+		}
+	}
+	return rules
+}
+
+// ViolationRule returns the scoring rule for a category, falling back to the
+// default rule when the game does not configure one
+func (r GameRules) ViolationRule(violationType ViolationType) ViolationRule {
+	if rule, exists := r.ViolationRules[violationType]; exists {
+		return rule
+	}
+	return DefaultGameRules().ViolationRules[ViolationTypeOther]
+}
+
+// GuessingRules returns how guesses are matched, falling back to the
+// default for games created before it was configurable
+func (r GameRules) GuessingRules() GuessRules {
+	if r.Guessing != nil {
+		return *r.Guessing
+	}
+	return *DefaultGameRules().Guessing
+}
+
+// ClueRules returns how clues are checked, falling back to the default for
+// games created before it was configurable
+func (r GameRules) ClueRules() ClueRules {
+	if r.Clues != nil {
+		return *r.Clues
+	}
+	return *DefaultGameRules().Clues
+}
+
+// Validate checks that every configured category exists and scores sensibly
+func (r GameRules) Validate() error {
+	for violationType, rule := range r.ViolationRules {
+		if !violationType.IsValid() {
+			return errors.New("unknown violation type: " + string(violationType))
+		}
+		if rule.SpotterReward < 0 || rule.OffenderPenalty < 0 {
+			return errors.New("violation rewards and penalties must not be negative")
+		}
+	}
+	if r.Guessing != nil && (r.Guessing.MaxTypos < 0 || r.Guessing.CloseDistance < 0) {
+		return errors.New("guess distances must not be negative")
+	}
+	if r.Clues != nil && !r.Clues.SoundsLike.IsValid() {
+		return errors.New("unknown sounds-like sensitivity: " + string(r.Clues.SoundsLike))
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// ScoreReason describes why points were awarded or deducted
+type ScoreReason string
+
+const (
+	ScoreReasonCorrectGuess     ScoreReason = "correct_guess"
+	ScoreReasonViolationCatch   ScoreReason = "violation_catch"
+	ScoreReasonViolationPenalty ScoreReason = "violation_penalty"
+	ScoreReasonTeamSizeBonus    ScoreReason = "team_size_bonus"
+	ScoreReasonHostAward        ScoreReason = "host_award"
+)
+
+// ScoreEntry is a single immutable record in a game's scoring ledger
+type ScoreEntry struct {
+	Seq           int           `json:"seq"`
+	GameID        string        `json:"gameId"`
+	MatchID       string        `json:"matchId"`
+	StageID       string        `json:"stageId,omitempty"`
+	TeamID        string        `json:"teamId"` // "teamA" or "teamB"
+	Points        int           `json:"points"`
+	Reason        ScoreReason   `json:"reason"`
+	ViolationType ViolationType `json:"violationType,omitempty"`
+	CardID        string        `json:"cardId,omitempty"`
+	Timestamp     time.Time     `json:"timestamp"`
+}
+
+// ScoreTotals holds the scores of both teams derived from ledger entries
+type ScoreTotals struct {
+	TeamAScore int `json:"teamAScore"`
+	TeamBScore int `json:"teamBScore"`
+}
+
+// Add applies an entry's points to the matching team
+func (t *ScoreTotals) Add(entry ScoreEntry) {
+	if entry.TeamID == "teamA" {
+		t.TeamAScore += entry.Points
+	} else {
+		t.TeamBScore += entry.Points
+	}
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// GameSnapshot is the state of a game as seen by one player, sent to
+// clients that reconnect after missing too many messages to replay, or
+// after the server restarted
+type GameSnapshot struct {
+	Game       *Game         `json:"game"`
+	Match      *MatchDetails `json:"match,omitempty"` // Redacted for the player's role
+	Scores     []ScoreEntry  `json:"scores"`
+	WordCard   *WordCard     `json:"wordCard,omitempty"`   // Only for clue-givers and spotters
+	Remaining  int           `json:"remaining"`            // Seconds left in the current stage
+	RestoredAt *time.Time    `json:"restoredAt,omitempty"` // When the game was restored after a server restart
+}
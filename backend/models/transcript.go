@@ -0,0 +1,33 @@
+package models
+
+// TranscriptChunk is a piece of a clue-giver's speech as transcribed by
+// their own client
+type TranscriptChunk struct {
+	PlayerID    string         `json:"playerId"`
+	Text        string         `json:"text"`
+	TimestampMS int64          `json:"timestampMs"` // When the speech started
+	DurationMS  int64          `json:"durationMs,omitempty"`
+	Flags       []ClueEvidence `json:"flags,omitempty"` // What the clue checks found in it
+}
+
+// StageSummary is what happened in a stage, sent when it ends
+type StageSummary struct {
+	StageID      string            `json:"stageId"`
+	StageNumber  int               `json:"stageNumber"`
+	ActiveTeamID string            `json:"activeTeamId"`
+	TeamAScore   int               `json:"teamAScore"`
+	TeamBScore   int               `json:"teamBScore"`
+	Transcript   []TranscriptChunk `json:"transcript"`
+}
+
+// Summary returns the stage's summary
+func (s *MatchStage) Summary() StageSummary {
+	return StageSummary{
+		StageID:      s.ID,
+		StageNumber:  s.Number,
+		ActiveTeamID: s.ActiveTeamID,
+		TeamAScore:   s.TeamAScore,
+		TeamBScore:   s.TeamBScore,
+		Transcript:   append([]TranscriptChunk{}, s.Transcript...),
+	}
+}
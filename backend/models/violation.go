@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// ViolationStatus represents the possible states of a violation call
+type ViolationStatus string
+
+const (
+	ViolationStatusPending  ViolationStatus = "pending"  // Open for dispute
+	ViolationStatusDisputed ViolationStatus = "disputed" // Awaiting vote or referee
+	ViolationStatusUpheld   ViolationStatus = "upheld"
+	ViolationStatusRejected ViolationStatus = "rejected"
+)
+
+// Violation represents a violation called by a spotter against the clue-giving team
+type Violation struct {
+	ID              string          `json:"id"`
+	GameID          string          `json:"gameId"`
+	MatchID         string          `json:"matchId"`
+	StageID         string          `json:"stageId"`
+	CardID          string          `json:"cardId"`
+	Type            ViolationType   `json:"type"`
+	ReporterID      string          `json:"reporterId"` // Empty when raised automatically
+	Automatic       bool            `json:"automatic,omitempty"`
+	Evidence        *ClueEvidence   `json:"evidence,omitempty"` // Why an automatic call was raised; names card words
+	CardHolders     []string        `json:"-"`                  // Players who could see the card, the only ones shown the evidence
+	OffendingTeamID string          `json:"offendingTeamId"`
+	Status          ViolationStatus `json:"status"`
+	DisputedBy      string          `json:"disputedBy,omitempty"`
+	Votes           map[string]bool `json:"votes"` // Player ID -> true to uphold
+	EligibleVoters  []string        `json:"eligibleVoters"`
+	ResolvedBy      string          `json:"resolvedBy,omitempty"` // "vote", "referee" or "timeout"
+	ReportedAt      time.Time       `json:"reportedAt"`
+	DisputeDeadline time.Time       `json:"disputeDeadline"`
+	ResolvedAt      time.Time       `json:"resolvedAt,omitempty"`
+}
+
+// ClueMatch is how a word of a clue relates to a word on the card
+type ClueMatch string
+
+const (
+	ClueMatchExact      ClueMatch = "exact"
+	ClueMatchStem       ClueMatch = "stem"        // Another form of the card word
+	ClueMatchCompound   ClueMatch = "compound"    // Card word inside a clue word, or split across clue words
+	ClueMatchPart       ClueMatch = "part"        // Clue word is part of a card word
+	ClueMatchNear       ClueMatch = "near"        // Spelled almost like a card word
+	ClueMatchSoundsLike ClueMatch = "sounds_like" // Same Double Metaphone code as a card word
+	ClueMatchRhyme      ClueMatch = "rhyme"       // Same ending from the last vowel sounds
+)
+
+// ClueEvidence records which part of a clue matched which card word
+type ClueEvidence struct {
+	Clue     string    `json:"clue"`
+	ClueWord string    `json:"clueWord"`
+	CardWord string    `json:"cardWord"`
+	Match    ClueMatch `json:"match"`
+	Reason   string    `json:"reason"` // Human-readable explanation of the match
+}
+
+// WithoutEvidence returns a copy of the violation without the evidence,
+// for players who must not learn the card from it
+func (v Violation) WithoutEvidence() Violation {
+	v.Evidence = nil
+	return v
+}
+
+// IsResolved reports whether the violation has reached a final decision
+func (v *Violation) IsResolved() bool {
+	return v.Status == ViolationStatusUpheld || v.Status == ViolationStatusRejected
+}
@@ -0,0 +1,77 @@
+package models
+
+// VoiceRoomGame is the voice room of the whole game. Each team also has a
+// room, named by its team ID ("teamA" or "teamB").
+const VoiceRoomGame = "game"
+
+// VoiceRoom is a voice room of a match as one player sees it
+type VoiceRoom struct {
+	ID      string   `json:"id"`
+	Hears   []string `json:"hears"`   // Players whose audio the player receives
+	HeardBy []string `json:"heardBy"` // Players who receive the player's audio
+}
+
+// TeamOf returns the team ID of a player of the match, or "" for anyone else
+func (m *MatchDetails) TeamOf(playerID string) string {
+	for _, team := range []struct {
+		id      string
+		players []string
+	}{
+		{"teamA", m.TeamAPlayers},
+		{"teamB", m.TeamBPlayers},
+	} {
+		for _, id := range team.players {
+			if id == playerID {
+				return team.id
+			}
+		}
+	}
+	return ""
+}
+
+// CanHear reports whether listener receives speaker's audio in a voice room
+// of the match. Between stages each room is open to all its members. During
+// a stage everyone in the game room hears the clue-giving team, so the
+// spotters hear every clue, while the spotting team is heard only by
+// itself. The clue-giving team's own room is closed so no clue can bypass
+// the spotters.
+func (m *MatchDetails) CanHear(roomID, listener, speaker string) bool {
+	listenerTeam, speakerTeam := m.TeamOf(listener), m.TeamOf(speaker)
+	if listener == speaker || listenerTeam == "" || speakerTeam == "" {
+		return false
+	}
+
+	switch roomID {
+	case VoiceRoomGame:
+		return m.CurrentStage == nil || speakerTeam == m.CurrentStage.ActiveTeamID || listenerTeam == speakerTeam
+	case listenerTeam:
+		return speakerTeam == roomID && (m.CurrentStage == nil || roomID != m.CurrentStage.ActiveTeamID)
+	}
+	return false
+}
+
+// VoiceRooms returns the rooms a player of the match belongs to, with who
+// they hear and who hears them in each
+func (m *MatchDetails) VoiceRooms(playerID string) []VoiceRoom {
+	team := m.TeamOf(playerID)
+	if team == "" {
+		return nil
+	}
+
+	rooms := make([]VoiceRoom, 0, 2)
+	for _, roomID := range []string{VoiceRoomGame, team} {
+		room := VoiceRoom{ID: roomID, Hears: []string{}, HeardBy: []string{}}
+		for _, players := range [][]string{m.TeamAPlayers, m.TeamBPlayers} {
+			for _, other := range players {
+				if m.CanHear(roomID, playerID, other) {
+					room.Hears = append(room.Hears, other)
+				}
+				if m.CanHear(roomID, other, playerID) {
+					room.HeardBy = append(room.HeardBy, other)
+				}
+			}
+		}
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
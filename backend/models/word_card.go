@@ -1,18 +1,32 @@
 package models
 
 type WordCard struct {
-	ID         string   `json:"id"`
-	TargetWord string   `json:"targetWord"`
-	TabooWords []string `json:"tabooWords"`
-	Difficulty int      `json:"difficulty"` // 1-3
-	Category   string   `json:"category"`
+	ID               string   `json:"id"`
+	TargetWord       string   `json:"targetWord"`
+	AlternateAnswers []string `json:"alternateAnswers,omitempty"` // Also accepted as correct
+	TabooWords       []string `json:"tabooWords"`
+	Difficulty       int      `json:"difficulty"` // 1-3
+	Category         string   `json:"category"`
 }
 
+// GuessVerdict is how a typed guess compares to the card
+type GuessVerdict string
+
+const (
+	GuessCorrect GuessVerdict = "correct"
+	GuessClose   GuessVerdict = "close" // Wrong, but within the close distance
+	GuessWrong   GuessVerdict = "wrong"
+)
+
 type GuessAttempt struct {
-	CardID      string `json:"cardId"`
-	Correct     bool   `json:"correct"`
-	Violation   bool   `json:"violation"`
-	TeamID      string `json:"teamId"`
-	StageID     string `json:"stageId"`
-	TimestampMS int64  `json:"timestampMs"`
+	CardID        string        `json:"cardId"`
+	PlayerID      string        `json:"playerId,omitempty"`
+	Guess         string        `json:"guess,omitempty"` // Typed guess the server judged
+	Verdict       GuessVerdict  `json:"verdict,omitempty"`
+	Correct       bool          `json:"correct"`
+	Violation     bool          `json:"violation"`
+	ViolationType ViolationType `json:"violationType,omitempty"`
+	TeamID        string        `json:"teamId"`
+	StageID       string        `json:"stageId"`
+	TimestampMS   int64         `json:"timestampMs"`
 }
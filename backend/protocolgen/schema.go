@@ -0,0 +1,117 @@
+package protocolgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"taboo-game/websocket"
+)
+
+type schema = map[string]interface{}
+
+// JSONSchema returns the protocol as a JSON Schema (draft 2020-12) that
+// matches any message of the current version
+func JSONSchema() ([]byte, error) {
+	p, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	defs := schema{}
+	for _, name := range p.typeNames() {
+		defs[name] = p.schemaShape(p.types[name])
+	}
+
+	messageTypes := make([]string, 0, len(p.messages))
+	oneOf := make([]schema, 0, len(p.messages))
+	for _, m := range p.messages {
+		messageTypes = append(messageTypes, string(m.msgType))
+		defs[m.name] = p.envelopeSchema(m)
+		oneOf = append(oneOf, ref(m.name))
+	}
+	defs["MessageType"] = schema{"type": "string", "enum": messageTypes}
+
+	data, err := json.MarshalIndent(schema{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"title":       "Taboo WebSocket protocol",
+		"description": fmt.Sprintf("Version %d. Generated by protocolgen from backend/websocket; do not edit.", websocket.ProtocolVersion),
+		"oneOf":       oneOf,
+		"$defs":       defs,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// envelopeSchema is the Message envelope with the message's type and payload
+func (p *protocol) envelopeSchema(m message) schema {
+	envelope := p.schemaShape(messageType)
+	properties := envelope["properties"].(schema)
+	properties["v"] = schema{"const": websocket.ProtocolVersion}
+	properties["type"] = schema{"const": string(m.msgType)}
+	properties["payload"] = ref(m.payload.Name())
+	return envelope
+}
+
+func (p *protocol) schemaField(f field) schema {
+	fieldSchema := p.schemaType(f.typ)
+	if f.nullable {
+		return schema{"anyOf": []schema{fieldSchema, {"type": "null"}}}
+	}
+	return fieldSchema
+}
+
+// schemaType refers to t, through $defs when it has a definition
+func (p *protocol) schemaType(t reflect.Type) schema {
+	switch {
+	case t == timeType:
+		return schema{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return schema{}
+	case t == messageTypeType:
+		return ref("MessageType")
+	case p.types[t.Name()] == t:
+		return ref(t.Name())
+	}
+	return p.schemaShape(t)
+}
+
+// schemaShape spells out t's structure
+func (p *protocol) schemaShape(t reflect.Type) schema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return schema{"type": "boolean"}
+	case reflect.String:
+		return schema{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return schema{"type": "number"}
+	case reflect.Pointer:
+		return p.schemaType(t.Elem())
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return schema{"type": "array", "items": p.schemaType(t.Elem())}
+	case reflect.Map:
+		return schema{"type": "object", "additionalProperties": p.schemaType(t.Elem())}
+	case reflect.Struct:
+		properties := schema{}
+		required := []string{}
+		for _, f := range fields(t) {
+			properties[f.name] = p.schemaField(f)
+			if !f.optional {
+				required = append(required, f.name)
+			}
+		}
+		return schema{"type": "object", "properties": properties, "required": required}
+	}
+	return schema{}
+}
+
+func ref(name string) schema {
+	return schema{"$ref": "#/$defs/" + name}
+}
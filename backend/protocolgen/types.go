@@ -0,0 +1,150 @@
+// Package protocolgen generates the JSON Schema and TypeScript definitions
+// of the WebSocket protocol from the payload structs in websocket.Messages
+package protocolgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"taboo-game/websocket"
+	"time"
+)
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	rawMessageType  = reflect.TypeOf(json.RawMessage{})
+	messageType     = reflect.TypeOf(websocket.Message{})
+	messageTypeType = reflect.TypeOf(websocket.MessageType(""))
+)
+
+// field is a struct field as it appears in JSON
+type field struct {
+	name     string
+	typ      reflect.Type
+	optional bool // Left out when empty
+	nullable bool // A pointer that is sent as null when unset
+}
+
+// message is one message type with the name its definitions get
+type message struct {
+	msgType websocket.MessageType
+	name    string // e.g. GiveClueMessage for GIVE_CLUE
+	payload reflect.Type
+	spec    websocket.MessageSpec
+}
+
+// protocol holds the messages and every named type their payloads use
+type protocol struct {
+	messages []message
+	types    map[string]reflect.Type
+}
+
+func load() (*protocol, error) {
+	p := &protocol{types: make(map[string]reflect.Type)}
+	for msgType, spec := range websocket.Messages {
+		payload := reflect.TypeOf(spec.Payload)
+		if err := p.collect(payload); err != nil {
+			return nil, err
+		}
+		p.messages = append(p.messages, message{
+			msgType: msgType,
+			name:    messageName(msgType),
+			payload: payload,
+			spec:    spec,
+		})
+	}
+	sort.Slice(p.messages, func(i, j int) bool {
+		return p.messages[i].msgType < p.messages[j].msgType
+	})
+	return p, nil
+}
+
+// collect registers t and the named types it refers to
+func (p *protocol) collect(t reflect.Type) error {
+	switch {
+	case t == timeType || t == rawMessageType || t == messageTypeType:
+		return nil
+	case t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map:
+		return p.collect(t.Elem())
+	case t.Name() != "" && t.PkgPath() != "":
+		if existing, seen := p.types[t.Name()]; seen {
+			if existing != t {
+				return fmt.Errorf("%s and %s have the same name", existing, t)
+			}
+			return nil
+		}
+		p.types[t.Name()] = t
+	}
+
+	if t.Kind() == reflect.Struct {
+		for _, f := range fields(t) {
+			if err := p.collect(f.typ); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// typeNames returns the named types in a stable order
+func (p *protocol) typeNames() []string {
+	names := make([]string, 0, len(p.types))
+	for name := range p.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fields lists a struct's fields the way encoding/json sees them, with
+// embedded structs flattened
+func fields(t reflect.Type) []field {
+	var result []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			result = append(result, fields(f.Type)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		omitempty := strings.Contains(options, "omitempty")
+		typ := f.Type
+		pointer := typ.Kind() == reflect.Pointer
+		if pointer {
+			typ = typ.Elem()
+		}
+		result = append(result, field{
+			name: name,
+			typ:  typ,
+			// omitempty never leaves out a struct value
+			optional: omitempty && (pointer || f.Type.Kind() != reflect.Struct),
+			nullable: pointer && !omitempty,
+		})
+	}
+	return result
+}
+
+// messageName turns GIVE_CLUE into GiveClueMessage
+func messageName(msgType websocket.MessageType) string {
+	var name strings.Builder
+	for _, word := range strings.Split(strings.ToLower(string(msgType)), "_") {
+		if word != "" {
+			name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	name.WriteString("Message")
+	return name.String()
+}
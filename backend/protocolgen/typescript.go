@@ -0,0 +1,157 @@
+package protocolgen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"taboo-game/websocket"
+)
+
+// TypeScript returns the protocol's TypeScript definitions
+func TypeScript() (string, error) {
+	p, err := load()
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	out.WriteString("// Code generated by protocolgen from backend/websocket. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "export const PROTOCOL_VERSION = %d;\n", websocket.ProtocolVersion)
+	fmt.Fprintf(&out, "export const PROTOCOL_SUBPROTOCOL = '%s';\n", websocket.Subprotocol(websocket.ProtocolVersion))
+	fmt.Fprintf(&out, "export const PROTOCOL_ENCODINGS = ['%s'] as const;\n\n", strings.Join(websocket.Encodings, "', '"))
+
+	out.WriteString("export type MessageType =\n")
+	for i, m := range p.messages {
+		fmt.Fprintf(&out, "  | '%s'%s\n", m.msgType, terminator(i, len(p.messages)))
+	}
+	out.WriteString("\n")
+
+	// The envelope, generic over the type and payload
+	out.WriteString("export interface Message<T extends MessageType = MessageType, P = unknown> {\n")
+	for _, f := range fields(messageType) {
+		tsType := p.tsField(f)
+		switch f.name {
+		case "v":
+			tsType = "typeof PROTOCOL_VERSION"
+		case "type":
+			tsType = "T"
+		case "payload":
+			tsType = "P"
+		}
+		fmt.Fprintf(&out, "  %s%s: %s;\n", f.name, optionalMark(f), tsType)
+	}
+	out.WriteString("}\n\n")
+
+	for _, name := range p.typeNames() {
+		t := p.types[name]
+		if t.Kind() != reflect.Struct || t.NumField() == 0 {
+			fmt.Fprintf(&out, "export type %s = %s;\n\n", name, p.tsShape(t))
+			continue
+		}
+		fmt.Fprintf(&out, "export interface %s {\n", name)
+		for _, f := range fields(t) {
+			fmt.Fprintf(&out, "  %s%s: %s;\n", f.name, optionalMark(f), p.tsField(f))
+		}
+		out.WriteString("}\n\n")
+	}
+
+	for _, m := range p.messages {
+		fmt.Fprintf(&out, "export type %s = Message<'%s', %s>;\n", m.name, m.msgType, m.payload.Name())
+	}
+	out.WriteString("\n")
+
+	out.WriteString("export interface PayloadByType {\n")
+	for _, m := range p.messages {
+		fmt.Fprintf(&out, "  %s: %s;\n", m.msgType, m.payload.Name())
+	}
+	out.WriteString("}\n\n")
+
+	writeUnion(&out, "ClientMessage", p.messages, func(m message) bool { return m.spec.FromClient })
+	writeUnion(&out, "ServerMessage", p.messages, func(m message) bool { return m.spec.FromServer })
+
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+func writeUnion(out *strings.Builder, name string, messages []message, include func(message) bool) {
+	var members []string
+	for _, m := range messages {
+		if include(m) {
+			members = append(members, m.name)
+		}
+	}
+	fmt.Fprintf(out, "export type %s =\n", name)
+	for i, member := range members {
+		fmt.Fprintf(out, "  | %s%s\n", member, terminator(i, len(members)))
+	}
+	out.WriteString("\n")
+}
+
+func (p *protocol) tsField(f field) string {
+	tsType := p.tsType(f.typ)
+	if f.nullable {
+		return tsType + " | null"
+	}
+	return tsType
+}
+
+// tsType refers to t in TypeScript, by name when it has a definition
+func (p *protocol) tsType(t reflect.Type) string {
+	switch {
+	case t == timeType:
+		return "string"
+	case t == rawMessageType:
+		return "unknown"
+	case t == messageTypeType:
+		return "MessageType"
+	case p.types[t.Name()] == t:
+		return t.Name()
+	}
+	return p.tsShape(t)
+}
+
+// tsShape spells out t's structure
+func (p *protocol) tsShape(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Pointer:
+		return p.tsType(t.Elem())
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string" // Base64
+		}
+		return p.tsType(t.Elem()) + "[]"
+	case reflect.Map:
+		return fmt.Sprintf("Record<string, %s>", p.tsType(t.Elem()))
+	case reflect.Struct:
+		var inline []string
+		for _, f := range fields(t) {
+			inline = append(inline, fmt.Sprintf("%s%s: %s", f.name, optionalMark(f), p.tsField(f)))
+		}
+		if len(inline) == 0 {
+			return "Record<string, never>"
+		}
+		return "{ " + strings.Join(inline, "; ") + " }"
+	}
+	return "unknown"
+}
+
+func optionalMark(f field) string {
+	if f.optional {
+		return "?"
+	}
+	return ""
+}
+
+func terminator(i, n int) string {
+	if i == n-1 {
+		return ";"
+	}
+	return ""
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"taboo-game/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventLogRoutes struct {
+	eventLogHandler *handlers.EventLogHandler
+	requireSession  gin.HandlerFunc
+}
+
+func NewEventLogRoutes(eventLogHandler *handlers.EventLogHandler, requireSession gin.HandlerFunc) *EventLogRoutes {
+	return &EventLogRoutes{
+		eventLogHandler: eventLogHandler,
+		requireSession:  requireSession,
+	}
+}
+
+func (r *EventLogRoutes) RegisterRoutes(router *gin.Engine) {
+	api := router.Group("/api/v1/games/:gameId", r.requireSession)
+	{
+		api.GET("/events", r.eventLogHandler.GetEvents)
+		api.GET("/replay", r.eventLogHandler.ReplayGame)
+	}
+}
@@ -7,12 +7,14 @@ import (
 )
 
 type GameRoutes struct {
-	gameHandler *handlers.GameHandler
+	gameHandler    *handlers.GameHandler
+	requireSession gin.HandlerFunc
 }
 
-func NewGameRoutes(gameHandler *handlers.GameHandler) *GameRoutes {
+func NewGameRoutes(gameHandler *handlers.GameHandler, requireSession gin.HandlerFunc) *GameRoutes {
 	return &GameRoutes{
-		gameHandler: gameHandler,
+		gameHandler:    gameHandler,
+		requireSession: requireSession,
 	}
 }
 
@@ -22,7 +24,8 @@ func (r *GameRoutes) RegisterRoutes(router *gin.Engine) {
 		api.POST("/", r.gameHandler.CreateGame)
 		api.POST("/:gameId/join", r.gameHandler.JoinGame)
 		api.GET("/:gameId", r.gameHandler.GetGame)
-		api.PUT("/:gameId/start", r.gameHandler.StartGame)
-		api.PUT("/:gameId/end", r.gameHandler.EndGame)
+		api.PUT("/:gameId/start", r.requireSession, r.gameHandler.StartGame)
+		api.PUT("/:gameId/end", r.requireSession, r.gameHandler.EndGame)
+		api.PUT("/:gameId/rules", r.requireSession, r.gameHandler.UpdateRules)
 	}
 }
@@ -7,25 +7,32 @@ import (
 )
 
 type MatchRoutes struct {
-	matchHandler *handlers.MatchHandler
+	matchHandler   *handlers.MatchHandler
+	requireSession gin.HandlerFunc
 }
 
-func NewMatchRoutes(matchHandler *handlers.MatchHandler) *MatchRoutes {
+func NewMatchRoutes(matchHandler *handlers.MatchHandler, requireSession gin.HandlerFunc) *MatchRoutes {
 	return &MatchRoutes{
-		matchHandler: matchHandler,
+		matchHandler:   matchHandler,
+		requireSession: requireSession,
 	}
 }
 
 func (r *MatchRoutes) RegisterRoutes(router *gin.Engine) {
-	api := router.Group("/api/v1")
+	api := router.Group("/api/v1/games/:gameId")
 	{
-		matches := api.Group("/matches")
+		matches := api.Group("/matches/:matchId", r.requireSession)
 		{
-			matches.GET("/:matchId", r.matchHandler.GetMatch)
-			matches.POST("/:matchId/start", r.matchHandler.StartMatch)
-			matches.POST("/:matchId/guess", r.matchHandler.ProcessGuessAttempt)
-			matches.PUT("/:matchId/end", r.matchHandler.EndMatch)
-			matches.POST("/:matchId/teams/switch/:playerId", r.matchHandler.SwitchTeam)
+			matches.GET("", r.matchHandler.GetMatch)
+			matches.POST("/start", r.matchHandler.StartMatch)
+			matches.POST("/stages", r.matchHandler.CreateStage)
+			matches.POST("/guess", r.matchHandler.ProcessGuessAttempt)
+			matches.POST("/score", r.matchHandler.ScorePoint)
+			matches.PUT("/end", r.matchHandler.EndMatch)
+			matches.POST("/teams/switch/:playerId", r.matchHandler.SwitchTeam)
 		}
+
+		api.GET("/stats", r.matchHandler.GetGameStats)
+		api.GET("/scores", r.matchHandler.GetScoreLedger)
 	}
 }
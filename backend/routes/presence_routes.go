@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"taboo-game/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PresenceRoutes struct {
+	presenceHandler *handlers.PresenceHandler
+	requireSession  gin.HandlerFunc
+}
+
+func NewPresenceRoutes(presenceHandler *handlers.PresenceHandler, requireSession gin.HandlerFunc) *PresenceRoutes {
+	return &PresenceRoutes{
+		presenceHandler: presenceHandler,
+		requireSession:  requireSession,
+	}
+}
+
+func (r *PresenceRoutes) RegisterRoutes(router *gin.Engine) {
+	router.GET("/api/v1/games/:gameId/presence", r.presenceHandler.GetPresence)
+	router.GET("/api/v1/games/:gameId/spectators", r.requireSession, r.presenceHandler.GetSpectators)
+}
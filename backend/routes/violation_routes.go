@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"taboo-game/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ViolationRoutes struct {
+	violationHandler *handlers.ViolationHandler
+	requireSession   gin.HandlerFunc
+}
+
+func NewViolationRoutes(violationHandler *handlers.ViolationHandler, requireSession gin.HandlerFunc) *ViolationRoutes {
+	return &ViolationRoutes{
+		violationHandler: violationHandler,
+		requireSession:   requireSession,
+	}
+}
+
+func (r *ViolationRoutes) RegisterRoutes(router *gin.Engine) {
+	api := router.Group("/api/v1/games/:gameId", r.requireSession)
+	{
+		api.PUT("/referee", r.violationHandler.SetReferee)
+
+		violations := api.Group("/violations")
+		{
+			violations.GET("", r.violationHandler.GetViolations)
+			violations.POST("", r.violationHandler.ReportViolation)
+			violations.POST("/:violationId/dispute", r.violationHandler.DisputeViolation)
+			violations.POST("/:violationId/votes", r.violationHandler.VoteOnViolation)
+			violations.POST("/:violationId/resolve", r.violationHandler.ResolveViolation)
+		}
+	}
+}
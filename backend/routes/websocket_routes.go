@@ -11,4 +11,20 @@ func SetupWebSocketRoutes(router *gin.Engine, wsManager *websocket.Manager) {
 		playerID := c.Param("playerId")
 		wsManager.HandleConnection(c.Writer, c.Request, gameID, playerID)
 	})
+
+	// Read-only connection for watchers, optionally delayed by ?delay=N seconds
+	router.GET("/spectate/:gameId", func(c *gin.Context) {
+		wsManager.HandleSpectator(c.Writer, c.Request, c.Param("gameId"))
+	})
+
+	// Server-Sent Events fallback for networks that block WebSocket upgrades
+	router.GET("/sse/:gameId", func(c *gin.Context) { // Spectator stream
+		wsManager.HandleEventStream(c.Writer, c.Request, c.Param("gameId"), "")
+	})
+	router.GET("/sse/:gameId/:playerId", func(c *gin.Context) {
+		wsManager.HandleEventStream(c.Writer, c.Request, c.Param("gameId"), c.Param("playerId"))
+	})
+	router.POST("/api/v1/games/:gameId/players/:playerId/actions", func(c *gin.Context) {
+		wsManager.HandleAction(c.Writer, c.Request, c.Param("gameId"), c.Param("playerId"))
+	})
 }
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"taboo-game/models"
+	"taboo-game/websocket"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Messages kept per game for players who ask for the history
+	defaultChatHistorySize = 100
+
+	// Longest chat message accepted, in characters
+	maxChatLength = 500
+
+	// Each player may send this many messages per window
+	defaultChatRateCount  = 5
+	defaultChatRateWindow = 10 * time.Second
+)
+
+// SetChatRateLimit changes how many chat messages a player may send per window
+func (s *GameEventsService) SetChatRateLimit(count int, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chatRateCount = count
+	s.chatRateWindow = window
+}
+
+// SendChat posts a player's message to a chat channel of the game. Team
+// channels reach only that team. While a stage is in play clue-givers
+// cannot chat, and messages on the game channel from players who can see
+// the card must not mention it.
+func (s *GameEventsService) SendChat(gameID, playerID, channel, text string) (*models.ChatMessage, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, errors.New("chat message is empty")
+	}
+	if utf8.RuneCountInString(text) > maxChatLength {
+		return nil, errors.New("chat message is too long")
+	}
+
+	recipients, err := s.chatRecipients(gameID, playerID, channel)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkChatDuringStage(gameID, playerID, channel, text); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	message := &models.ChatMessage{
+		ID:       uuid.New().String(),
+		GameID:   gameID,
+		Channel:  channel,
+		PlayerID: playerID,
+		Text:     text,
+		SentAt:   now,
+	}
+
+	s.mu.Lock()
+	if !s.allowChatLocked(gameID, playerID, now) {
+		s.mu.Unlock()
+		return nil, errors.New("sending chat messages too fast")
+	}
+	history := append(s.chatHistory[gameID], *message)
+	if len(history) > defaultChatHistorySize {
+		history = append([]models.ChatMessage(nil), history[len(history)-defaultChatHistorySize:]...)
+	}
+	s.chatHistory[gameID] = history
+	s.mu.Unlock()
+
+	msg := websocket.NewMessage(websocket.ChatMessage, gameID, playerID, websocket.ChatMessagePayload{Message: message})
+	if recipients == nil {
+		s.wsManager.SendToGame(gameID, msg.Encode())
+	} else {
+		s.wsManager.SendToPlayers(gameID, recipients, msg.Encode())
+	}
+	return message, nil
+}
+
+// ChatHistory returns the recent messages of the channels a player can read:
+// the game channel, and their team's channel
+func (s *GameEventsService) ChatHistory(gameID, playerID string) []models.ChatMessage {
+	team, _ := s.chatTeam(gameID, playerID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	history := make([]models.ChatMessage, 0, len(s.chatHistory[gameID]))
+	for _, message := range s.chatHistory[gameID] {
+		if message.Channel == models.ChatChannelGame || (team != "" && message.Channel == team) {
+			history = append(history, message)
+		}
+	}
+	return history
+}
+
+// SendChatHistory sends a player the recent messages they can read
+func (s *GameEventsService) SendChatHistory(gameID, playerID string) error {
+	msg := websocket.NewMessage(websocket.ChatHistory, gameID, playerID, websocket.ChatHistoryPayload{
+		Messages: s.ChatHistory(gameID, playerID),
+	})
+	s.wsManager.SendToPlayer(gameID, playerID, msg.Encode())
+	return nil
+}
+
+// chatRecipients returns the players a channel reaches, or nil for the
+// whole game
+func (s *GameEventsService) chatRecipients(gameID, playerID, channel string) ([]string, error) {
+	if channel == models.ChatChannelGame {
+		return nil, nil
+	}
+
+	if channel != "teamA" && channel != "teamB" {
+		return nil, errors.New("unknown chat channel")
+	}
+	team, members := s.chatTeam(gameID, playerID)
+	if team != channel {
+		return nil, errors.New("players can only chat on their own team's channel")
+	}
+	return members, nil
+}
+
+// chatTeam returns the channel of a player's team and its members. Teams
+// come from the current match while there is one, and otherwise from the
+// game itself, whose first team is "teamA".
+func (s *GameEventsService) chatTeam(gameID, playerID string) (string, []string) {
+	if match, err := s.matchService.currentGameMatch(gameID); err == nil {
+		switch match.TeamOf(playerID) {
+		case "teamA":
+			return "teamA", match.TeamAPlayers
+		case "teamB":
+			return "teamB", match.TeamBPlayers
+		}
+		return "", nil
+	}
+
+	game, err := s.matchService.gameService.GetGame(gameID)
+	if err != nil {
+		return "", nil
+	}
+	channels := []string{"teamA", "teamB"}
+	for i, team := range game.Teams {
+		if i >= len(channels) {
+			break
+		}
+		members := make([]string, 0, len(team.Players))
+		for _, player := range team.Players {
+			members = append(members, player.ID)
+		}
+		if containsPlayer(members, playerID) {
+			return channels[i], members
+		}
+	}
+	return "", nil
+}
+
+// checkChatDuringStage keeps chat from giving the card away while a stage
+// is in play
+func (s *GameEventsService) checkChatDuringStage(gameID, playerID, channel, text string) error {
+	s.mu.RLock()
+	stageTimer, active := s.activeStages[gameID]
+	var card *models.WordCard
+	if active {
+		card = stageTimer.card
+	}
+	s.mu.RUnlock()
+	if card == nil {
+		return nil
+	}
+
+	match, err := s.matchService.GetActiveMatch(gameID)
+	if err != nil {
+		return nil
+	}
+	role := match.RoleOf(playerID)
+	if role == models.PlayerRoleClueGiver {
+		return errors.New("clue-givers cannot chat during their stage")
+	}
+	if role.CanSeeCard() && channel == models.ChatChannelGame {
+		rules := models.ClueRules{SoundsLike: models.SoundsLikeOff}
+		if violation, flags := checkClue(text, card, rules); violation != nil || len(flags) > 0 {
+			return errors.New("chat message mentions the card")
+		}
+	}
+	return nil
+}
+
+// allowChatLocked applies the per-player rate limit and notes the send.
+// Must be called with s.mu held.
+func (s *GameEventsService) allowChatLocked(gameID, playerID string, now time.Time) bool {
+	key := gameID + "/" + playerID
+	recent := s.chatSends[key][:0]
+	for _, sentAt := range s.chatSends[key] {
+		if now.Sub(sentAt) < s.chatRateWindow {
+			recent = append(recent, sentAt)
+		}
+	}
+	if len(recent) >= s.chatRateCount {
+		s.chatSends[key] = recent
+		return false
+	}
+	s.chatSends[key] = append(recent, now)
+	s.pruneChatSendsLocked(now)
+	return true
+}
+
+// pruneChatSendsLocked forgets players who have sent nothing within the
+// rate window. Must be called with s.mu held.
+func (s *GameEventsService) pruneChatSendsLocked(now time.Time) {
+	for key, sends := range s.chatSends {
+		if len(sends) == 0 || now.Sub(sends[len(sends)-1]) >= s.chatRateWindow {
+			delete(s.chatSends, key)
+		}
+	}
+}
+
+// forgetChatSends drops a player's rate-limit entry once they leave the game
+func (s *GameEventsService) forgetChatSends(gameID, playerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.chatSends, gameID+"/"+playerID)
+}
+
+// ChatSenders returns how many players the rate limit is tracking
+func (s *GameEventsService) ChatSenders() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.chatSends)
+}
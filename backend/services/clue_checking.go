@@ -0,0 +1,179 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"taboo-game/helpers"
+	"taboo-game/models"
+	"unicode/utf8"
+)
+
+const (
+	// minPartLength is the shortest part a clue word and a card word can
+	// share, at the start or end of the longer one, that clearly counts as
+	// the card word; shorter or inner parts are only flagged to the spotters
+	minPartLength = 5
+
+	// minNearLength is the shortest card word a near spelling is flagged for
+	minNearLength = 5
+)
+
+// clueStopWords are never matched on their own, as clue words or as words
+// of a multi-word card entry
+var clueStopWords = map[string]bool{
+	"a": true, "all": true, "an": true, "and": true, "are": true, "but": true, "can": true,
+	"for": true, "has": true, "have": true, "in": true, "is": true, "it": true, "not": true,
+	"of": true, "on": true, "or": true, "that": true, "the": true, "them": true, "they": true,
+	"this": true, "to": true, "was": true, "with": true, "you": true,
+}
+
+// checkClue compares a clue with the card's target and taboo words. It
+// returns the evidence of the first clear violation, if any, and every
+// other match for the spotters to judge.
+func checkClue(clue string, card *models.WordCard, rules models.ClueRules) (violation *models.ClueEvidence, flags []*models.ClueEvidence) {
+	tokens := helpers.Tokenize(clue)
+	flagged := make(map[[2]string]bool)
+	flag := func(evidence *models.ClueEvidence, clear bool) {
+		key := [2]string{evidence.ClueWord, evidence.CardWord}
+		switch {
+		case flagged[key]:
+		case clear && violation == nil:
+			violation = evidence
+		default:
+			flags = append(flags, evidence)
+		}
+		flagged[key] = true
+	}
+
+	for _, cardWord := range append([]string{card.TargetWord}, card.TabooWords...) {
+		for _, part := range cardWordParts(cardWord) {
+			for i, token := range tokens {
+				// Two clue words that spell the card word together
+				if i+1 < len(tokens) && token+tokens[i+1] == part {
+					split := token + " " + tokens[i+1]
+					flag(clueEvidence(clue, split, cardWord, models.ClueMatchCompound, fmt.Sprintf("%q spells %q", split, part)), true)
+				}
+				if clueStopWords[token] {
+					continue
+				}
+
+				if match, isClear, found := matchClueWord(token, part); found {
+					flag(clueEvidence(clue, token, cardWord, match, spellingReason(match, token, part)), isClear)
+				} else if match, reason, found := matchClueSound(token, part, rules.SoundsLike); found {
+					flag(clueEvidence(clue, token, cardWord, match, reason), false)
+				}
+			}
+		}
+	}
+	return violation, flags
+}
+
+// cardWordParts returns the words of a card entry worth matching on their
+// own, plus the entry run together when it has several words
+func cardWordParts(cardWord string) []string {
+	words := helpers.Tokenize(cardWord)
+	parts := make([]string, 0, len(words)+1)
+	for _, word := range words {
+		if !clueStopWords[word] {
+			parts = append(parts, word)
+		}
+	}
+	if len(words) > 1 {
+		parts = append(parts, strings.Join(words, ""))
+	}
+	return parts
+}
+
+// matchClueWord compares one clue word with one card word
+func matchClueWord(token, part string) (match models.ClueMatch, clear bool, found bool) {
+	tokenLength, partLength := utf8.RuneCountInString(token), utf8.RuneCountInString(part)
+
+	switch {
+	case token == part:
+		return models.ClueMatchExact, true, true
+	case helpers.Stem(token) == helpers.Stem(part):
+		return models.ClueMatchStem, true, true
+	case partLength >= 3 && strings.Contains(token, part):
+		return models.ClueMatchCompound, clearPart(token, part), true
+	case tokenLength >= 3 && strings.Contains(part, token):
+		return models.ClueMatchPart, clearPart(part, token), true
+	case partLength >= minNearLength && helpers.EditDistance(token, part) <= 1:
+		return models.ClueMatchNear, false, true
+	}
+	return "", false, false
+}
+
+// clearPart reports whether a part of a word is long enough, and sits at
+// the start or end of the word, to clearly stand for it
+func clearPart(word, part string) bool {
+	return utf8.RuneCountInString(part) >= minPartLength &&
+		(strings.HasPrefix(word, part) || strings.HasSuffix(word, part))
+}
+
+// matchClueSound compares how one clue word and one card word sound, as
+// far as the game's sensitivity asks
+func matchClueSound(token, part string, sensitivity models.SoundsLikeSensitivity) (match models.ClueMatch, reason string, found bool) {
+	if sensitivity == models.SoundsLikeOff || utf8.RuneCountInString(token) < 3 || utf8.RuneCountInString(part) < 3 {
+		return "", "", false
+	}
+
+	tokenCodes, partCodes := metaphoneCodes(token), metaphoneCodes(part)
+	if sensitivity == models.SoundsLikeLow {
+		tokenCodes, partCodes = tokenCodes[:1], partCodes[:1]
+	}
+	for _, tokenCode := range tokenCodes {
+		for _, partCode := range partCodes {
+			if len(tokenCode) >= 2 && tokenCode == partCode {
+				return models.ClueMatchSoundsLike, fmt.Sprintf("%q sounds like %q (both %s)", token, part, tokenCode), true
+			}
+		}
+	}
+
+	if sensitivity == models.SoundsLikeLow {
+		return "", "", false
+	}
+	syllables := 2
+	if sensitivity == models.SoundsLikeHigh {
+		syllables = 1
+	}
+	if rhyme := helpers.RhymeKey(token, syllables); utf8.RuneCountInString(rhyme) >= 2 && rhyme == helpers.RhymeKey(part, syllables) {
+		return models.ClueMatchRhyme, fmt.Sprintf("%q rhymes with %q (-%s)", token, part, rhyme), true
+	}
+	return "", "", false
+}
+
+// metaphoneCodes returns a word's primary Double Metaphone code followed by
+// its alternate one
+func metaphoneCodes(word string) []string {
+	primary, alternate := helpers.DoubleMetaphone(word)
+	return []string{primary, alternate}
+}
+
+func spellingReason(match models.ClueMatch, token, part string) string {
+	switch match {
+	case models.ClueMatchExact:
+		return fmt.Sprintf("%q is on the card", token)
+	case models.ClueMatchStem:
+		return fmt.Sprintf("%q is a form of %q", token, part)
+	case models.ClueMatchCompound:
+		return fmt.Sprintf("%q contains %q", token, part)
+	case models.ClueMatchPart:
+		return fmt.Sprintf("%q is part of %q", token, part)
+	}
+	return fmt.Sprintf("%q is spelled almost like %q", token, part)
+}
+
+func clueEvidence(clue, clueWord, cardWord string, match models.ClueMatch, reason string) *models.ClueEvidence {
+	return &models.ClueEvidence{Clue: clue, ClueWord: clueWord, CardWord: cardWord, Match: match, Reason: reason}
+}
+
+// violationTypeFor is the category a clue match is called as
+func violationTypeFor(match models.ClueMatch) models.ViolationType {
+	switch match {
+	case models.ClueMatchExact, models.ClueMatchStem:
+		return models.ViolationTypeTabooWord
+	case models.ClueMatchNear, models.ClueMatchSoundsLike, models.ClueMatchRhyme:
+		return models.ViolationTypeSoundsLike
+	}
+	return models.ViolationTypePartial
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"taboo-game/models"
+	"time"
+)
+
+// NewDomainEvent encodes a payload into an event ready to be appended to a log
+func NewDomainEvent(gameID string, eventType models.DomainEventType, data interface{}) (models.DomainEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return models.DomainEvent{}, fmt.Errorf("error encoding %s event: %v", eventType, err)
+	}
+
+	return models.DomainEvent{
+		GameID:        gameID,
+		Type:          eventType,
+		SchemaVersion: models.DomainEventSchemaVersion,
+		Timestamp:     time.Now(),
+		Data:          payload,
+	}, nil
+}
+
+// MemoryEventStore keeps event logs in process memory
+type MemoryEventStore struct {
+	mu   sync.RWMutex
+	logs map[string][]models.DomainEvent
+}
+
+func NewMemoryEventStore() *MemoryEventStore {
+	return &MemoryEventStore{
+		logs: make(map[string][]models.DomainEvent),
+	}
+}
+
+func (s *MemoryEventStore) Append(events ...models.DomainEvent) ([]models.DomainEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	appended := make([]models.DomainEvent, 0, len(events))
+	for _, event := range events {
+		event.Version = len(s.logs[event.GameID]) + 1
+		s.logs[event.GameID] = append(s.logs[event.GameID], event)
+		appended = append(appended, event)
+	}
+	return appended, nil
+}
+
+func (s *MemoryEventStore) Load(gameID string) ([]models.DomainEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]models.DomainEvent{}, s.logs[gameID]...), nil
+}
+
+func (s *MemoryEventStore) GameIDs() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.logs))
+	for gameID := range s.logs {
+		ids = append(ids, gameID)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// FileEventStore keeps one JSON-lines log file per game in a directory so
+// that games survive a restart
+type FileEventStore struct {
+	mu       sync.Mutex
+	dir      string
+	versions map[string]int
+}
+
+func NewFileEventStore(dir string) (*FileEventStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating event log directory: %v", err)
+	}
+
+	return &FileEventStore{
+		dir:      dir,
+		versions: make(map[string]int),
+	}, nil
+}
+
+// Append writes the events of each game to its log file in a single write
+func (s *FileEventStore) Append(events ...models.DomainEvent) ([]models.DomainEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	appended := make([]models.DomainEvent, 0, len(events))
+	versions := make(map[string]int)
+	lines := make(map[string][]byte)
+	var order []string
+	for _, event := range events {
+		version, known := versions[event.GameID]
+		if !known {
+			var err error
+			if version, err = s.lastVersion(event.GameID); err != nil {
+				return nil, err
+			}
+			order = append(order, event.GameID)
+		}
+		event.Version = version + 1
+		versions[event.GameID] = event.Version
+
+		line, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding event: %v", err)
+		}
+		lines[event.GameID] = append(append(lines[event.GameID], line...), '\n')
+		appended = append(appended, event)
+	}
+
+	for _, gameID := range order {
+		if err := s.writeLog(gameID, lines[gameID]); err != nil {
+			return nil, err
+		}
+		s.versions[gameID] = versions[gameID]
+	}
+	return appended, nil
+}
+
+// lastVersion returns the version of the last event in a game's log file
+func (s *FileEventStore) lastVersion(gameID string) (int, error) {
+	if version, known := s.versions[gameID]; known {
+		return version, nil
+	}
+	path, err := s.logPath(gameID)
+	if err != nil {
+		return 0, err
+	}
+	existing, err := readEventLog(path)
+	if err != nil {
+		return 0, err
+	}
+	return len(existing), nil
+}
+
+func (s *FileEventStore) writeLog(gameID string, data []byte) error {
+	path, err := s.logPath(gameID)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening event log: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("error writing event log: %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("error syncing event log: %v", err)
+	}
+	return nil
+}
+
+func (s *FileEventStore) Load(gameID string) ([]models.DomainEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path, err := s.logPath(gameID)
+	if err != nil {
+		return nil, err
+	}
+	return readEventLog(path)
+}
+
+func (s *FileEventStore) GameIDs() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading event log directory: %v", err)
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".jsonl") {
+			ids = append(ids, strings.TrimSuffix(entry.Name(), ".jsonl"))
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (s *FileEventStore) logPath(gameID string) (string, error) {
+	if gameID == "" || strings.ContainsAny(gameID, `/\.`) {
+		return "", errors.New("invalid game ID")
+	}
+	return filepath.Join(s.dir, gameID+".jsonl"), nil
+}
+
+func readEventLog(path string) ([]models.DomainEvent, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []models.DomainEvent{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening event log: %v", err)
+	}
+	defer file.Close()
+
+	events := make([]models.DomainEvent, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event models.DomainEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("error decoding event log: %v", err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading event log: %v", err)
+	}
+	return events, nil
+}
@@ -2,17 +2,34 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
+	"strings"
 	"sync"
+	"taboo-game/models"
+	"taboo-game/types"
 	"taboo-game/websocket"
 	"time"
 )
 
 type GameEventsService struct {
-	matchService *MatchService
-	wordService  *WordService
-	wsManager    *websocket.Manager
-	activeStages map[string]*StageTimer
-	mu           sync.RWMutex
+	matchService  *MatchService
+	wordService   *WordService
+	wsManager     types.WebSocketManagerInterface
+	activeStages  map[string]*StageTimer
+	violations    map[string][]*models.Violation
+	referees      map[string]string
+	disputeWindow time.Duration
+	stageDuration time.Duration
+
+	chatHistory    map[string][]models.ChatMessage
+	chatSends      map[string][]time.Time // Recent sends per game and player, for rate limiting
+	chatRateCount  int
+	chatRateWindow time.Duration
+
+	restoredAt map[string]time.Time // Games restored after a server restart
+
+	mu sync.RWMutex
 }
 
 type StageTimer struct {
@@ -20,56 +37,121 @@ type StageTimer struct {
 	ticker  *time.Ticker
 	done    chan bool
 	endTime time.Time
+	card    *models.WordCard
 }
 
-func NewGameEventsService(ms *MatchService, ws *WordService, wm *websocket.Manager) *GameEventsService {
+func NewGameEventsService(ms *MatchService, ws *WordService, wm types.WebSocketManagerInterface) *GameEventsService {
 	return &GameEventsService{
-		matchService: ms,
-		wordService:  ws,
-		wsManager:    wm,
-		activeStages: make(map[string]*StageTimer),
+		matchService:  ms,
+		wordService:   ws,
+		wsManager:     wm,
+		activeStages:  make(map[string]*StageTimer),
+		violations:    make(map[string][]*models.Violation),
+		referees:      make(map[string]string),
+		disputeWindow: defaultDisputeWindow,
+		stageDuration: defaultStageDuration,
+
+		chatHistory:    make(map[string][]models.ChatMessage),
+		chatSends:      make(map[string][]time.Time),
+		chatRateCount:  defaultChatRateCount,
+		chatRateWindow: defaultChatRateWindow,
+
+		restoredAt: make(map[string]time.Time),
 	}
 }
 
-func (s *GameEventsService) StartStage(gameID string, stageNum int) error {
+// Time each stage runs for
+const defaultStageDuration = 3 * time.Minute
+
+// SetStageDuration changes how long the stages started from now on run for
+func (s *GameEventsService) SetStageDuration(duration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stageDuration = duration
+}
+
+// StartStage starts the timer of the game's current stage and deals its
+// first card. Only the host or one of the stage's clue-givers can start it,
+// and not while another stage is running.
+func (s *GameEventsService) StartStage(gameID, playerID string, stageNum int) error {
+	game, err := s.matchService.gameService.GetGame(gameID)
+	if err != nil {
+		return err
+	}
+	isHost := game.HostID != "" && game.HostID == playerID
+	match, err := s.matchService.GetActiveMatch(gameID)
+	if err != nil && !isHost {
+		return err
+	}
+	if !isHost && !containsPlayer(match.CurrentStage.ClueGivers, playerID) {
+		return errors.New("only the host or a clue-giver can start the stage")
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	previous, running := s.activeStages[gameID]
+	if running && time.Now().Before(previous.endTime) {
+		return errors.New("a stage is already running")
+	}
+
 	// Get new word card
 	wordCard, err := s.wordService.GetNextCard()
 	if err != nil {
 		return err
 	}
 
-	duration := 3 * time.Minute
+	cardHolders, err := s.matchService.drawCard(gameID, stageNum, wordCard)
+	if err != nil {
+		return err
+	}
+
+	duration := s.stageDuration
 	// Initialize stage timer
 	timer := &StageTimer{
 		timer:   time.NewTimer(duration),
 		ticker:  time.NewTicker(time.Second),
 		done:    make(chan bool),
 		endTime: time.Now().Add(duration),
+		card:    wordCard,
+	}
+	if running {
+		close(previous.done)
 	}
 	s.activeStages[gameID] = timer
 
 	// Start timer goroutine
 	go s.runStageTimer(gameID, timer)
 
-	// Broadcast stage start
-	msg := websocket.Message{
-		Type:   websocket.StartStage,
-		GameID: gameID,
-		Payload: map[string]interface{}{
-			"stage_num": stageNum,
-			"word_card": wordCard,
-			"duration":  int(duration.Seconds()),
-		},
-	}
-	data, _ := json.Marshal(msg)
-	s.wsManager.SendToGame(gameID, data)
+	s.sendCard(gameID, cardHolders, wordCard, func(card *models.WordCard) websocket.Message {
+		return websocket.NewMessage(websocket.StartStage, gameID, "", websocket.StartStagePayload{
+			StageNum: stageNum,
+			Duration: int(duration.Seconds()),
+			WordCard: card,
+		})
+	})
+	s.announceVoiceRooms(gameID)
 
 	return nil
 }
 
+// dealCard records a card drawn for a stage and makes it the current word
+func (s *GameEventsService) dealCard(gameID string, stageNum int, card *models.WordCard, message func(card *models.WordCard) websocket.Message) error {
+	cardHolders, err := s.matchService.drawCard(gameID, stageNum, card)
+	if err != nil {
+		return err
+	}
+	s.sendCard(gameID, cardHolders, card, message)
+	return nil
+}
+
+// sendCard sends a card to the stage's clue-givers and spotters only;
+// everyone else is sent the message built without it
+func (s *GameEventsService) sendCard(gameID string, cardHolders []string, card *models.WordCard, message func(card *models.WordCard) websocket.Message) {
+	s.wsManager.SendToPlayers(gameID, cardHolders, message(card).Encode())
+	s.wsManager.SendToGameExcept(gameID, cardHolders, message(nil).Encode())
+}
+
 func (s *GameEventsService) runStageTimer(gameID string, timer *StageTimer) {
 	defer timer.ticker.Stop()
 	defer timer.timer.Stop()
@@ -78,17 +160,12 @@ func (s *GameEventsService) runStageTimer(gameID string, timer *StageTimer) {
 		select {
 		case <-timer.ticker.C:
 			remaining := time.Until(timer.endTime).Seconds()
-			msg := websocket.Message{
-				Type:   websocket.TimerUpdate,
-				GameID: gameID,
-				Payload: map[string]interface{}{
-					"remaining": int(remaining),
-				},
-			}
-			data, _ := json.Marshal(msg)
-			s.wsManager.SendToGame(gameID, data)
+			msg := websocket.NewMessage(websocket.TimerUpdate, gameID, "", websocket.TimerUpdatePayload{
+				Remaining: int(remaining),
+			})
+			s.wsManager.SendToGame(gameID, msg.Encode())
 		case <-timer.timer.C:
-			s.handleStageEnd(gameID)
+			s.handleStageEnd(gameID, timer)
 			return
 		case <-timer.done:
 			return
@@ -96,42 +173,190 @@ func (s *GameEventsService) runStageTimer(gameID string, timer *StageTimer) {
 	}
 }
 
-func (s *GameEventsService) handleStageEnd(gameID string) {
+func (s *GameEventsService) handleStageEnd(gameID string, timer *StageTimer) {
+	// The stage is over unless a newer one has replaced its timer
+	s.mu.Lock()
+	if s.activeStages[gameID] != timer {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.activeStages, gameID)
+	s.mu.Unlock()
+
 	// Update match state
-	match, err := s.matchService.GetCurrentMatch(gameID)
+	match, err := s.matchService.GetActiveMatch(gameID)
 	if err != nil {
 		return
 	}
 
+	s.mu.RLock()
+	summary := match.CurrentStage.Summary()
+	s.mu.RUnlock()
+	msg := websocket.NewMessage(websocket.StageEnd, gameID, "", websocket.StageEndPayload{Summary: summary})
+	s.wsManager.SendToGame(gameID, msg.Encode())
+
 	// Move to next stage or end match
 	// Hard coded to 4 stages for now
 	if match.CurrentStage.Number < 4 {
-		s.matchService.NextStage(gameID)
+		err = s.matchService.NextStage(gameID)
 	} else {
-		s.matchService.EndCurrentMatch(gameID)
+		err = s.matchService.EndCurrentMatch(gameID)
 	}
+	if err != nil {
+		log.Printf("Error ending stage of game %s: %v", gameID, err)
+	}
+	s.announceVoiceRooms(match.GameID)
 }
 
+// HandleClue checks a clue-giver's clue against the current card. A clue
+// that clearly breaks the rules raises a violation and is not passed on;
+// any other clue is relayed to the clue-giver's team and the spotters, who
+// are also asked to judge words that come close to the card or sound like
+// or rhyme with it.
 func (s *GameEventsService) HandleClue(gameID, playerID, clue string) error {
-	// Validate clue giver's turn
-	// Broadcast clue to all players
+	if strings.TrimSpace(clue) == "" {
+		return errors.New("clue is empty")
+	}
+	match, err := s.matchService.GetActiveMatch(gameID)
+	if err != nil {
+		return err
+	}
+	stage := match.CurrentStage
+	if !containsPlayer(stage.ClueGivers, playerID) {
+		return errors.New("only clue-givers in the active stage can give clues")
+	}
+
+	s.mu.RLock()
+	stageTimer, active := s.activeStages[gameID]
+	var card *models.WordCard
+	if active {
+		card = stageTimer.card
+	}
+	s.mu.RUnlock()
+	if card == nil {
+		return errors.New("no active stage")
+	}
+
+	rules := s.matchService.getGameRules(gameID).ClueRules()
+	violation, flags := checkClue(clue, card, rules)
+	if violation != nil {
+		_, err := s.reportClueViolation(match, card.ID, violation)
+		return err
+	}
+
+	recipients := make([]string, 0, len(stage.ClueGivers)+len(stage.Guessers)+len(stage.Spotters))
+	recipients = append(append(append(recipients, stage.ClueGivers...), stage.Guessers...), stage.Spotters...)
+	relayed := websocket.NewMessage(websocket.GiveClue, gameID, playerID, websocket.GiveCluePayload{Clue: clue})
+	s.wsManager.SendToPlayers(gameID, recipients, relayed.Encode())
+
+	s.flagToSpotters(gameID, playerID, stage, flags, 0)
 	return nil
 }
 
+// flagToSpotters asks the stage's spotters to judge what the clue checks
+// found in a clue-giver's clue, or in their speech at timestampMS
+func (s *GameEventsService) flagToSpotters(gameID, playerID string, stage *models.MatchStage, flags []*models.ClueEvidence, timestampMS int64) {
+	if len(flags) == 0 {
+		return
+	}
+
+	payload := websocket.ClueFlaggedPayload{Flags: make([]websocket.ClueFlag, 0, len(flags)), TimestampMS: timestampMS}
+	for _, evidence := range flags {
+		payload.Flags = append(payload.Flags, websocket.ClueFlag{
+			Evidence:      evidence,
+			ViolationType: violationTypeFor(evidence.Match),
+		})
+	}
+	flagged := websocket.NewMessage(websocket.ClueFlagged, gameID, playerID, payload)
+	s.wsManager.SendToPlayers(gameID, stage.Spotters, flagged.Encode())
+}
+
+// HandleGuess judges a guesser's typed guess against the current card. A
+// correct guess scores for the guessing team and draws the next card, and
+// a near miss is hinted as close.
 func (s *GameEventsService) HandleGuess(gameID, playerID, guess string) error {
-	// Validate guess and update score
-	// Broadcast result to all players
+	match, err := s.matchService.GetActiveMatch(gameID)
+	if err != nil {
+		return err
+	}
+	stage := match.CurrentStage
+	if match.RoleOf(playerID) != models.PlayerRoleGuesser {
+		return errors.New("only guessers in the active stage can guess")
+	}
+	rules := s.matchService.getGameRules(gameID).GuessingRules()
+
+	// Judging and replacing the card together means only the first of
+	// several correct guesses scores
+	s.mu.Lock()
+	stageTimer, active := s.activeStages[gameID]
+	if !active || stageTimer.card == nil {
+		s.mu.Unlock()
+		return errors.New("no active stage")
+	}
+	card := stageTimer.card
+	verdict := judgeGuess(guess, card, rules)
+	var next *models.WordCard
+	if verdict == models.GuessCorrect {
+		if next, err = s.wordService.GetNextCard(); err != nil {
+			s.mu.Unlock()
+			return err
+		}
+		stageTimer.card = next
+	}
+	s.mu.Unlock()
+
+	err = s.matchService.ProcessGuessAttempt(gameID, match.ID, &models.GuessAttempt{
+		CardID:      card.ID,
+		PlayerID:    playerID,
+		Guess:       guess,
+		Verdict:     verdict,
+		Correct:     verdict == models.GuessCorrect,
+		TeamID:      stage.ActiveTeamID,
+		StageID:     stage.ID,
+		TimestampMS: time.Now().UnixMilli(),
+	})
+	if err != nil {
+		s.putCardBack(stageTimer, card, next)
+		return err
+	}
+
+	result := websocket.GuessResultPayload{Guess: guess, Verdict: verdict}
+	if verdict == models.GuessCorrect {
+		result.Answer = card.TargetWord
+	}
+	s.wsManager.SendToGame(gameID, websocket.NewMessage(websocket.GuessResult, gameID, playerID, result).Encode())
+
+	if next != nil {
+		err = s.dealCard(gameID, stage.Number, next, func(card *models.WordCard) websocket.Message {
+			return websocket.NewMessage(websocket.CardDrawn, gameID, "", websocket.CardDrawnPayload{
+				StageNum: stage.Number,
+				WordCard: card,
+			})
+		})
+		if err != nil {
+			s.putCardBack(stageTimer, card, next)
+			return err
+		}
+	}
 	return nil
 }
 
-func (s *GameEventsService) HandleViolation(gameID, reporterID, violationType string) error {
-	// Validate violation report and update score
-	// Broadcast violation to all players
-	return nil
+// putCardBack makes a card the stage's card again when the next one drawn
+// in its place could not be recorded
+func (s *GameEventsService) putCardBack(stageTimer *StageTimer, card, next *models.WordCard) {
+	if next == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stageTimer.card == next {
+		stageTimer.card = card
+	}
 }
-func encodeMessage(msg websocket.Message) []byte {
-	data, _ := json.Marshal(msg)
-	return data
+
+func (s *GameEventsService) HandleViolation(gameID, reporterID, violationType string) error {
+	_, err := s.ReportViolation(gameID, reporterID, violationType, "")
+	return err
 }
 
 func (s *GameEventsService) ProcessEvent(eventData []byte) error {
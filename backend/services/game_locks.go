@@ -0,0 +1,28 @@
+package services
+
+import "sync"
+
+// gameLocks hands out one mutex per game, so that changes to a game are
+// serialised without holding up other games
+type gameLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func newGameLocks() *gameLocks {
+	return &gameLocks{locks: make(map[string]*sync.Mutex)}
+}
+
+// lock locks the game and returns the function that unlocks it
+func (l *gameLocks) lock(gameID string) func() {
+	l.mu.Lock()
+	lock, exists := l.locks[gameID]
+	if !exists {
+		lock = &sync.Mutex{}
+		l.locks[gameID] = lock
+	}
+	l.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"taboo-game/models"
+)
+
+// ProjectGame builds a game from its event log. When upTo is positive the
+// projection stops after the event with that version, which allows a game to
+// be replayed step by step.
+func ProjectGame(events []models.DomainEvent, upTo int) (*models.Game, error) {
+	if len(events) == 0 {
+		return nil, errors.New("game not found")
+	}
+
+	var game *models.Game
+	for _, event := range events {
+		if upTo > 0 && event.Version > upTo {
+			break
+		}
+
+		if game == nil {
+			if event.Type != models.DomainEventGameCreated {
+				return nil, errors.New("event log does not start with game creation")
+			}
+			game = &models.Game{ID: event.GameID}
+		}
+
+		if err := applyGameEvent(game, event); err != nil {
+			return nil, err
+		}
+	}
+	return game, nil
+}
+
+// applyGameEvent folds a single event into the game projection. Events
+// that change the game move it to a new version; card, guess, violation,
+// transcript, turn and team switch events only affect match state.
+func applyGameEvent(game *models.Game, event models.DomainEvent) error {
+	if event.SchemaVersion > models.DomainEventSchemaVersion {
+		return fmt.Errorf("unsupported schema version %d for %s event", event.SchemaVersion, event.Type)
+	}
+
+	changed := true
+	switch event.Type {
+	case models.DomainEventGameCreated:
+		var data models.GameCreatedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		game.CreatedAt = data.CreatedAt
+		game.Status = models.GameStatusWaiting
+		game.Teams = data.Teams
+		game.Matches = []models.Match{}
+		game.Rules = data.Rules
+
+	case models.DomainEventPlayerJoined:
+		var data models.PlayerJoinedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		for i := range game.Teams {
+			if game.Teams[i].ID == data.Player.TeamID {
+				game.Teams[i].Players = append(game.Teams[i].Players, data.Player)
+			}
+		}
+		if game.HostID == "" {
+			game.HostID = data.Player.ID
+		}
+
+	case models.DomainEventRulesUpdated:
+		var data models.RulesUpdatedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		game.Rules = data.Rules
+
+	case models.DomainEventGameStarted:
+		var data models.GameStartedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		game.Status = models.GameStatusInProgress
+		game.Matches = data.Matches
+
+	case models.DomainEventGameEnded:
+		game.Status = models.GameStatusCompleted
+
+	case models.DomainEventMatchStarted:
+		var data models.MatchStartedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		match := findGameMatch(game, data.MatchID)
+		if match == nil {
+			changed = false
+			break
+		}
+		match.Status = models.MatchStatusInProgress
+		match.StartedAt = event.Timestamp
+
+	case models.DomainEventStageStarted:
+		var data models.StageStartedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		match := findGameMatch(game, data.Stage.MatchID)
+		if match == nil {
+			changed = false
+			break
+		}
+		for i := range match.Stages {
+			if match.Stages[i].Status == models.StageStatusActive {
+				match.Stages[i].Status = models.StageStatusCompleted
+				match.Stages[i].EndedAt = event.Timestamp
+			}
+		}
+		match.Stages = append(match.Stages, models.Stage{
+			ID:             data.Stage.ID,
+			MatchID:        data.Stage.MatchID,
+			Number:         data.Stage.Number,
+			ActiveTeamID:   data.Stage.ActiveTeamID,
+			ClueGivers:     data.Stage.ClueGivers,
+			Guessers:       data.Stage.Guessers,
+			SpottingTeamID: data.Stage.SpottingTeamID,
+			Spotters:       data.Stage.Spotters,
+			Status:         models.StageStatusActive,
+			StartedAt:      event.Timestamp,
+		})
+
+	case models.DomainEventStageEnded:
+		var data models.StageEndedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		stage := findGameStage(game, data.MatchID, data.StageID)
+		if stage == nil || stage.Status != models.StageStatusActive {
+			changed = false
+			break
+		}
+		stage.Status = models.StageStatusCompleted
+		stage.EndedAt = event.Timestamp
+
+	case models.DomainEventScoreRecorded:
+		var data models.ScoreRecordedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		// A stage's score counts the points of its active team
+		stage := findGameStage(game, data.Entry.MatchID, data.Entry.StageID)
+		if stage == nil || stage.ActiveTeamID != data.Entry.TeamID {
+			changed = false
+			break
+		}
+		stage.Score += data.Entry.Points
+
+	case models.DomainEventMatchEnded:
+		var data models.MatchEndedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		match := findGameMatch(game, data.MatchID)
+		if match == nil {
+			changed = false
+			break
+		}
+		match.Status = models.MatchStatusCompleted
+		match.EndedAt = event.Timestamp
+		for i := range match.Stages {
+			if match.Stages[i].Status == models.StageStatusActive {
+				match.Stages[i].Status = models.StageStatusCompleted
+				match.Stages[i].EndedAt = event.Timestamp
+			}
+		}
+
+	default:
+		changed = false
+	}
+
+	if changed {
+		game.Version++
+	}
+	return nil
+}
+
+func findGameMatch(game *models.Game, matchID string) *models.Match {
+	for i := range game.Matches {
+		if game.Matches[i].ID == matchID {
+			return &game.Matches[i]
+		}
+	}
+	return nil
+}
+
+func findGameStage(game *models.Game, matchID, stageID string) *models.Stage {
+	match := findGameMatch(game, matchID)
+	if match == nil {
+		return nil
+	}
+	for i := range match.Stages {
+		if match.Stages[i].ID == stageID {
+			return &match.Stages[i]
+		}
+	}
+	return nil
+}
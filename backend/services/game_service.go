@@ -3,30 +3,48 @@ package services
 import (
 	"errors"
 	"taboo-game/models"
+	"taboo-game/types"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// GameService records every game change as an event in the game's log.
+// The games it returns are copies of projections of those logs. Changes to
+// a game are serialised by a per-game lock.
 type GameService struct {
-	games map[string]*models.Game
+	locks      *gameLocks
+	repository types.GameRepository
+	store      types.EventStoreInterface
 }
 
 func NewGameService() *GameService {
+	return NewGameServiceWithStore(NewMemoryEventStore())
+}
+
+func NewGameServiceWithStore(store types.EventStoreInterface) *GameService {
 	return &GameService{
-		games: make(map[string]*models.Game),
+		locks:      newGameLocks(),
+		repository: NewMemoryRepository(),
+		store:      store,
 	}
 }
 
+// SetRepository changes where the current state of games is kept. It must
+// be called before any game is created or rebuilt.
+func (s *GameService) SetRepository(repository types.GameRepository) {
+	s.repository = repository
+}
+
 func (s *GameService) CreateGame(teamSize int) (*models.Game, error) {
-	game := &models.Game{
-		ID:        uuid.New().String(),
+	gameID := uuid.New().String()
+	event, err := NewDomainEvent(gameID, models.DomainEventGameCreated, models.GameCreatedData{
 		CreatedAt: time.Now(),
-		Status:    "waiting",
 		Teams: []models.Team{
 			{
 				ID:      uuid.New().String(),
 				Name:    "Team 1",
+				GameID:  gameID,
 				Players: []models.Player{},
 				Score:   0,
 				Size:    teamSize,
@@ -34,22 +52,42 @@ func (s *GameService) CreateGame(teamSize int) (*models.Game, error) {
 			{
 				ID:      uuid.New().String(),
 				Name:    "Team 2",
+				GameID:  gameID,
 				Players: []models.Player{},
 				Score:   0,
 				Size:    teamSize,
 			},
 		},
-		Matches: []models.Match{},
+		Rules: models.DefaultGameRules(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer s.locks.lock(gameID)()
+
+	game, err := ProjectGame([]models.DomainEvent{event}, 0)
+	if err != nil {
+		return nil, err
 	}
 
-	s.games[game.ID] = game
-	return game, nil
+	if err := s.writeLocked([]models.DomainEvent{event}, game, nil, nil); err != nil {
+		return nil, err
+	}
+	return game.Clone(), nil
 }
 
-func (s *GameService) AddPlayer(gameID string, playerName string) (*models.Player, error) {
-	game, exists := s.games[gameID]
-	if !exists {
-		return nil, errors.New("game not found")
+// AddPlayer puts a new player on the first team with room. The game must be
+// at a version the caller expects, or ErrVersionConflict is returned.
+func (s *GameService) AddPlayer(gameID string, playerName string, expected models.VersionMatch) (*models.Player, error) {
+	defer s.locks.lock(gameID)()
+
+	game, err := s.repository.GetGame(gameID)
+	if err != nil {
+		return nil, err
+	}
+	if !expected.Allows(game.Version) {
+		return nil, models.ErrVersionConflict
 	}
 
 	if game.Status != "waiting" {
@@ -76,22 +114,31 @@ func (s *GameService) AddPlayer(gameID string, playerName string) (*models.Playe
 		JoinedAt: time.Now(),
 	}
 
-	targetTeam.Players = append(targetTeam.Players, player)
+	if _, err := s.recordEventLocked(game, models.DomainEventPlayerJoined, models.PlayerJoinedData{Player: player}); err != nil {
+		return nil, err
+	}
 	return &player, nil
 }
 
 func (s *GameService) GetGame(gameID string) (*models.Game, error) {
-	game, exists := s.games[gameID]
-	if !exists {
-		return nil, errors.New("game not found")
+	defer s.locks.lock(gameID)()
+
+	game, err := s.repository.GetGame(gameID)
+	if err != nil {
+		return nil, err
 	}
-	return game, nil
+	return game.Clone(), nil
 }
 
-func (s *GameService) StartGame(gameID string) (*models.Game, error) {
-	game, exists := s.games[gameID]
-	if !exists {
-		return nil, errors.New("game not found")
+func (s *GameService) StartGame(gameID string, expected models.VersionMatch) (*models.Game, error) {
+	defer s.locks.lock(gameID)()
+
+	game, err := s.repository.GetGame(gameID)
+	if err != nil {
+		return nil, err
+	}
+	if !expected.Allows(game.Version) {
+		return nil, models.ErrVersionConflict
 	}
 
 	// Validate team sizes
@@ -101,28 +148,39 @@ func (s *GameService) StartGame(gameID string) (*models.Game, error) {
 		}
 	}
 
-	game.Status = models.GameStatusInProgress
-	game.Matches = []models.Match{
+	matches := []models.Match{
 		createMatch(1, gameID),
 		createMatch(2, gameID),
 		createMatch(3, gameID),
 	}
+	started, err := s.recordEventLocked(game, models.DomainEventGameStarted, models.GameStartedData{Matches: matches})
+	if err != nil {
+		return nil, err
+	}
 
-	return game, nil
+	return started.Clone(), nil
 }
 
-func (s *GameService) EndGame(gameID string) (*models.Game, error) {
-	game, exists := s.games[gameID]
-	if !exists {
-		return nil, errors.New("game not found")
+func (s *GameService) EndGame(gameID string, expected models.VersionMatch) (*models.Game, error) {
+	defer s.locks.lock(gameID)()
+
+	game, err := s.repository.GetGame(gameID)
+	if err != nil {
+		return nil, err
+	}
+	if !expected.Allows(game.Version) {
+		return nil, models.ErrVersionConflict
 	}
 
 	if game.Status != models.GameStatusInProgress {
 		return nil, errors.New("game is not in progress")
 	}
 
-	game.Status = models.GameStatusCompleted
-	return game, nil
+	ended, err := s.recordEventLocked(game, models.DomainEventGameEnded, struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	return ended.Clone(), nil
 }
 
 // Helper function to create a match
@@ -136,10 +194,194 @@ func createMatch(number int, gameID string) models.Match {
 	}
 }
 
+// UpdateGame records the game's rules. All other fields are derived from
+// the event log and change only through their own events. The game must be
+// at the version it was read at, or ErrVersionConflict is returned.
 func (s *GameService) UpdateGame(game *models.Game) error {
-	if _, exists := s.games[game.ID]; !exists {
-		return errors.New("game not found")
+	defer s.locks.lock(game.ID)()
+
+	current, err := s.repository.GetGame(game.ID)
+	if err != nil {
+		return err
+	}
+	if game.Version != current.Version {
+		return models.ErrVersionConflict
+	}
+	updated, err := s.recordEventLocked(current, models.DomainEventRulesUpdated, models.RulesUpdatedData{Rules: game.Rules})
+	if err != nil {
+		return err
 	}
-	s.games[game.ID] = game
+	game.Version = updated.Version
 	return nil
 }
+
+// RecordEvent appends an event to a game's log and applies it to the game
+func (s *GameService) RecordEvent(gameID string, eventType models.DomainEventType, data interface{}) error {
+	event, err := NewDomainEvent(gameID, eventType, data)
+	if err != nil {
+		return err
+	}
+	return s.RecordEvents(gameID, []models.DomainEvent{event}, nil)
+}
+
+// RecordEvents appends events to a game's log and applies them to the game.
+// The matches they change are saved to their repository with them, in the
+// same transaction when the event store keeps matches too.
+func (s *GameService) RecordEvents(gameID string, events []models.DomainEvent, repository types.MatchRepository, matches ...*models.MatchDetails) error {
+	defer s.locks.lock(gameID)()
+
+	game, err := s.repository.GetGame(gameID)
+	if err != nil {
+		return err
+	}
+	_, err = s.commitLocked(game, events, repository, matches)
+	return err
+}
+
+// recordEventLocked must be called with the game's lock held. It returns
+// the game as the event left it.
+func (s *GameService) recordEventLocked(game *models.Game, eventType models.DomainEventType, data interface{}) (*models.Game, error) {
+	event, err := NewDomainEvent(game.ID, eventType, data)
+	if err != nil {
+		return nil, err
+	}
+	return s.commitLocked(game, []models.DomainEvent{event}, nil, nil)
+}
+
+// commitLocked applies events to a copy of a stored game and writes them
+// with it, so the stored game changes only once the events are in the log.
+// It must be called with the game's lock held.
+func (s *GameService) commitLocked(game *models.Game, events []models.DomainEvent, repository types.MatchRepository, matches []*models.MatchDetails) (*models.Game, error) {
+	changed := game.Clone()
+	for _, event := range events {
+		if err := applyGameEvent(changed, event); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.writeLocked(events, changed, repository, matches); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// writeLocked writes events with the game and matches they change, all or
+// none. A store that keeps the games and matches writes them in one
+// transaction; otherwise the projections, which are then only in memory,
+// are saved once the events are appended.
+func (s *GameService) writeLocked(events []models.DomainEvent, game *models.Game, repository types.MatchRepository, matches []*models.MatchDetails) error {
+	committer, ok := s.store.(types.Committer)
+	if ok && any(s.repository) == any(s.store) && (len(matches) == 0 || any(repository) == any(s.store)) {
+		_, err := committer.Commit(events, []*models.Game{game}, matches)
+		return err
+	}
+
+	if _, err := s.store.Append(events...); err != nil {
+		return err
+	}
+	if err := s.repository.SaveGames(game); err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	return repository.SaveMatches(matches...)
+}
+
+// activeGames returns copies of every game that has not ended, with the
+// version of the last event in each game's log
+func (s *GameService) activeGames() ([]*models.Game, map[string]int, error) {
+	games, err := s.repository.ListGames()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	active := make([]*models.Game, 0, len(games))
+	logVersions := make(map[string]int, len(games))
+	for _, game := range games {
+		unlock := s.locks.lock(game.ID)
+		if game.Status != models.GameStatusCompleted {
+			version, err := s.logVersion(game.ID)
+			if err != nil {
+				unlock()
+				return nil, nil, err
+			}
+			active = append(active, game.Clone())
+			logVersions[game.ID] = version
+		}
+		unlock()
+	}
+	return active, logVersions, nil
+}
+
+// logVersion returns the version of the last event in a game's log, or zero
+// when the log is empty
+func (s *GameService) logVersion(gameID string) (int, error) {
+	events, err := s.store.Load(gameID)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	return events[len(events)-1].Version, nil
+}
+
+// restoreGame keeps a game from a recovery snapshot unless the game's event
+// log has caught up with the log the snapshot was taken from, and reports
+// whether it did
+func (s *GameService) restoreGame(game *models.Game, snapshotLogVersion int) (bool, error) {
+	defer s.locks.lock(game.ID)()
+
+	version, err := s.logVersion(game.ID)
+	if err != nil {
+		return false, err
+	}
+	if version >= snapshotLogVersion {
+		if _, err := s.repository.GetGame(game.ID); err == nil {
+			return false, nil
+		}
+	}
+	return true, s.repository.SaveGames(game)
+}
+
+// Events returns a game's full event log
+func (s *GameService) Events(gameID string) ([]models.DomainEvent, error) {
+	events, err := s.store.Load(gameID)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, errors.New("game not found")
+	}
+	return events, nil
+}
+
+// ReplayGame rebuilds a game as it was right after the given event version.
+// A version of zero replays the whole log.
+func (s *GameService) ReplayGame(gameID string, version int) (*models.Game, error) {
+	events, err := s.Events(gameID)
+	if err != nil {
+		return nil, err
+	}
+	return ProjectGame(events, version)
+}
+
+// Rebuild restores every game from the event store, typically on startup
+func (s *GameService) Rebuild() error {
+	gameIDs, err := s.store.GameIDs()
+	if err != nil {
+		return err
+	}
+
+	games := make([]*models.Game, 0, len(gameIDs))
+	for _, gameID := range gameIDs {
+		events, err := s.store.Load(gameID)
+		if err != nil {
+			return err
+		}
+		game, err := ProjectGame(events, 0)
+		if err != nil {
+			return err
+		}
+		games = append(games, game)
+	}
+
+	return s.repository.SaveGames(games...)
+}
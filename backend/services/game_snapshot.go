@@ -0,0 +1,40 @@
+package services
+
+import (
+	"taboo-game/models"
+	"time"
+)
+
+// Snapshot returns the current state of a game as seen by one player
+func (s *GameEventsService) Snapshot(gameID, playerID string) (*models.GameSnapshot, error) {
+	game, err := s.matchService.gameService.GetGame(gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &models.GameSnapshot{
+		Game:   game,
+		Scores: s.matchService.GetScoreLedger(gameID),
+	}
+	s.mu.RLock()
+	if restoredAt, restored := s.restoredAt[gameID]; restored {
+		snapshot.RestoredAt = &restoredAt
+	}
+	s.mu.RUnlock()
+
+	match, err := s.matchService.currentGameMatch(gameID)
+	if err != nil {
+		return snapshot, nil
+	}
+	snapshot.Match = match.RedactedFor(playerID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if stage, active := s.activeStages[gameID]; active {
+		snapshot.Remaining = int(time.Until(stage.endTime).Seconds())
+		if match.RoleOf(playerID).CanSeeCard() {
+			snapshot.WordCard = stage.card
+		}
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"taboo-game/helpers"
+	"taboo-game/models"
+	"unicode/utf8"
+)
+
+// minTypoLength is the shortest answer typos are forgiven in; for shorter
+// ones a single edit often makes another word
+const minTypoLength = 4
+
+// judgeGuess compares a typed guess with the card's answer and alternates
+func judgeGuess(guess string, card *models.WordCard, rules models.GuessRules) models.GuessVerdict {
+	normalized := helpers.NormalizeAnswer(guess)
+	if normalized == "" {
+		return models.GuessWrong
+	}
+
+	closest := -1
+	for _, answer := range append([]string{card.TargetWord}, card.AlternateAnswers...) {
+		expected := helpers.NormalizeAnswer(answer)
+		if expected == "" {
+			continue
+		}
+		distance := helpers.EditDistance(normalized, expected)
+		if distance == 0 || (distance <= rules.MaxTypos && utf8.RuneCountInString(expected) >= minTypoLength) {
+			return models.GuessCorrect
+		}
+		if closest < 0 || distance < closest {
+			closest = distance
+		}
+	}
+
+	if closest >= 0 && closest <= rules.CloseDistance {
+		return models.GuessClose
+	}
+	return models.GuessWrong
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"errors"
+	"taboo-game/models"
+)
+
+// PlayerRole returns the player's role in the game's current stage.
+// Players outside any stage in play are spectators.
+func (s *MatchService) PlayerRole(gameID, playerID string) models.PlayerRole {
+	match, err := s.GetActiveMatch(gameID)
+	if err != nil {
+		return models.PlayerRoleSpectator
+	}
+	return match.RoleOf(playerID)
+}
+
+// SendToTeam delivers a message to the players of one team ("teamA" or
+// "teamB") in the game's current match
+func (s *MatchService) SendToTeam(gameID, teamID string, message []byte) error {
+	match, err := s.currentGameMatch(gameID)
+	if err != nil {
+		return err
+	}
+
+	switch teamID {
+	case "teamA":
+		s.wsManager.SendToPlayers(gameID, match.TeamAPlayers, message)
+	case "teamB":
+		s.wsManager.SendToPlayers(gameID, match.TeamBPlayers, message)
+	default:
+		return errors.New("unknown team")
+	}
+	return nil
+}
+
+// SendToRole delivers a message to the players holding a role in the game's
+// current stage. Spectators are every connection without a stage role.
+func (s *MatchService) SendToRole(gameID string, role models.PlayerRole, message []byte) error {
+	match, err := s.GetActiveMatch(gameID)
+	if err != nil {
+		return err
+	}
+	stage := match.CurrentStage
+
+	switch role {
+	case models.PlayerRoleClueGiver:
+		s.wsManager.SendToPlayers(gameID, stage.ClueGivers, message)
+	case models.PlayerRoleGuesser:
+		s.wsManager.SendToPlayers(gameID, stage.Guessers, message)
+	case models.PlayerRoleSpotter:
+		s.wsManager.SendToPlayers(gameID, stage.Spotters, message)
+	case models.PlayerRoleSpectator:
+		s.wsManager.SendToGameExcept(gameID, stagePlayers(stage), message)
+	default:
+		return errors.New("unknown role")
+	}
+	return nil
+}
+
+// currentGameMatch returns the game's match that has not completed yet,
+// preferring one with a stage in play
+func (s *MatchService) currentGameMatch(gameID string) (*models.MatchDetails, error) {
+	defer s.locks.lock(gameID)()
+
+	if match, err := s.activeMatchLocked(gameID); err == nil {
+		return match.Clone(), nil
+	}
+	for _, match := range s.gameMatches(gameID) {
+		if match.Status != models.MatchStatusCompleted {
+			return match.Clone(), nil
+		}
+	}
+	return nil, errors.New("no current match for game")
+}
+
+// drawCard records a card drawn for a stage, makes its word the active
+// match's current word and returns the players allowed to see it
+func (s *MatchService) drawCard(gameID string, stageNum int, card *models.WordCard) ([]string, error) {
+	defer s.locks.lock(gameID)()
+
+	data := models.CardDrawnData{StageNumber: stageNum, Card: *card}
+	match, err := s.activeMatchLocked(gameID)
+	if err != nil {
+		return []string{}, s.recordEventLocked(gameID, models.DomainEventCardDrawn, data)
+	}
+	match = match.Clone()
+	match.CurrentWord = card.TargetWord
+	if err := s.recordEventLocked(gameID, models.DomainEventCardDrawn, data, match); err != nil {
+		return nil, err
+	}
+	return match.CurrentStage.CardHolders(), nil
+}
+
+// addTranscript adds a chunk of speech to a stage, if it is still the
+// match's current stage
+func (s *MatchService) addTranscript(gameID, matchID, stageID string, chunk models.TranscriptChunk) error {
+	match, unlock, err := s.lockMatch(matchID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if match.CurrentStage == nil || match.CurrentStage.ID != stageID {
+		return errors.New("stage is no longer in play")
+	}
+	match = match.Clone()
+	match.CurrentStage.Transcript = append(match.CurrentStage.Transcript, chunk)
+	return s.recordEventLocked(gameID, models.DomainEventTranscriptAdded, models.TranscriptAddedData{
+		MatchID: matchID,
+		StageID: stageID,
+		Chunk:   chunk,
+	}, match)
+}
+
+func stagePlayers(stage *models.MatchStage) []string {
+	return append(stage.CardHolders(), stage.Guessers...)
+}
@@ -3,24 +3,34 @@ package services
 import (
 	"encoding/json"
 	"errors"
+	"log"
+	"sort"
 	"taboo-game/models"
 	"taboo-game/types"
+	"taboo-game/websocket"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// MatchService changes matches under a per-game lock and returns copies of
+// them, so callers never share a match with another goroutine
 type MatchService struct {
-	matches      map[string]*models.MatchDetails
+	locks        *gameLocks
+	repository   types.MatchRepository
+	ledger       *ScoreLedger
 	words        []string
 	gameService  types.GameServiceInterface
 	wsManager    types.WebSocketManagerInterface
+	events       types.EventRecorder
 	turnDuration time.Duration
 }
 
 func NewMatchService(gameService types.GameServiceInterface, wsManager types.WebSocketManagerInterface) *MatchService {
 	return &MatchService{
-		matches:      make(map[string]*models.MatchDetails),
+		locks:        newGameLocks(),
+		repository:   NewMemoryRepository(),
+		ledger:       NewScoreLedger(),
 		words:        []string{},
 		gameService:  gameService,
 		wsManager:    wsManager,
@@ -28,12 +38,111 @@ func NewMatchService(gameService types.GameServiceInterface, wsManager types.Web
 	}
 }
 
+// SetEventRecorder makes the service record match events in the game's log
+func (s *MatchService) SetEventRecorder(events types.EventRecorder) {
+	s.events = events
+}
+
+// SetRepository changes where the current state of matches is kept. It must
+// be called before any match is started or rebuilt.
+func (s *MatchService) SetRepository(repository types.MatchRepository) {
+	s.repository = repository
+}
+
+// recordEvent logs a change to the game that leaves its matches as they are
+func (s *MatchService) recordEvent(gameID string, eventType models.DomainEventType, data interface{}) error {
+	defer s.locks.lock(gameID)()
+	return s.recordEventLocked(gameID, eventType, data)
+}
+
+// recordEventLocked logs a change to the game with the matches it changed.
+// It must be called with the game's lock held.
+func (s *MatchService) recordEventLocked(gameID string, eventType models.DomainEventType, data interface{}, matches ...*models.MatchDetails) error {
+	event, err := NewDomainEvent(gameID, eventType, data)
+	if err != nil {
+		return err
+	}
+	return s.commitLocked(gameID, []models.DomainEvent{event}, matches...)
+}
+
+// commitLocked records an operation's events with the matches they change,
+// all or none. The matches must be changed copies of the stored ones, which
+// they replace only once the events are recorded. It must be called with
+// the game's lock held.
+func (s *MatchService) commitLocked(gameID string, events []models.DomainEvent, matches ...*models.MatchDetails) error {
+	if s.events == nil {
+		return s.saveMatches(matches...)
+	}
+	return s.events.RecordEvents(gameID, events, s.repository, matches...)
+}
+
+// saveMatches writes changed copies of matches to the repository
+func (s *MatchService) saveMatches(matches ...*models.MatchDetails) error {
+	if len(matches) == 0 {
+		return nil
+	}
+	return s.repository.SaveMatches(matches...)
+}
+
+// allMatches returns every match, logging rather than failing when the
+// repository cannot be read
+func (s *MatchService) allMatches() []*models.MatchDetails {
+	matches, err := s.repository.ListMatches()
+	if err != nil {
+		log.Printf("Error listing matches: %v", err)
+	}
+	return matches
+}
+
+// gameMatches returns every match of a game
+func (s *MatchService) gameMatches(gameID string) []*models.MatchDetails {
+	var matches []*models.MatchDetails
+	for _, match := range s.allMatches() {
+		if match.GameID == gameID {
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// lockMatch finds a match, locks its game and reads the match again under
+// the lock. The caller must unlock the game once done with the match.
+func (s *MatchService) lockMatch(matchID string) (*models.MatchDetails, func(), error) {
+	match, err := s.repository.GetMatch(matchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	unlock := s.locks.lock(match.GameID)
+	if match, err = s.repository.GetMatch(matchID); err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	return match, unlock, nil
+}
+
+// lockStageMatch finds the match playing a stage and locks its game
+func (s *MatchService) lockStageMatch(stageID string) (*models.MatchDetails, func(), error) {
+	for _, listed := range s.allMatches() {
+		unlock := s.locks.lock(listed.GameID)
+		match, err := s.repository.GetMatch(listed.ID)
+		if err == nil && match.CurrentStage != nil && match.CurrentStage.ID == stageID {
+			return match, unlock, nil
+		}
+		unlock()
+	}
+	return nil, nil, errors.New("match not found for stage")
+}
+
 func (s *MatchService) GetMatch(gameID, matchID string) (*models.MatchDetails, error) {
-	match, exists := s.matches[matchID]
-	if !exists {
-		return nil, errors.New("match not found")
+	match, unlock, err := s.lockMatch(matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if match.GameID != gameID {
+		return nil, errors.New("match not found for the given game")
 	}
-	return match, nil
+	return match.Clone(), nil
 }
 
 func (s *MatchService) StartMatch(gameID, matchID string, teamAssignments map[string][]string) (*models.MatchDetails, error) {
@@ -42,15 +151,15 @@ func (s *MatchService) StartMatch(gameID, matchID string, teamAssignments map[st
 	if err != nil {
 		return nil, errors.New("game not found")
 	}
+	defer s.locks.lock(gameID)()
 
-	match, exists := s.matches[matchID]
-	if !exists {
-		match = &models.MatchDetails{
-			ID:     matchID,
-			GameID: gameID,
-			Status: models.MatchStatusPending,
-		}
-		s.matches[matchID] = match
+	match := &models.MatchDetails{
+		ID:     matchID,
+		GameID: gameID,
+		Status: models.MatchStatusPending,
+	}
+	if stored, err := s.repository.GetMatch(matchID); err == nil {
+		match = stored.Clone()
 	}
 
 	// Validate team assignments
@@ -78,98 +187,104 @@ func (s *MatchService) StartMatch(gameID, matchID string, teamAssignments map[st
 	match.CurrentWord = s.getNextWord()
 	match.TeamATurn = true
 
-	return match, nil
+	err = s.recordEventLocked(gameID, models.DomainEventMatchStarted, models.MatchStartedData{
+		MatchID:      match.ID,
+		TeamAPlayers: teamAPlayers,
+		TeamBPlayers: teamBPlayers,
+	}, match)
+	if err != nil {
+		return nil, err
+	}
+
+	return match.Clone(), nil
 }
 
-func (s *MatchService) ScorePoint(matchID string, isTeamA bool) (*models.MatchDetails, error) {
-	match, exists := s.matches[matchID]
-	if !exists {
-		return nil, errors.New("match not found")
+// ScorePoint lets the game's host award a point by hand, recorded apart
+// from the points of correct guesses.
+func (s *MatchService) ScorePoint(matchID, hostID string, isTeamA bool) (*models.MatchDetails, error) {
+	match, unlock, err := s.lockMatch(matchID)
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
-	if match.Status != models.MatchStatusInProgress {
-		return nil, errors.New("match is not in progress")
+	game, err := s.gameService.GetGame(match.GameID)
+	if err != nil {
+		return nil, err
 	}
-
-	// Update score
-	if isTeamA {
-		match.TeamAScore++
-	} else {
-		match.TeamBScore++
+	if game.HostID == "" || game.HostID != hostID {
+		return nil, errors.New("only the host can award points")
 	}
-
-	// Broadcast score update
-	scoreUpdateData := models.ScoreUpdateData{
-		TeamAScore:  match.TeamAScore,
-		TeamBScore:  match.TeamBScore,
-		ScoringTeam: map[bool]string{true: "teamA", false: "teamB"}[isTeamA],
+	if err := validateScoring(match); err != nil {
+		return nil, err
 	}
-	dataJSON, _ := json.Marshal(scoreUpdateData)
 
-	scoreUpdate := models.GameEvent{
-		Type:      models.EventTypeScoreUpdate,
-		GameID:    match.GameID,
-		MatchID:   match.ID,
-		Timestamp: time.Now(),
-		Data:      json.RawMessage(dataJSON),
+	scored, err := s.recordScore(match, nil, models.ScoreEntry{
+		TeamID: map[bool]string{true: "teamA", false: "teamB"}[isTeamA],
+		Points: models.PointsCorrectGuess,
+		Reason: models.ScoreReasonHostAward,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	eventJSON, _ := json.Marshal(scoreUpdate)
-	s.wsManager.SendToGame(match.GameID, eventJSON)
-
-	return match, nil
+	return scored.Clone(), nil
 }
 
 func (s *MatchService) ChangeTurn(matchID string) (*models.MatchDetails, error) {
-	match, exists := s.matches[matchID]
-	if !exists {
-		return nil, errors.New("match not found")
+	match, unlock, err := s.lockMatch(matchID)
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
 	// Switch turns
+	match = match.Clone()
 	match.TeamATurn = !match.TeamATurn
 	match.CurrentWord = s.getNextWord()
+	err = s.recordEventLocked(match.GameID, models.DomainEventTurnChanged, models.TurnChangedData{
+		MatchID:   match.ID,
+		TeamATurn: match.TeamATurn,
+	}, match)
+	if err != nil {
+		return nil, err
+	}
 
 	// Broadcast turn change
-	turnChangeData := models.TurnChangeData{
+	turnChange := websocket.NewMessage(websocket.TurnChange, match.GameID, "", websocket.TurnChangePayload{
+		MatchID:    match.ID,
 		ActiveTeam: map[bool]string{true: "teamA", false: "teamB"}[match.TeamATurn],
 		TimeLeft:   int(s.turnDuration.Seconds()),
-	}
-	dataJSON, _ := json.Marshal(turnChangeData)
-
-	turnChange := models.GameEvent{
-		Type:      models.EventTypeTurnChange,
-		GameID:    match.GameID,
-		MatchID:   match.ID,
-		Timestamp: time.Now(),
-		Data:      json.RawMessage(dataJSON),
-	}
-
-	eventJSON, _ := json.Marshal(turnChange)
-	s.wsManager.SendToGame(match.GameID, eventJSON)
+	})
+	s.wsManager.SendToGame(match.GameID, turnChange.Encode())
 
 	// Start turn timer
-	go s.startTurnTimer(match)
+	go s.startTurnTimer(match.ID)
 
-	return match, nil
+	return match.Clone(), nil
 }
 
-func (s *MatchService) startTurnTimer(match *models.MatchDetails) {
+func (s *MatchService) startTurnTimer(matchID string) {
 	timer := time.NewTimer(s.turnDuration)
 	<-timer.C
 
 	// Time's up, change turns
-	s.ChangeTurn(match.ID)
+	s.ChangeTurn(matchID)
 }
 
 func (s *MatchService) EndMatch(gameID, matchID string) (*models.MatchDetails, error) {
-	match, exists := s.matches[matchID]
-	if !exists {
-		return nil, errors.New("match not found")
+	match, unlock, err := s.lockMatch(matchID)
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
+	match = match.Clone()
 	match.Status = models.MatchStatusCompleted
-	return match, nil
+	if err := s.recordEventLocked(match.GameID, models.DomainEventMatchEnded, models.MatchEndedData{MatchID: match.ID}, match); err != nil {
+		return nil, err
+	}
+	return match.Clone(), nil
 }
 
 func (s *MatchService) getNextWord() string {
@@ -177,27 +292,39 @@ func (s *MatchService) getNextWord() string {
 }
 
 func (s *MatchService) CreateStage(gameID, matchID string, details models.MatchStageDetails) (*models.MatchStage, error) {
-	match, exists := s.matches[matchID]
-	if !exists {
-		return nil, errors.New("match not found")
+	match, unlock, err := s.lockMatch(matchID)
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
 	if match.Status != models.MatchStatusPending {
 		return nil, errors.New("match is not in pending state")
 	}
 
+	stageNumber := 1
+	if match.CurrentStage != nil {
+		stageNumber = match.CurrentStage.Number + 1
+	}
+
 	stage := &models.MatchStage{
 		ID:             generateID(),
 		MatchID:        matchID,
+		Number:         stageNumber,
 		ActiveTeamID:   details.ActiveTeamID,
 		SpottingTeamID: details.SpottingTeamID,
 		ClueGivers:     details.ClueGivers,
 		Guessers:       details.Guessers,
 		Spotters:       details.Spotters,
-		Status:         "pending",
+		Status:         string(models.StageStatusActive),
+	}
+	match = match.Clone()
+	match.CurrentStage = stage
+	if err := s.recordEventLocked(match.GameID, models.DomainEventStageStarted, models.StageStartedData{Stage: *stage}, match); err != nil {
+		return nil, err
 	}
 
-	return stage, nil
+	return stage.Clone(), nil
 }
 
 func generateID() string {
@@ -205,10 +332,11 @@ func generateID() string {
 }
 
 func (s *MatchService) SwitchTeam(matchID string, playerID string) (*models.MatchDetails, error) {
-	match, exists := s.matches[matchID]
-	if !exists {
-		return nil, errors.New("match not found")
+	match, unlock, err := s.lockMatch(matchID)
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
 	if match.Status != models.MatchStatusPending {
 		return nil, errors.New("team switches are only allowed before match starts")
@@ -238,6 +366,7 @@ func (s *MatchService) SwitchTeam(matchID string, playerID string) (*models.Matc
 	}
 
 	// Perform the switch
+	match = match.Clone()
 	if isInTeamA {
 		match.TeamAPlayers = removePlayer(match.TeamAPlayers, playerID)
 		match.TeamBPlayers = append(match.TeamBPlayers, playerID)
@@ -246,7 +375,15 @@ func (s *MatchService) SwitchTeam(matchID string, playerID string) (*models.Matc
 		match.TeamAPlayers = append(match.TeamAPlayers, playerID)
 	}
 
-	return match, nil
+	err = s.recordEventLocked(match.GameID, models.DomainEventTeamSwitched, models.TeamSwitchedData{
+		MatchID:  match.ID,
+		PlayerID: playerID,
+	}, match)
+	if err != nil {
+		return nil, err
+	}
+
+	return match.Clone(), nil
 }
 
 // Helper functions
@@ -277,13 +414,34 @@ func removePlayer(players []string, playerID string) []string {
 }
 
 func (s *MatchService) GetCurrentMatch(stageID string) (*models.MatchDetails, error) {
-	// Find match containing this stage
-	for _, match := range s.matches {
-		if match.CurrentStage != nil && match.CurrentStage.ID == stageID {
+	match, unlock, err := s.lockStageMatch(stageID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return match.Clone(), nil
+}
+
+// GetActiveMatch returns the match of a game that currently has a stage in play
+func (s *MatchService) GetActiveMatch(gameID string) (*models.MatchDetails, error) {
+	defer s.locks.lock(gameID)()
+
+	match, err := s.activeMatchLocked(gameID)
+	if err != nil {
+		return nil, err
+	}
+	return match.Clone(), nil
+}
+
+// activeMatchLocked returns the stored active match of a game. It must be
+// called with the game's lock held.
+func (s *MatchService) activeMatchLocked(gameID string) (*models.MatchDetails, error) {
+	for _, match := range s.gameMatches(gameID) {
+		if stageActive(match) && match.Status != models.MatchStatusCompleted {
 			return match, nil
 		}
 	}
-	return nil, errors.New("match not found for stage")
+	return nil, errors.New("no active match for game")
 }
 
 func (s *MatchService) getOpposingTeamID(teamID string) string {
@@ -303,97 +461,394 @@ func (s *MatchService) getSmallerTeam(match *models.MatchDetails) string {
 }
 
 func (s *MatchService) ProcessGuessAttempt(gameID, matchID string, attempt *models.GuessAttempt) error {
-	match, exists := s.matches[matchID]
-	if !exists || match.GameID != gameID {
+	return s.processGuessAttempt(gameID, matchID, attempt)
+}
+
+// processGuessAttempt records a guess and the points it scores together with
+// any further events of the same operation
+func (s *MatchService) processGuessAttempt(gameID, matchID string, attempt *models.GuessAttempt, events ...models.DomainEvent) error {
+	match, unlock, err := s.lockMatch(matchID)
+	if err != nil {
+		return errors.New("match not found for the given game")
+	}
+	defer unlock()
+	if match.GameID != gameID {
 		return errors.New("match not found for the given game")
 	}
 
-	if match.CurrentStage == nil {
-		return errors.New("no active stage")
+	if err := validateScoring(match); err != nil {
+		return err
 	}
 
-	// Update both match and stage scores
-	if attempt.Correct {
-		if attempt.TeamID == "teamA" {
-			match.TeamAScore += models.PointsCorrectGuess
-			match.CurrentStage.TeamAScore += models.PointsCorrectGuess
-		} else {
-			match.TeamBScore += models.PointsCorrectGuess
-			match.CurrentStage.TeamBScore += models.PointsCorrectGuess
+	if attempt.Violation {
+		if attempt.ViolationType == "" {
+			attempt.ViolationType = models.ViolationTypeOther
+		}
+		if !attempt.ViolationType.IsValid() {
+			return errors.New("unknown violation type")
 		}
 	}
 
+	guess, err := NewDomainEvent(gameID, models.DomainEventGuessRecorded, models.GuessRecordedData{
+		MatchID: matchID,
+		Attempt: *attempt,
+	})
+	if err != nil {
+		return err
+	}
+
+	var entries []models.ScoreEntry
+	if attempt.Correct {
+		entries = append(entries, models.ScoreEntry{
+			TeamID: attempt.TeamID,
+			Points: models.PointsCorrectGuess,
+			Reason: models.ScoreReasonCorrectGuess,
+			CardID: attempt.CardID,
+		})
+	}
+
 	if attempt.Violation {
-		opposingTeamID := s.getOpposingTeamID(attempt.TeamID)
-		if opposingTeamID == "teamA" {
-			match.TeamAScore += models.PointsViolationCatch
-			match.CurrentStage.TeamAScore += models.PointsViolationCatch
-		} else {
-			match.TeamBScore += models.PointsViolationCatch
-			match.CurrentStage.TeamBScore += models.PointsViolationCatch
+		rule := s.getGameRules(gameID).ViolationRule(attempt.ViolationType)
+		// The catch is always recorded so violation statistics stay complete
+		entries = append(entries, models.ScoreEntry{
+			TeamID:        s.getOpposingTeamID(attempt.TeamID),
+			Points:        rule.SpotterReward,
+			Reason:        models.ScoreReasonViolationCatch,
+			ViolationType: attempt.ViolationType,
+			CardID:        attempt.CardID,
+		})
+		if rule.OffenderPenalty > 0 {
+			entries = append(entries, models.ScoreEntry{
+				TeamID:        attempt.TeamID,
+				Points:        -rule.OffenderPenalty,
+				Reason:        models.ScoreReasonViolationPenalty,
+				ViolationType: attempt.ViolationType,
+				CardID:        attempt.CardID,
+			})
 		}
 	}
 
-	// Emit score update event
-	dataJSON, _ := json.Marshal(struct {
-		TeamAScore int `json:"teamAScore"`
-		TeamBScore int `json:"teamBScore"`
-	}{
-		TeamAScore: match.TeamAScore,
-		TeamBScore: match.TeamBScore,
+	_, err = s.recordScore(match, append([]models.DomainEvent{guess}, events...), entries...)
+	return err
+}
+
+// validateScoring checks that a match can accept points.
+// Every point belongs to a stage, so a stage must be in play.
+func validateScoring(match *models.MatchDetails) error {
+	if match.Status == models.MatchStatusCompleted {
+		return errors.New("match is already completed")
+	}
+	if !stageActive(match) {
+		return errors.New("no active stage")
+	}
+	return nil
+}
+
+// stageActive reports whether a match has a stage in play
+func stageActive(match *models.MatchDetails) bool {
+	return match.CurrentStage != nil && match.CurrentStage.Status == string(models.StageStatusActive)
+}
+
+// recordScore records entries in the ledger after the events of the
+// operation that scored them, re-derives the match and stage totals and
+// broadcasts a single score update. It must be called with the game's lock
+// held, and returns the match as saved.
+func (s *MatchService) recordScore(match *models.MatchDetails, events []models.DomainEvent, entries ...models.ScoreEntry) (*models.MatchDetails, error) {
+	if len(entries) == 0 {
+		return match, s.commitLocked(match.GameID, events)
+	}
+
+	for i := range entries {
+		entries[i].GameID = match.GameID
+		entries[i].MatchID = match.ID
+		entries[i].StageID = match.CurrentStage.ID
+	}
+	recorded := s.ledger.stamp(match.GameID, entries)
+	for _, entry := range recorded {
+		event, err := NewDomainEvent(match.GameID, models.DomainEventScoreRecorded, models.ScoreRecordedData{Entry: entry})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	scored := match.Clone()
+	refreshScores(scored, s.ledger.with(match.GameID, recorded))
+	if err := s.commitLocked(match.GameID, events, scored); err != nil {
+		return nil, err
+	}
+	s.ledger.add(recorded...)
+
+	scoreUpdate := websocket.NewMessage(websocket.ScoreUpdate, scored.GameID, "", websocket.ScoreUpdatePayload{
+		MatchID:         scored.ID,
+		TeamAScore:      scored.TeamAScore,
+		TeamBScore:      scored.TeamBScore,
+		ScoringTeam:     recorded[0].TeamID,
+		StageID:         scored.CurrentStage.ID,
+		StageTeamAScore: scored.CurrentStage.TeamAScore,
+		StageTeamBScore: scored.CurrentStage.TeamBScore,
+		Entries:         recorded,
 	})
+	s.wsManager.SendToGame(scored.GameID, scoreUpdate.Encode())
+	return scored, nil
+}
 
-	scoreUpdate := models.GameEvent{
-		Type:    "SCORE_UPDATE",
-		GameID:  match.GameID,
-		MatchID: match.ID,
-		Data:    json.RawMessage(dataJSON),
+// refreshScores derives the match and current stage totals from a ledger
+func refreshScores(match *models.MatchDetails, ledger *ScoreLedger) {
+	matchTotals := ledger.MatchTotals(match.GameID, match.ID)
+	match.TeamAScore = matchTotals.TeamAScore
+	match.TeamBScore = matchTotals.TeamBScore
+	match.ViolationCounts = ledger.MatchViolationCounts(match.GameID, match.ID)
+
+	if match.CurrentStage != nil {
+		stageTotals := ledger.StageTotals(match.GameID, match.CurrentStage.ID)
+		match.CurrentStage.TeamAScore = stageTotals.TeamAScore
+		match.CurrentStage.TeamBScore = stageTotals.TeamBScore
+		match.CurrentStage.ViolationCounts = ledger.StageViolationCounts(match.GameID, match.CurrentStage.ID)
 	}
+}
 
-	eventJSON, _ := json.Marshal(scoreUpdate)
-	s.wsManager.SendToGame(match.GameID, eventJSON)
+func (s *MatchService) getGameRules(gameID string) models.GameRules {
+	game, err := s.gameService.GetGame(gameID)
+	if err != nil {
+		return models.DefaultGameRules()
+	}
+	return game.Rules
+}
 
-	return nil
+// GetScoreLedger returns every score entry recorded for a game in order
+func (s *MatchService) GetScoreLedger(gameID string) []models.ScoreEntry {
+	return s.ledger.Entries(gameID)
+}
+
+// GetGameStats aggregates scores and violation counts over every match of a game
+func (s *MatchService) GetGameStats(gameID string) (*models.GameStats, error) {
+	if _, err := s.gameService.GetGame(gameID); err != nil {
+		return nil, errors.New("game not found")
+	}
+
+	totals := s.ledger.TeamTotals(gameID)
+	stats := &models.GameStats{
+		GameID:          gameID,
+		TeamAScore:      totals.TeamAScore,
+		TeamBScore:      totals.TeamBScore,
+		ViolationCounts: s.ledger.GameViolationCounts(gameID),
+		Matches:         make([]models.MatchStats, 0),
+	}
+	for _, match := range s.gameMatches(gameID) {
+		matchTotals := s.ledger.MatchTotals(gameID, match.ID)
+		stats.Matches = append(stats.Matches, models.MatchStats{
+			MatchID:         match.ID,
+			TeamAScore:      matchTotals.TeamAScore,
+			TeamBScore:      matchTotals.TeamBScore,
+			ViolationCounts: s.ledger.MatchViolationCounts(gameID, match.ID),
+		})
+	}
+
+	sort.Slice(stats.Matches, func(i, j int) bool {
+		return stats.Matches[i].MatchID < stats.Matches[j].MatchID
+	})
+	return stats, nil
 }
 
 func (s *MatchService) FinalizeStageScores(stageID string) error {
-	match, err := s.GetCurrentMatch(stageID)
+	match, unlock, err := s.lockStageMatch(stageID)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
 	// Apply team size balance adjustment at the end of each stage
 	smallerTeam := s.getSmallerTeam(match)
-	if smallerTeam == "teamA" && len(match.TeamAPlayers) == 3 {
-		match.TeamAScore += models.BasePointsTeamOfThree
-		match.CurrentStage.TeamAScore += models.BasePointsTeamOfThree
-	} else if smallerTeam == "teamB" && len(match.TeamBPlayers) == 3 {
-		match.TeamBScore += models.BasePointsTeamOfThree
-		match.CurrentStage.TeamBScore += models.BasePointsTeamOfThree
+	if (smallerTeam == "teamA" && len(match.TeamAPlayers) == 3) ||
+		(smallerTeam == "teamB" && len(match.TeamBPlayers) == 3) {
+		_, err := s.recordScore(match, nil, models.ScoreEntry{
+			TeamID: smallerTeam,
+			Points: models.BasePointsTeamOfThree,
+			Reason: models.ScoreReasonTeamSizeBonus,
+		})
+		return err
 	}
 
 	return nil
 }
 
 // Add this method to store matches for testing
-func (s *MatchService) StoreMatch(match *models.MatchDetails) {
-	s.matches[match.ID] = match
+func (s *MatchService) StoreMatch(match *models.MatchDetails) error {
+	defer s.locks.lock(match.GameID)()
+	return s.saveMatches(match.Clone())
 }
 
+// NextStage closes the game's stage that has ended, so that its match can
+// go on to the next stage
 func (s *MatchService) NextStage(gameID string) error {
-	match, err := s.GetCurrentMatch(gameID)
+	defer s.locks.lock(gameID)()
+
+	match, err := s.activeMatchLocked(gameID)
 	if err != nil {
 		return err
 	}
-	match.CurrentStage.Status = "in_progress"
-	return nil
+	match = match.Clone()
+	match.CurrentStage.Status = string(models.StageStatusCompleted)
+	return s.recordEventLocked(match.GameID, models.DomainEventStageEnded, models.StageEndedData{
+		MatchID: match.ID,
+		StageID: match.CurrentStage.ID,
+	}, match)
 }
 
+// EndCurrentMatch completes the game's match whose last stage has ended
 func (s *MatchService) EndCurrentMatch(gameID string) error {
-	match, err := s.GetCurrentMatch(gameID)
+	defer s.locks.lock(gameID)()
+
+	match, err := s.activeMatchLocked(gameID)
 	if err != nil {
 		return err
 	}
+	match = match.Clone()
 	match.Status = models.MatchStatusCompleted
+	return s.recordEventLocked(match.GameID, models.DomainEventMatchEnded, models.MatchEndedData{MatchID: match.ID}, match)
+}
+
+// matchesOf returns copies of every match of a game
+func (s *MatchService) matchesOf(gameID string) []*models.MatchDetails {
+	defer s.locks.lock(gameID)()
+
+	matches := s.gameMatches(gameID)
+	for i, match := range matches {
+		matches[i] = match.Clone()
+	}
+	return matches
+}
+
+// restoreMatches replaces a game's matches and scoring ledger with those of
+// a recovery snapshot
+func (s *MatchService) restoreMatches(gameID string, matches []*models.MatchDetails, scores []models.ScoreEntry) error {
+	defer s.locks.lock(gameID)()
+
+	if err := s.saveMatches(matches...); err != nil {
+		return err
+	}
+	s.ledger.restore(gameID, scores)
+	return nil
+}
+
+// Rebuild restores matches and the scoring ledger from the game event logs
+func (s *MatchService) Rebuild(store types.EventStoreInterface) error {
+	gameIDs, err := store.GameIDs()
+	if err != nil {
+		return err
+	}
+
+	matches := make(map[string]*models.MatchDetails)
+	for _, gameID := range gameIDs {
+		events, err := store.Load(gameID)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := s.applyMatchEvent(matches, event); err != nil {
+				return err
+			}
+		}
+	}
+
+	rebuilt := make([]*models.MatchDetails, 0, len(matches))
+	for _, match := range matches {
+		refreshScores(match, s.ledger)
+		rebuilt = append(rebuilt, match)
+	}
+	return s.repository.SaveMatches(rebuilt...)
+}
+
+// applyMatchEvent folds a logged event into the matches being rebuilt
+// without re-recording it
+func (s *MatchService) applyMatchEvent(matches map[string]*models.MatchDetails, event models.DomainEvent) error {
+	switch event.Type {
+	case models.DomainEventMatchStarted:
+		var data models.MatchStartedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		match, exists := matches[data.MatchID]
+		if !exists {
+			match = &models.MatchDetails{ID: data.MatchID, GameID: event.GameID}
+			matches[data.MatchID] = match
+		}
+		match.Status = models.MatchStatusPending
+		match.TeamAPlayers = data.TeamAPlayers
+		match.TeamBPlayers = data.TeamBPlayers
+		match.CurrentWord = s.getNextWord()
+		match.TeamATurn = true
+
+	case models.DomainEventTeamSwitched:
+		var data models.TeamSwitchedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		if match, exists := matches[data.MatchID]; exists {
+			if containsPlayer(match.TeamAPlayers, data.PlayerID) {
+				match.TeamAPlayers = removePlayer(match.TeamAPlayers, data.PlayerID)
+				match.TeamBPlayers = append(match.TeamBPlayers, data.PlayerID)
+			} else {
+				match.TeamBPlayers = removePlayer(match.TeamBPlayers, data.PlayerID)
+				match.TeamAPlayers = append(match.TeamAPlayers, data.PlayerID)
+			}
+		}
+
+	case models.DomainEventStageStarted:
+		var data models.StageStartedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		if match, exists := matches[data.Stage.MatchID]; exists {
+			stage := data.Stage
+			stage.Status = string(models.StageStatusActive)
+			match.CurrentStage = &stage
+		}
+
+	case models.DomainEventStageEnded:
+		var data models.StageEndedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		if match, exists := matches[data.MatchID]; exists && match.CurrentStage != nil && match.CurrentStage.ID == data.StageID {
+			match.CurrentStage.Status = string(models.StageStatusCompleted)
+		}
+
+	case models.DomainEventTurnChanged:
+		var data models.TurnChangedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		if match, exists := matches[data.MatchID]; exists {
+			match.TeamATurn = data.TeamATurn
+			match.CurrentWord = s.getNextWord()
+		}
+
+	case models.DomainEventScoreRecorded:
+		var data models.ScoreRecordedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		s.ledger.Append(data.Entry)
+
+	case models.DomainEventTranscriptAdded:
+		var data models.TranscriptAddedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		if match, exists := matches[data.MatchID]; exists && match.CurrentStage != nil && match.CurrentStage.ID == data.StageID {
+			match.CurrentStage.Transcript = append(match.CurrentStage.Transcript, data.Chunk)
+		}
+
+	case models.DomainEventMatchEnded:
+		var data models.MatchEndedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		if match, exists := matches[data.MatchID]; exists {
+			match.Status = models.MatchStatusCompleted
+		}
+	}
 	return nil
 }
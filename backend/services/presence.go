@@ -0,0 +1,55 @@
+package services
+
+import (
+	"errors"
+	"taboo-game/models"
+	"taboo-game/types"
+	"taboo-game/websocket"
+)
+
+// PresenceChanged tells the other players of a game that a player joined,
+// left, reconnected or went idle
+func (s *GameEventsService) PresenceChanged(event models.PresenceEvent, presence models.PlayerPresence) {
+	if event == models.PresenceLeft {
+		s.forgetChatSends(presence.GameID, presence.PlayerID)
+	}
+
+	msg := websocket.NewMessage(websocket.PresenceUpdate, presence.GameID, presence.PlayerID, websocket.PresenceUpdatePayload{
+		Event:    event,
+		Presence: presence,
+	})
+	s.wsManager.SendToGameExcept(presence.GameID, []string{presence.PlayerID}, msg.Encode())
+}
+
+// SpectatorsChanged tells the game's host how many spectators are watching
+func (s *GameEventsService) SpectatorsChanged(gameID string, count int) {
+	game, err := s.matchService.gameService.GetGame(gameID)
+	if err != nil || game.HostID == "" {
+		return
+	}
+
+	msg := websocket.NewMessage(websocket.SpectatorCount, gameID, game.HostID, websocket.SpectatorCountPayload{Count: count})
+	s.wsManager.SendToPlayer(gameID, game.HostID, msg.Encode())
+}
+
+// TeamPresent reports whether every player of a team ("teamA" or "teamB")
+// in the game's current match is connected
+func (s *MatchService) TeamPresent(gameID, teamID string) (bool, error) {
+	tracker, ok := s.wsManager.(types.PresenceTracker)
+	if !ok {
+		return false, errors.New("presence is not tracked")
+	}
+
+	match, err := s.currentGameMatch(gameID)
+	if err != nil {
+		return false, err
+	}
+
+	switch teamID {
+	case "teamA":
+		return tracker.AllPresent(gameID, match.TeamAPlayers), nil
+	case "teamB":
+		return tracker.AllPresent(gameID, match.TeamBPlayers), nil
+	}
+	return false, errors.New("unknown team")
+}
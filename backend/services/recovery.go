@@ -0,0 +1,292 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"taboo-game/models"
+	"taboo-game/types"
+	"time"
+)
+
+const recoveryFileName = "snapshot.json"
+
+// RecoveryService periodically saves every active game, with its matches,
+// scores, running stage timer and the state of the deck, to a snapshot
+// file, and restores them from it when the server starts again. The file is
+// replaced atomically, so a crash while saving leaves the previous one.
+type RecoveryService struct {
+	dir         string
+	gameService *GameService
+	gameEvents  *GameEventsService
+	stop        chan struct{}
+	stopOnce    sync.Once
+}
+
+func NewRecoveryService(dir string, gameService *GameService, gameEvents *GameEventsService) (*RecoveryService, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating snapshot directory: %v", err)
+	}
+	return &RecoveryService{
+		dir:         dir,
+		gameService: gameService,
+		gameEvents:  gameEvents,
+		stop:        make(chan struct{}),
+	}, nil
+}
+
+// Path returns the snapshot file
+func (s *RecoveryService) Path() string {
+	return filepath.Join(s.dir, recoveryFileName)
+}
+
+// Take captures the current state of every game that has not ended
+func (s *RecoveryService) Take() (*models.RecoverySnapshot, error) {
+	games, logVersions, err := s.gameService.activeGames()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &models.RecoverySnapshot{
+		TakenAt:     time.Now(),
+		Games:       games,
+		LogVersions: logVersions,
+		Matches:     []*models.MatchDetails{},
+		Scores:      []models.ScoreEntry{},
+		UsedCards:   s.gameEvents.wordService.usedCardIDs(),
+	}
+	active := make(map[string]bool, len(games))
+	for _, game := range games {
+		active[game.ID] = true
+		snapshot.Matches = append(snapshot.Matches, s.gameEvents.matchService.matchesOf(game.ID)...)
+		snapshot.Scores = append(snapshot.Scores, s.gameEvents.matchService.GetScoreLedger(game.ID)...)
+	}
+	snapshot.Stages = s.gameEvents.stageDeadlines(snapshot.TakenAt, active)
+	for i, stage := range snapshot.Stages {
+		if match, err := s.gameEvents.matchService.currentGameMatch(stage.GameID); err == nil && match.CurrentStage != nil {
+			snapshot.Stages[i].StageID = match.CurrentStage.ID
+		}
+	}
+	return snapshot, nil
+}
+
+// Save takes a snapshot and writes it to a temporary file, which then
+// replaces the previous snapshot
+func (s *RecoveryService) Save() error {
+	snapshot, err := s.Take()
+	if err != nil {
+		return fmt.Errorf("error taking snapshot: %v", err)
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("error encoding snapshot: %v", err)
+	}
+
+	file, err := os.CreateTemp(s.dir, recoveryFileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating snapshot file: %v", err)
+	}
+	defer os.Remove(file.Name()) // Fails harmlessly once renamed
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing snapshot file: %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("error syncing snapshot file: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error closing snapshot file: %v", err)
+	}
+	if err := os.Rename(file.Name(), s.Path()); err != nil {
+		return fmt.Errorf("error replacing snapshot file: %v", err)
+	}
+	return nil
+}
+
+// Load reads the last saved snapshot. It returns nil when none was saved.
+func (s *RecoveryService) Load() (*models.RecoverySnapshot, error) {
+	data, err := os.ReadFile(s.Path())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading snapshot file: %v", err)
+	}
+
+	var snapshot models.RecoverySnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("error decoding snapshot file: %v", err)
+	}
+	return &snapshot, nil
+}
+
+// Restore carries on the games of the last saved snapshot and returns the
+// IDs of those it took from it. A game whose event log has caught up with
+// the snapshot keeps what was rebuilt from the log, so Restore must run
+// after the games and matches are rebuilt and before clients can connect.
+// Stage timers resume from when they were due to end, unless the log has
+// moved past the stage; stages that ran out meanwhile end at once.
+func (s *RecoveryService) Restore() ([]string, error) {
+	snapshot, err := s.Load()
+	if err != nil || snapshot == nil {
+		return nil, err
+	}
+
+	matches := make(map[string][]*models.MatchDetails)
+	for _, match := range snapshot.Matches {
+		matches[match.GameID] = append(matches[match.GameID], match)
+	}
+	scores := make(map[string][]models.ScoreEntry)
+	for _, entry := range snapshot.Scores {
+		scores[entry.GameID] = append(scores[entry.GameID], entry)
+	}
+
+	s.gameEvents.wordService.markCardsUsed(snapshot.UsedCards)
+
+	restored := make([]string, 0, len(snapshot.Games))
+	kept := make(map[string]bool, len(snapshot.Games))
+	for _, game := range snapshot.Games {
+		ok, err := s.gameService.restoreGame(game, snapshot.LogVersions[game.ID])
+		if err != nil {
+			return restored, fmt.Errorf("error restoring game %s: %v", game.ID, err)
+		}
+		if !ok {
+			continue
+		}
+		if err := s.gameEvents.matchService.restoreMatches(game.ID, matches[game.ID], scores[game.ID]); err != nil {
+			return restored, fmt.Errorf("error restoring matches of game %s: %v", game.ID, err)
+		}
+		kept[game.ID] = true
+		restored = append(restored, game.ID)
+	}
+
+	for _, stage := range snapshot.Stages {
+		if !kept[stage.GameID] && !s.stageStillRunning(stage) {
+			continue
+		}
+		// The log has the card last drawn even if it was drawn after the
+		// snapshot was taken
+		card := stage.Card
+		if events, err := s.gameService.Events(stage.GameID); err == nil {
+			if drawn := lastDrawnCard(events); drawn != nil {
+				card = drawn
+			}
+		}
+		s.gameEvents.resumeStage(stage.GameID, stage.EndTime, card)
+	}
+
+	restoredAt := time.Now()
+	for _, gameID := range restored {
+		s.gameEvents.markRestored(gameID, restoredAt)
+	}
+	return restored, nil
+}
+
+// stageStillRunning reports whether the match rebuilt from a game's log is
+// still in the stage a snapshot's timer belongs to. Timers are not logged,
+// so without it the stage would never end.
+func (s *RecoveryService) stageStillRunning(stage models.StageDeadline) bool {
+	match, err := s.gameEvents.matchService.currentGameMatch(stage.GameID)
+	if err != nil || match.CurrentStage == nil {
+		return false
+	}
+	return match.CurrentStage.ID == stage.StageID && match.CurrentStage.Status != string(models.StageStatusCompleted)
+}
+
+// Run saves a snapshot at every interval until Stop is called
+func (s *RecoveryService) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Save(); err != nil {
+				log.Printf("Error saving recovery snapshot: %v", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *RecoveryService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+// lastDrawnCard returns the card most recently drawn in a game's log
+func lastDrawnCard(events []models.DomainEvent) *models.WordCard {
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Type != models.DomainEventCardDrawn {
+			continue
+		}
+		var data models.CardDrawnData
+		if err := json.Unmarshal(events[i].Data, &data); err != nil {
+			return nil
+		}
+		return &data.Card
+	}
+	return nil
+}
+
+// stageDeadlines returns when the running stages of the given games end.
+// Timers that have already fired are left out.
+func (s *GameEventsService) stageDeadlines(now time.Time, gameIDs map[string]bool) []models.StageDeadline {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deadlines := make([]models.StageDeadline, 0, len(s.activeStages))
+	for gameID, stage := range s.activeStages {
+		if gameIDs[gameID] && stage.endTime.After(now) {
+			deadlines = append(deadlines, models.StageDeadline{GameID: gameID, EndTime: stage.endTime, Card: stage.card})
+		}
+	}
+	sort.Slice(deadlines, func(i, j int) bool {
+		return deadlines[i].GameID < deadlines[j].GameID
+	})
+	return deadlines
+}
+
+// resumeStage restarts a stage timer that was running before a restart
+func (s *GameEventsService) resumeStage(gameID string, endTime time.Time, card *models.WordCard) {
+	remaining := time.Until(endTime)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if previous, exists := s.activeStages[gameID]; exists {
+		close(previous.done)
+	}
+	timer := &StageTimer{
+		timer:   time.NewTimer(remaining),
+		ticker:  time.NewTicker(time.Second),
+		done:    make(chan bool),
+		endTime: endTime,
+		card:    card,
+	}
+	s.activeStages[gameID] = timer
+	go s.runStageTimer(gameID, timer)
+}
+
+// markRestored notes that a game was restored after a restart, so that the
+// snapshots its players are sent say so
+func (s *GameEventsService) markRestored(gameID string, restoredAt time.Time) {
+	s.mu.Lock()
+	s.restoredAt[gameID] = restoredAt
+	s.mu.Unlock()
+
+	if listener, ok := s.wsManager.(types.RestoreListener); ok {
+		listener.GameRestored(gameID)
+	}
+}
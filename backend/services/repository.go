@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"taboo-game/models"
+)
+
+var (
+	errGameNotFound  = errors.New("game not found")
+	errMatchNotFound = errors.New("match not found")
+)
+
+// MemoryRepository keeps games and matches in process memory. The services
+// change copies of the stored values and save them once the change is
+// recorded, so the stored values are never changed in place.
+type MemoryRepository struct {
+	mu      sync.RWMutex
+	games   map[string]*models.Game
+	matches map[string]*models.MatchDetails
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		games:   make(map[string]*models.Game),
+		matches: make(map[string]*models.MatchDetails),
+	}
+}
+
+func (r *MemoryRepository) GetGame(gameID string) (*models.Game, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	game, exists := r.games[gameID]
+	if !exists {
+		return nil, errGameNotFound
+	}
+	return game, nil
+}
+
+// ListGames returns every game ordered by ID
+func (r *MemoryRepository) ListGames() ([]*models.Game, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	games := make([]*models.Game, 0, len(r.games))
+	for _, game := range r.games {
+		games = append(games, game)
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].ID < games[j].ID })
+	return games, nil
+}
+
+func (r *MemoryRepository) SaveGames(games ...*models.Game) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, game := range games {
+		r.games[game.ID] = game
+	}
+	return nil
+}
+
+func (r *MemoryRepository) GetMatch(matchID string) (*models.MatchDetails, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	match, exists := r.matches[matchID]
+	if !exists {
+		return nil, errMatchNotFound
+	}
+	return match, nil
+}
+
+// ListMatches returns every match ordered by ID
+func (r *MemoryRepository) ListMatches() ([]*models.MatchDetails, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matches := make([]*models.MatchDetails, 0, len(r.matches))
+	for _, match := range r.matches {
+		matches = append(matches, match)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+	return matches, nil
+}
+
+func (r *MemoryRepository) SaveMatches(matches ...*models.MatchDetails) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, match := range matches {
+		r.matches[match.ID] = match
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"sync"
+	"taboo-game/models"
+	"time"
+)
+
+// ScoreLedger is an append-only record of every point scored in each game.
+// Match, stage and team totals are always derived from it.
+type ScoreLedger struct {
+	mu      sync.RWMutex
+	entries map[string][]models.ScoreEntry
+}
+
+func NewScoreLedger() *ScoreLedger {
+	return &ScoreLedger{
+		entries: make(map[string][]models.ScoreEntry),
+	}
+}
+
+// Append stamps the entry with the next sequence number of its game and stores it
+func (l *ScoreLedger) Append(entry models.ScoreEntry) models.ScoreEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry.Seq = len(l.entries[entry.GameID]) + 1
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	l.entries[entry.GameID] = append(l.entries[entry.GameID], entry)
+	return entry
+}
+
+// stamp numbers entries of a game after its last one without storing them,
+// so that they can be recorded before they are added. The game's entries
+// must not change in between.
+func (l *ScoreLedger) stamp(gameID string, entries []models.ScoreEntry) []models.ScoreEntry {
+	l.mu.RLock()
+	next := len(l.entries[gameID]) + 1
+	l.mu.RUnlock()
+
+	stamped := make([]models.ScoreEntry, 0, len(entries))
+	for i, entry := range entries {
+		entry.Seq = next + i
+		if entry.Timestamp.IsZero() {
+			entry.Timestamp = time.Now()
+		}
+		stamped = append(stamped, entry)
+	}
+	return stamped
+}
+
+// add stores stamped entries
+func (l *ScoreLedger) add(entries ...models.ScoreEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, entry := range entries {
+		l.entries[entry.GameID] = append(l.entries[entry.GameID], entry)
+	}
+}
+
+// with returns a ledger of a game's entries followed by stamped entries not
+// yet added, to derive totals from before they are recorded
+func (l *ScoreLedger) with(gameID string, entries []models.ScoreEntry) *ScoreLedger {
+	view := NewScoreLedger()
+	view.entries[gameID] = append(l.Entries(gameID), entries...)
+	return view
+}
+
+// restore replaces a game's ledger with entries recorded earlier, keeping
+// their sequence numbers
+func (l *ScoreLedger) restore(gameID string, entries []models.ScoreEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries[gameID] = append([]models.ScoreEntry{}, entries...)
+}
+
+// Entries returns a copy of a game's ledger in order
+func (l *ScoreLedger) Entries(gameID string) []models.ScoreEntry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return append([]models.ScoreEntry{}, l.entries[gameID]...)
+}
+
+// MatchTotals sums every entry recorded for a match
+func (l *ScoreLedger) MatchTotals(gameID, matchID string) models.ScoreTotals {
+	return l.sum(gameID, func(entry models.ScoreEntry) bool {
+		return entry.MatchID == matchID
+	})
+}
+
+// StageTotals sums every entry recorded for a stage
+func (l *ScoreLedger) StageTotals(gameID, stageID string) models.ScoreTotals {
+	return l.sum(gameID, func(entry models.ScoreEntry) bool {
+		return entry.StageID == stageID
+	})
+}
+
+// TeamTotals sums every entry recorded in a game
+func (l *ScoreLedger) TeamTotals(gameID string) models.ScoreTotals {
+	return l.sum(gameID, func(entry models.ScoreEntry) bool {
+		return true
+	})
+}
+
+// MatchViolationCounts counts caught violations per category in a match
+func (l *ScoreLedger) MatchViolationCounts(gameID, matchID string) map[models.ViolationType]int {
+	return l.countViolations(gameID, func(entry models.ScoreEntry) bool {
+		return entry.MatchID == matchID
+	})
+}
+
+// StageViolationCounts counts caught violations per category in a stage
+func (l *ScoreLedger) StageViolationCounts(gameID, stageID string) map[models.ViolationType]int {
+	return l.countViolations(gameID, func(entry models.ScoreEntry) bool {
+		return entry.StageID == stageID
+	})
+}
+
+// GameViolationCounts counts caught violations per category in a game
+func (l *ScoreLedger) GameViolationCounts(gameID string) map[models.ViolationType]int {
+	return l.countViolations(gameID, func(entry models.ScoreEntry) bool {
+		return true
+	})
+}
+
+func (l *ScoreLedger) countViolations(gameID string, include func(models.ScoreEntry) bool) map[models.ViolationType]int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	counts := make(map[models.ViolationType]int)
+	for _, entry := range l.entries[gameID] {
+		if entry.Reason == models.ScoreReasonViolationCatch && include(entry) {
+			counts[entry.ViolationType]++
+		}
+	}
+	return counts
+}
+
+func (l *ScoreLedger) sum(gameID string, include func(models.ScoreEntry) bool) models.ScoreTotals {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var totals models.ScoreTotals
+	for _, entry := range l.entries[gameID] {
+		if include(entry) {
+			totals.Add(entry)
+		}
+	}
+	return totals
+}
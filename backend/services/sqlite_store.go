@@ -0,0 +1,303 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"taboo-game/models"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteMigrations are applied in order, each in its own transaction. The
+// number of applied migrations is kept in the schema_migrations table, so
+// new migrations must only ever be appended.
+var sqliteMigrations = [][]string{
+	{
+		`CREATE TABLE games (
+			id         TEXT PRIMARY KEY,
+			status     TEXT NOT NULL,
+			data       TEXT NOT NULL,
+			updated_at INTEGER NOT NULL
+		)`,
+		`CREATE TABLE matches (
+			id         TEXT PRIMARY KEY,
+			game_id    TEXT NOT NULL,
+			status     TEXT NOT NULL,
+			data       TEXT NOT NULL,
+			updated_at INTEGER NOT NULL
+		)`,
+		`CREATE INDEX matches_game_id ON matches (game_id)`,
+	},
+	{
+		`CREATE TABLE events (
+			game_id TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			type    TEXT NOT NULL,
+			data    TEXT NOT NULL,
+			PRIMARY KEY (game_id, version)
+		)`,
+	},
+}
+
+// SQLiteStore keeps games, matches and the event log in an embedded SQLite
+// database. Games and matches are loaded into memory when the store opens;
+// reads are served from there and every save is written through in a
+// transaction.
+type SQLiteStore struct {
+	db    *sql.DB
+	cache *MemoryRepository
+}
+
+// OpenSQLiteStore opens or creates the database at path and brings its
+// schema up to date
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("error creating database directory: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("error opening database: %v", err)
+	}
+	// SQLite allows a single writer; one connection keeps transactions
+	// from failing on a locked database
+	db.SetMaxOpenConns(1)
+
+	store := &SQLiteStore{db: db, cache: NewMemoryRepository()}
+	if err := store.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := store.load(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// SchemaVersion returns the number of migrations applied to the database
+func (s *SQLiteStore) SchemaVersion() (int, error) {
+	var version int
+	err := s.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("error reading schema version: %v", err)
+	}
+	return version, nil
+}
+
+func (s *SQLiteStore) migrate() error {
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("error creating migrations table: %v", err)
+	}
+
+	applied, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if applied > len(sqliteMigrations) {
+		return fmt.Errorf("database schema version %d is newer than this server supports", applied)
+	}
+
+	for i := applied; i < len(sqliteMigrations); i++ {
+		version := i + 1
+		err := s.inTransaction(func(tx *sql.Tx) error {
+			for _, statement := range sqliteMigrations[i] {
+				if _, err := tx.Exec(statement); err != nil {
+					return err
+				}
+			}
+			_, err := tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, time.Now().Unix())
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("error applying migration %d: %v", version, err)
+		}
+	}
+	return nil
+}
+
+// load reads every game and match into memory
+func (s *SQLiteStore) load() error {
+	games, err := queryRows[models.Game](s.db, `SELECT data FROM games`)
+	if err != nil {
+		return fmt.Errorf("error loading games: %v", err)
+	}
+	matches, err := queryRows[models.MatchDetails](s.db, `SELECT data FROM matches`)
+	if err != nil {
+		return fmt.Errorf("error loading matches: %v", err)
+	}
+
+	s.cache.SaveGames(games...)
+	s.cache.SaveMatches(matches...)
+	return nil
+}
+
+func (s *SQLiteStore) GetGame(gameID string) (*models.Game, error) {
+	return s.cache.GetGame(gameID)
+}
+
+func (s *SQLiteStore) ListGames() ([]*models.Game, error) {
+	return s.cache.ListGames()
+}
+
+func (s *SQLiteStore) SaveGames(games ...*models.Game) error {
+	_, err := s.Commit(nil, games, nil)
+	return err
+}
+
+func (s *SQLiteStore) GetMatch(matchID string) (*models.MatchDetails, error) {
+	return s.cache.GetMatch(matchID)
+}
+
+func (s *SQLiteStore) ListMatches() ([]*models.MatchDetails, error) {
+	return s.cache.ListMatches()
+}
+
+func (s *SQLiteStore) SaveMatches(matches ...*models.MatchDetails) error {
+	_, err := s.Commit(nil, nil, matches)
+	return err
+}
+
+// Append numbers events after the last one in their game's log and stores them
+func (s *SQLiteStore) Append(events ...models.DomainEvent) ([]models.DomainEvent, error) {
+	return s.Commit(events, nil, nil)
+}
+
+// Commit writes events and the games and matches they change in one
+// transaction, and puts the games and matches in memory once it commits
+func (s *SQLiteStore) Commit(events []models.DomainEvent, games []*models.Game, matches []*models.MatchDetails) ([]models.DomainEvent, error) {
+	appended := make([]models.DomainEvent, 0, len(events))
+	err := s.inTransaction(func(tx *sql.Tx) error {
+		for _, event := range events {
+			var version int
+			if err := tx.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM events WHERE game_id = ?`, event.GameID).Scan(&version); err != nil {
+				return err
+			}
+			event.Version = version + 1
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`INSERT INTO events (game_id, version, type, data) VALUES (?, ?, ?, ?)`,
+				event.GameID, event.Version, string(event.Type), string(data))
+			if err != nil {
+				return err
+			}
+			appended = append(appended, event)
+		}
+
+		now := time.Now().Unix()
+		for _, game := range games {
+			data, err := json.Marshal(game)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`INSERT INTO games (id, status, data, updated_at) VALUES (?, ?, ?, ?)
+				ON CONFLICT (id) DO UPDATE SET status = excluded.status, data = excluded.data, updated_at = excluded.updated_at`,
+				game.ID, game.Status, string(data), now)
+			if err != nil {
+				return err
+			}
+		}
+		for _, match := range matches {
+			data, err := json.Marshal(match)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`INSERT INTO matches (id, game_id, status, data, updated_at) VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT (id) DO UPDATE SET game_id = excluded.game_id, status = excluded.status, data = excluded.data, updated_at = excluded.updated_at`,
+				match.ID, match.GameID, match.Status, string(data), now)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error committing changes: %v", err)
+	}
+
+	s.cache.SaveGames(games...)
+	s.cache.SaveMatches(matches...)
+	return appended, nil
+}
+
+func (s *SQLiteStore) Load(gameID string) ([]models.DomainEvent, error) {
+	events, err := queryRows[models.DomainEvent](s.db, `SELECT data FROM events WHERE game_id = ? ORDER BY version`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading events: %v", err)
+	}
+
+	result := make([]models.DomainEvent, 0, len(events))
+	for _, event := range events {
+		result = append(result, *event)
+	}
+	return result, nil
+}
+
+func (s *SQLiteStore) GameIDs() ([]string, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT game_id FROM events ORDER BY game_id`)
+	if err != nil {
+		return nil, fmt.Errorf("error listing games: %v", err)
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error listing games: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// inTransaction runs fn in a transaction that is committed only if fn succeeds
+func (s *SQLiteStore) inTransaction(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// queryRows decodes the JSON in the single column of each row
+func queryRows[T any](db *sql.DB, query string, args ...interface{}) ([]*T, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make([]*T, 0)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		value := new(T)
+		if err := json.Unmarshal([]byte(data), value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"taboo-game/models"
+	"time"
+)
+
+// HandleTranscript adds a chunk of a clue-giver's transcribed speech to the
+// active stage and runs the clue checks on it. Speech-to-text makes
+// mistakes, so nothing in a transcript is called automatically: every match
+// is flagged to the spotters with the moment it was said.
+func (s *GameEventsService) HandleTranscript(gameID, playerID string, chunk models.TranscriptChunk) error {
+	if strings.TrimSpace(chunk.Text) == "" {
+		return errors.New("transcript text is empty")
+	}
+	match, err := s.matchService.GetActiveMatch(gameID)
+	if err != nil {
+		return err
+	}
+	stage := match.CurrentStage
+	if !containsPlayer(stage.ClueGivers, playerID) {
+		return errors.New("only clue-givers in the active stage can send transcripts")
+	}
+	rules := s.matchService.getGameRules(gameID).ClueRules()
+
+	chunk.PlayerID = playerID
+	if chunk.TimestampMS == 0 {
+		chunk.TimestampMS = time.Now().UnixMilli()
+	}
+
+	s.mu.Lock()
+	stageTimer, active := s.activeStages[gameID]
+	if !active || stageTimer.card == nil {
+		s.mu.Unlock()
+		return errors.New("no active stage")
+	}
+	violation, flags := checkClue(chunk.Text, stageTimer.card, rules)
+	if violation != nil {
+		flags = append([]*models.ClueEvidence{violation}, flags...)
+	}
+	chunk.Flags = make([]models.ClueEvidence, 0, len(flags))
+	for _, evidence := range flags {
+		chunk.Flags = append(chunk.Flags, *evidence)
+	}
+	s.mu.Unlock()
+
+	if err := s.matchService.addTranscript(gameID, match.ID, stage.ID, chunk); err != nil {
+		return err
+	}
+	s.flagToSpotters(gameID, playerID, stage, flags, chunk.TimestampMS)
+	return nil
+}
@@ -0,0 +1,521 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"taboo-game/models"
+	"taboo-game/types"
+	"taboo-game/websocket"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Time the clue-giving team has to dispute a violation before it stands
+const defaultDisputeWindow = 15 * time.Second
+
+// SetDisputeWindow changes how long a violation stays open for dispute
+func (s *GameEventsService) SetDisputeWindow(window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disputeWindow = window
+}
+
+// SetReferee lets the game's host appoint the player who can settle
+// violation calls. The referee must be in the game and not playing in the
+// current stage.
+func (s *GameEventsService) SetReferee(gameID, hostID, playerID string) error {
+	if playerID == "" {
+		return errors.New("referee player ID is required")
+	}
+
+	game, err := s.matchService.gameService.GetGame(gameID)
+	if err != nil {
+		return err
+	}
+	if game.HostID == "" || game.HostID != hostID {
+		return errors.New("only the host can appoint the referee")
+	}
+	if !gameHasPlayer(game, playerID) {
+		return errors.New("referee must be a player in the game")
+	}
+	if match, err := s.matchService.GetActiveMatch(gameID); err == nil && containsPlayer(stagePlayers(match.CurrentStage), playerID) {
+		return errors.New("referee cannot be playing in the current stage")
+	}
+
+	s.mu.Lock()
+	s.referees[gameID] = playerID
+	s.mu.Unlock()
+	return nil
+}
+
+// ReportViolation files a spotter's violation call against the current card.
+// No points are awarded until the call is resolved.
+func (s *GameEventsService) ReportViolation(gameID, reporterID, violationType, cardID string) (*models.Violation, error) {
+	category := models.ViolationType(violationType)
+	if !category.IsValid() {
+		return nil, errors.New("unknown violation type")
+	}
+
+	match, err := s.matchService.GetActiveMatch(gameID)
+	if err != nil {
+		return nil, err
+	}
+	stage := match.CurrentStage
+	if !containsPlayer(stage.Spotters, reporterID) {
+		return nil, errors.New("only spotters can report violations")
+	}
+
+	return s.openViolation(match, &models.Violation{
+		CardID:     cardID,
+		Type:       category,
+		ReporterID: reporterID,
+	})
+}
+
+// reportClueViolation raises a violation the clue checker found clear
+// evidence of. It can be disputed like a spotter's call.
+func (s *GameEventsService) reportClueViolation(match *models.MatchDetails, cardID string, evidence *models.ClueEvidence) (*models.Violation, error) {
+	return s.openViolation(match, &models.Violation{
+		CardID:    cardID,
+		Type:      violationTypeFor(evidence.Match),
+		Automatic: true,
+		Evidence:  evidence,
+	})
+}
+
+// openViolation files a call against the current card of the match's
+// stage and starts its dispute window
+func (s *GameEventsService) openViolation(match *models.MatchDetails, violation *models.Violation) (*models.Violation, error) {
+	gameID := match.GameID
+	stage := match.CurrentStage
+
+	s.mu.Lock()
+	stageTimer, active := s.activeStages[gameID]
+	if !active || stageTimer.card == nil {
+		s.mu.Unlock()
+		return nil, errors.New("no active stage")
+	}
+	if violation.CardID == "" {
+		violation.CardID = stageTimer.card.ID
+	} else if violation.CardID != stageTimer.card.ID {
+		s.mu.Unlock()
+		return nil, errors.New("violations can only be reported on the current card")
+	}
+
+	now := time.Now()
+	window := s.disputeWindow
+	violation.ID = uuid.New().String()
+	violation.GameID = gameID
+	violation.MatchID = match.ID
+	violation.StageID = stage.ID
+	violation.OffendingTeamID = stage.ActiveTeamID
+	violation.Status = models.ViolationStatusPending
+	violation.Votes = make(map[string]bool)
+	violation.EligibleVoters = eligibleVoters(match)
+	violation.CardHolders = stage.CardHolders()
+	violation.ReportedAt = now
+	violation.DisputeDeadline = now.Add(window)
+	reported := copyViolation(violation)
+	if err := s.matchService.recordEvent(gameID, models.DomainEventViolationReported, models.ViolationRecordedData{Violation: *reported}); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	s.violations[gameID] = append(s.violations[gameID], violation)
+	s.mu.Unlock()
+
+	time.AfterFunc(window, func() {
+		s.closeDisputeWindow(gameID, violation.ID)
+	})
+
+	s.broadcastViolation(websocket.ViolationReported, reported)
+	return reported, nil
+}
+
+// DisputeViolation lets a clue-giver contest a call while its dispute window
+// is still open. A dispute needs a referee or neutral players to settle it.
+func (s *GameEventsService) DisputeViolation(gameID, violationID, playerID string) (*models.Violation, error) {
+	match, err := s.matchService.GetActiveMatch(gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	violation, err := s.findViolation(gameID, violationID)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	stage := match.CurrentStage
+	if stage.ID != violation.StageID {
+		s.mu.Unlock()
+		return nil, errors.New("stage has already ended")
+	}
+	if !containsPlayer(stage.ClueGivers, playerID) {
+		s.mu.Unlock()
+		return nil, errors.New("only the clue-givers can dispute a violation")
+	}
+	if _, hasReferee := s.referees[gameID]; !hasReferee && len(violation.EligibleVoters) == 0 {
+		s.mu.Unlock()
+		return nil, errors.New("no referee or neutral players to settle a dispute")
+	}
+	if violation.Status != models.ViolationStatusPending {
+		s.mu.Unlock()
+		return nil, errors.New("violation is not open for dispute")
+	}
+	if time.Now().After(violation.DisputeDeadline) {
+		s.mu.Unlock()
+		return nil, errors.New("dispute window has closed")
+	}
+
+	disputed := copyViolation(violation)
+	disputed.Status = models.ViolationStatusDisputed
+	disputed.DisputedBy = playerID
+	if err := s.matchService.recordEvent(gameID, models.DomainEventViolationDisputed, models.ViolationRecordedData{Violation: *disputed}); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	violation.Status = disputed.Status
+	violation.DisputedBy = disputed.DisputedBy
+	s.mu.Unlock()
+
+	s.broadcastViolation(websocket.ViolationDisputed, disputed)
+	return disputed, nil
+}
+
+// VoteOnViolation records a non-involved player's vote on a disputed call.
+// The call is resolved as soon as the outcome can no longer change.
+func (s *GameEventsService) VoteOnViolation(gameID, violationID, playerID string, uphold bool) (*models.Violation, error) {
+	s.mu.Lock()
+	violation, err := s.findViolation(gameID, violationID)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	if violation.Status != models.ViolationStatusDisputed {
+		s.mu.Unlock()
+		return nil, errors.New("violation is not being voted on")
+	}
+	if !containsPlayer(violation.EligibleVoters, playerID) {
+		s.mu.Unlock()
+		return nil, errors.New("player is not eligible to vote on this violation")
+	}
+	if _, voted := violation.Votes[playerID]; voted {
+		s.mu.Unlock()
+		return nil, errors.New("player has already voted")
+	}
+
+	cast := copyViolation(violation)
+	cast.Votes[playerID] = uphold
+	if err := s.matchService.recordEvent(gameID, models.DomainEventViolationVoted, models.ViolationRecordedData{Violation: *cast}); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	violation.Votes[playerID] = uphold
+	decided, upheld := tallyVotes(violation)
+	if decided {
+		resolveViolation(violation, upheld, "vote")
+	}
+	voted := copyViolation(violation)
+	s.mu.Unlock()
+
+	if decided {
+		if err := s.applyResolution(voted, models.ViolationStatusDisputed); err != nil {
+			return nil, err
+		}
+	}
+	s.broadcastViolation(websocket.ViolationVote, voted)
+	if decided {
+		s.broadcastViolation(websocket.ViolationResolved, voted)
+	}
+	return voted, nil
+}
+
+// ResolveViolation settles an open or disputed call by referee decision
+func (s *GameEventsService) ResolveViolation(gameID, violationID, refereeID string, uphold bool) (*models.Violation, error) {
+	var playing []string
+	if match, err := s.matchService.GetActiveMatch(gameID); err == nil {
+		playing = stagePlayers(match.CurrentStage)
+	}
+
+	s.mu.Lock()
+	if referee, exists := s.referees[gameID]; !exists || referee != refereeID {
+		s.mu.Unlock()
+		return nil, errors.New("only the referee can resolve violations")
+	}
+
+	violation, err := s.findViolation(gameID, violationID)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	if violation.IsResolved() {
+		s.mu.Unlock()
+		return nil, errors.New("violation has already been resolved")
+	}
+	if containsPlayer(playing, refereeID) {
+		s.mu.Unlock()
+		return nil, errors.New("referee cannot resolve calls while playing in the stage")
+	}
+
+	previous := violation.Status
+	resolveViolation(violation, uphold, "referee")
+	resolved := copyViolation(violation)
+	s.mu.Unlock()
+
+	if err := s.applyResolution(resolved, previous); err != nil {
+		return nil, err
+	}
+	s.broadcastViolation(websocket.ViolationResolved, resolved)
+	return resolved, nil
+}
+
+// GetViolations returns every violation called in a game, without the
+// evidence of automatic calls
+func (s *GameEventsService) GetViolations(gameID string) []models.Violation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.Violation, 0, len(s.violations[gameID]))
+	for _, violation := range s.violations[gameID] {
+		result = append(result, copyViolation(violation).WithoutEvidence())
+	}
+	return result
+}
+
+// closeDisputeWindow upholds a call nobody disputed in time
+func (s *GameEventsService) closeDisputeWindow(gameID, violationID string) {
+	s.mu.Lock()
+	violation, err := s.findViolation(gameID, violationID)
+	if err != nil || violation.Status != models.ViolationStatusPending {
+		s.mu.Unlock()
+		return
+	}
+	resolveViolation(violation, true, "timeout")
+	resolved := copyViolation(violation)
+	s.mu.Unlock()
+
+	if err := s.applyResolution(resolved, models.ViolationStatusPending); err != nil {
+		log.Printf("Error upholding violation %s of game %s: %v", violationID, gameID, err)
+		return
+	}
+	s.broadcastViolation(websocket.ViolationResolved, resolved)
+}
+
+// applyResolution records the outcome with the points it awards the
+// spotting team. If it cannot be recorded, the call goes back to its
+// previous status so that no outcome is announced.
+func (s *GameEventsService) applyResolution(violation *models.Violation, previous models.ViolationStatus) error {
+	outcome := models.ViolationRecordedData{Violation: *violation}
+	if violation.Status != models.ViolationStatusUpheld {
+		if err := s.matchService.recordEvent(violation.GameID, models.DomainEventViolationResolved, outcome); err != nil {
+			s.reopenViolation(violation, previous)
+			return fmt.Errorf("error recording violation outcome: %v", err)
+		}
+		return nil
+	}
+
+	resolved, err := NewDomainEvent(violation.GameID, models.DomainEventViolationResolved, outcome)
+	if err != nil {
+		s.reopenViolation(violation, previous)
+		return err
+	}
+	attempt := &models.GuessAttempt{
+		CardID:        violation.CardID,
+		Violation:     true,
+		ViolationType: violation.Type,
+		TeamID:        violation.OffendingTeamID,
+		StageID:       violation.StageID,
+		TimestampMS:   violation.ResolvedAt.UnixMilli(),
+	}
+	if err := s.matchService.processGuessAttempt(violation.GameID, violation.MatchID, attempt, resolved); err != nil {
+		s.reopenViolation(violation, previous)
+		return fmt.Errorf("error awarding violation points: %v", err)
+	}
+	return nil
+}
+
+// reopenViolation undoes a resolution whose points could not be awarded
+func (s *GameEventsService) reopenViolation(resolved *models.Violation, previous models.ViolationStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	violation, err := s.findViolation(resolved.GameID, resolved.ID)
+	if err != nil {
+		return
+	}
+	violation.Status = previous
+	violation.ResolvedBy = ""
+	violation.ResolvedAt = time.Time{}
+}
+
+// broadcastViolation announces a violation to the game. Evidence names
+// card words, so only the players who could see the card get it.
+func (s *GameEventsService) broadcastViolation(msgType websocket.MessageType, violation *models.Violation) {
+	if violation.Evidence == nil {
+		msg := websocket.NewMessage(msgType, violation.GameID, "", websocket.ViolationPayload{Violation: violation})
+		s.wsManager.SendToGame(violation.GameID, msg.Encode())
+		return
+	}
+
+	withEvidence := websocket.NewMessage(msgType, violation.GameID, "", websocket.ViolationPayload{Violation: violation})
+	s.wsManager.SendToPlayers(violation.GameID, violation.CardHolders, withEvidence.Encode())
+
+	redacted := violation.WithoutEvidence()
+	withoutEvidence := websocket.NewMessage(msgType, violation.GameID, "", websocket.ViolationPayload{Violation: &redacted})
+	s.wsManager.SendToGameExcept(violation.GameID, violation.CardHolders, withoutEvidence.Encode())
+}
+
+// Rebuild restores the violation calls of every game from the event logs.
+// Calls still open for dispute get what is left of their window.
+func (s *GameEventsService) Rebuild(store types.EventStoreInterface) error {
+	gameIDs, err := store.GameIDs()
+	if err != nil {
+		return err
+	}
+
+	violations := make(map[string][]*models.Violation)
+	for _, gameID := range gameIDs {
+		events, err := store.Load(gameID)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := applyViolationEvent(violations, event); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.violations = violations
+	s.mu.Unlock()
+
+	for gameID, calls := range violations {
+		for _, violation := range calls {
+			if violation.Status == models.ViolationStatusPending {
+				gameID, violationID := gameID, violation.ID
+				time.AfterFunc(time.Until(violation.DisputeDeadline), func() {
+					s.closeDisputeWindow(gameID, violationID)
+				})
+			}
+		}
+	}
+	return nil
+}
+
+// applyViolationEvent folds a logged violation event into the calls being
+// rebuilt. Each event carries the call as it stood afterwards.
+func applyViolationEvent(violations map[string][]*models.Violation, event models.DomainEvent) error {
+	switch event.Type {
+	case models.DomainEventViolationReported, models.DomainEventViolationDisputed,
+		models.DomainEventViolationVoted, models.DomainEventViolationResolved:
+	default:
+		return nil
+	}
+
+	var data models.ViolationRecordedData
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return err
+	}
+	violation := data.Violation
+	if violation.Votes == nil {
+		violation.Votes = make(map[string]bool)
+	}
+	calls := violations[event.GameID]
+	for i, call := range calls {
+		if call.ID == violation.ID {
+			calls[i] = &violation
+			return nil
+		}
+	}
+	violations[event.GameID] = append(calls, &violation)
+	return nil
+}
+
+// findViolation must be called with s.mu held
+func (s *GameEventsService) findViolation(gameID, violationID string) (*models.Violation, error) {
+	for _, violation := range s.violations[gameID] {
+		if violation.ID == violationID {
+			return violation, nil
+		}
+	}
+	return nil, errors.New("violation not found")
+}
+
+func resolveViolation(violation *models.Violation, upheld bool, resolvedBy string) {
+	if upheld {
+		violation.Status = models.ViolationStatusUpheld
+	} else {
+		violation.Status = models.ViolationStatusRejected
+	}
+	violation.ResolvedBy = resolvedBy
+	violation.ResolvedAt = time.Now()
+}
+
+// tallyVotes decides a disputed call by strict majority of eligible voters.
+// A tie rejects the call.
+func tallyVotes(violation *models.Violation) (decided bool, upheld bool) {
+	eligible := len(violation.EligibleVoters)
+	if eligible == 0 {
+		return false, false
+	}
+	upholdVotes, rejectVotes := 0, 0
+	for _, vote := range violation.Votes {
+		if vote {
+			upholdVotes++
+		} else {
+			rejectVotes++
+		}
+	}
+
+	switch {
+	case upholdVotes*2 > eligible:
+		return true, true
+	case rejectVotes*2 >= eligible:
+		return true, false
+	}
+	return false, false
+}
+
+// eligibleVoters returns the players not involved in the call: everyone in
+// the match except the clue-giving team and the spotters who made the call
+func eligibleVoters(match *models.MatchDetails) []string {
+	stage := match.CurrentStage
+	voters := make([]string, 0)
+	for _, players := range [][]string{match.TeamAPlayers, match.TeamBPlayers} {
+		for _, playerID := range players {
+			if match.TeamOf(playerID) == stage.ActiveTeamID || containsPlayer(stage.Spotters, playerID) {
+				continue
+			}
+			voters = append(voters, playerID)
+		}
+	}
+	return voters
+}
+
+func gameHasPlayer(game *models.Game, playerID string) bool {
+	for _, team := range game.Teams {
+		for _, player := range team.Players {
+			if player.ID == playerID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func copyViolation(violation *models.Violation) *models.Violation {
+	copied := *violation
+	copied.Votes = make(map[string]bool, len(violation.Votes))
+	for playerID, vote := range violation.Votes {
+		copied.Votes[playerID] = vote
+	}
+	copied.EligibleVoters = append([]string(nil), violation.EligibleVoters...)
+	copied.CardHolders = append([]string(nil), violation.CardHolders...)
+	return &copied
+}
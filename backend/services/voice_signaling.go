@@ -0,0 +1,51 @@
+package services
+
+import (
+	"errors"
+	"taboo-game/websocket"
+)
+
+// SendVoiceRooms sends a player the voice rooms of the game's current match,
+// with who they hear in each
+func (s *GameEventsService) SendVoiceRooms(gameID, playerID string) error {
+	match, err := s.matchService.currentGameMatch(gameID)
+	if err != nil {
+		return err
+	}
+	if match.TeamOf(playerID) == "" {
+		return errors.New("player is not in the current match")
+	}
+
+	msg := websocket.NewMessage(websocket.VoiceRooms, gameID, playerID, websocket.VoiceRoomsPayload{
+		Rooms: match.VoiceRooms(playerID),
+	})
+	s.wsManager.SendToPlayer(gameID, playerID, msg.Encode())
+	return nil
+}
+
+// announceVoiceRooms sends every player of the match their voice rooms, since
+// who hears whom changes with the stage roles
+func (s *GameEventsService) announceVoiceRooms(gameID string) {
+	match, err := s.matchService.currentGameMatch(gameID)
+	if err != nil {
+		return
+	}
+	for _, players := range [][]string{match.TeamAPlayers, match.TeamBPlayers} {
+		for _, playerID := range players {
+			s.SendVoiceRooms(gameID, playerID)
+		}
+	}
+}
+
+// AuthorizeSignal checks that two players may exchange WebRTC signaling in a
+// voice room, which they may when either of them hears the other there
+func (s *GameEventsService) AuthorizeSignal(gameID, fromID, toID, roomID string) error {
+	match, err := s.matchService.currentGameMatch(gameID)
+	if err != nil {
+		return err
+	}
+	if !match.CanHear(roomID, fromID, toID) && !match.CanHear(roomID, toID, fromID) {
+		return errors.New("players cannot hear each other in this voice room")
+	}
+	return nil
+}
@@ -3,15 +3,23 @@ package services
 import (
 	"encoding/csv"
 	"errors"
+	"fmt"
 	"io"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 
 	"taboo-game/models"
+
+	"github.com/google/uuid"
 )
 
+// WordService deals cards from the word lists. Cards in the deck are keyed
+// by their place in the lists, which never leaves the server: each card
+// drawn gets an ID of its own.
 type WordService struct {
 	wordCards []models.WordCard
 	mu        sync.RWMutex
@@ -53,6 +61,7 @@ func (ws *WordService) loadWordsFromFile(filePath string) error {
 	defer file.Close()
 
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // The alternates column is optional
 
 	// Skip header row
 	_, err = reader.Read()
@@ -60,7 +69,7 @@ func (ws *WordService) loadWordsFromFile(filePath string) error {
 		return err
 	}
 
-	for {
+	for line := 1; ; line++ {
 		record, err := reader.Read()
 		if err == io.EOF {
 			break
@@ -69,19 +78,25 @@ func (ws *WordService) loadWordsFromFile(filePath string) error {
 			return err
 		}
 
-		// Assuming CSV format: target_word,taboo_word1,taboo_word2,taboo_word3,difficulty,category
+		// Assuming CSV format: target_word,taboo_word1,taboo_word2,taboo_word3,difficulty,category[,alternates]
+		// with alternate answers separated by "|"
 		if len(record) < 6 {
 			continue
 		}
 
 		tabooWords := record[1:4]
 		card := models.WordCard{
-			ID:         filepath.Base(filePath) + "-" + record[0],
+			ID:         fmt.Sprintf("%s-%d", filepath.Base(filePath), line), // Deck key, replaced when drawn
 			TargetWord: record[0],
 			TabooWords: tabooWords,
 			Difficulty: 1, // Parse from record[4] if needed
 			Category:   record[5],
 		}
+		if len(record) > 6 && strings.TrimSpace(record[6]) != "" {
+			for _, alternate := range strings.Split(record[6], "|") {
+				card.AlternateAnswers = append(card.AlternateAnswers, strings.TrimSpace(alternate))
+			}
+		}
 
 		ws.wordCards = append(ws.wordCards, card)
 	}
@@ -98,16 +113,50 @@ func (ws *WordService) GetNextCard() (*models.WordCard, error) {
 		ws.usedCards = make(map[string]bool)
 	}
 
-	// Find an unused card
-	for attempts := 0; attempts < 10; attempts++ {
-		idx := rand.Intn(len(ws.wordCards))
-		card := ws.wordCards[idx]
-
+	// Pick a random unused card
+	unused := make([]int, 0, len(ws.wordCards)-len(ws.usedCards))
+	for idx, card := range ws.wordCards {
 		if !ws.usedCards[card.ID] {
-			ws.usedCards[card.ID] = true
-			return &card, nil
+			unused = append(unused, idx)
 		}
 	}
+	if len(unused) == 0 {
+		return nil, errors.New("failed to find unused card")
+	}
+
+	card := ws.wordCards[unused[rand.Intn(len(unused))]]
+	ws.usedCards[card.ID] = true
+	card.ID = uuid.New().String()
+	return &card, nil
+}
+
+// usedCardIDs returns the deck keys of the cards drawn since the deck was
+// last reshuffled
+func (ws *WordService) usedCardIDs() []string {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	ids := make([]string, 0, len(ws.usedCards))
+	for id := range ws.usedCards {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
 
-	return nil, errors.New("failed to find unused card")
+// markCardsUsed takes cards drawn before a restart out of the deck. Cards
+// that are no longer in the deck are ignored.
+func (ws *WordService) markCardsUsed(ids []string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	known := make(map[string]bool, len(ws.wordCards))
+	for _, card := range ws.wordCards {
+		known[card.ID] = true
+	}
+	for _, id := range ids {
+		if known[id] {
+			ws.usedCards[id] = true
+		}
+	}
 }
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"taboo-game/auth"
+	"taboo-game/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var secret = []byte(strings.Repeat("s", 32))
+
+func TestTokenSigner(t *testing.T) {
+	t.Run("rejects a short secret", func(t *testing.T) {
+		_, err := auth.NewTokenSigner([]byte("short"), time.Hour)
+		assert.Error(t, err)
+	})
+
+	t.Run("verifies its own tokens", func(t *testing.T) {
+		signer, _ := auth.NewTokenSigner(secret, time.Hour)
+		token, err := signer.Issue("game1", "p1")
+		require.NoError(t, err)
+
+		claims, err := signer.Verify(token)
+		require.NoError(t, err)
+		assert.Equal(t, "game1", claims.GameID)
+		assert.Equal(t, "p1", claims.PlayerID)
+	})
+
+	t.Run("rejects tampered, foreign and expired tokens", func(t *testing.T) {
+		signer, _ := auth.NewTokenSigner(secret, time.Hour)
+		token, _ := signer.Issue("game1", "p1")
+
+		_, err := signer.Verify("x" + token)
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+		other, _ := auth.NewTokenSigner([]byte(strings.Repeat("o", 32)), time.Hour)
+		_, err = other.Verify(token)
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+		expired, _ := auth.NewTokenSigner(secret, -time.Minute)
+		token, _ = expired.Issue("game1", "p1")
+		_, err = signer.Verify(token)
+		assert.ErrorIs(t, err, auth.ErrExpiredToken)
+	})
+}
+
+func TestSessionAuthenticator(t *testing.T) {
+	gameService := services.NewGameService()
+	game, _ := gameService.CreateGame(2)
+	player, _ := gameService.AddPlayer(game.ID, "Player1", nil)
+
+	signer, _ := auth.NewTokenSigner(secret, time.Hour)
+	authenticator := auth.NewSessionAuthenticator(signer, gameService)
+	token, _ := signer.Issue(game.ID, player.ID)
+	strangerToken, _ := signer.Issue(game.ID, "stranger")
+
+	cases := []struct {
+		name     string
+		query    string
+		header   string
+		playerID string
+		status   int
+	}{
+		{"no token", "", "", player.ID, http.StatusUnauthorized},
+		{"bad token", "?token=nope", "", player.ID, http.StatusUnauthorized},
+		{"query token", "?token=" + token, "", player.ID, http.StatusOK},
+		{"bearer token", "", "Bearer " + token, player.ID, http.StatusOK},
+		{"another player's token", "?token=" + token, "", "p2", http.StatusForbidden},
+		{"not in the game", "?token=" + strangerToken, "", "stranger", http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/ws/"+game.ID+"/"+tc.playerID+tc.query, nil)
+		if tc.header != "" {
+			r.Header.Set("Authorization", tc.header)
+		}
+		status, err := authenticator.Authenticate(r, game.ID, tc.playerID)
+		assert.Equal(t, tc.status, status, tc.name)
+		assert.Equal(t, tc.status != http.StatusOK, err != nil, tc.name)
+	}
+
+	t.Run("REST callers are identified by their token", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/games/"+game.ID+"/violations", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		playerID, status, err := authenticator.Identify(r, game.ID)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, player.ID, playerID)
+
+		other, _ := gameService.CreateGame(2)
+		_, status, err = authenticator.Identify(r, other.ID)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status, "tokens are only good for their own game")
+	})
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"taboo-game/handlers"
+	"taboo-game/models"
+	"taboo-game/routes"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// headerSessions takes the caller from a test header instead of a token
+type headerSessions struct{}
+
+func (headerSessions) Identify(r *http.Request, gameID string) (string, int, error) {
+	playerID := r.Header.Get("X-Test-Player")
+	if playerID == "" {
+		return "", http.StatusUnauthorized, errors.New("session token is required")
+	}
+	return playerID, http.StatusOK, nil
+}
+
+type fakeEventLog struct {
+	events []models.DomainEvent
+}
+
+func (l *fakeEventLog) Events(gameID string) ([]models.DomainEvent, error) {
+	return append([]models.DomainEvent(nil), l.events...), nil
+}
+
+func (l *fakeEventLog) ReplayGame(gameID string, version int) (*models.Game, error) {
+	return &models.Game{ID: gameID, Version: version}, nil
+}
+
+type fakeRoles map[string]models.PlayerRole
+
+func (r fakeRoles) PlayerRole(gameID, playerID string) models.PlayerRole {
+	if role, ok := r[playerID]; ok {
+		return role
+	}
+	return models.PlayerRoleSpectator
+}
+
+func domainEvent(t *testing.T, eventType models.DomainEventType, data interface{}) models.DomainEvent {
+	encoded, err := json.Marshal(data)
+	require.NoError(t, err)
+	return models.DomainEvent{GameID: "game1", Type: eventType, Data: encoded}
+}
+
+func TestEventLogRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	card := models.WordCard{ID: "card-1", TargetWord: "Custard", TabooWords: []string{"dessert"}}
+	eventLog := &fakeEventLog{events: []models.DomainEvent{
+		domainEvent(t, models.DomainEventCardDrawn, models.CardDrawnData{StageNumber: 1, Card: card}),
+		domainEvent(t, models.DomainEventGuessRecorded, models.GuessRecordedData{Attempt: models.GuessAttempt{CardID: "card-1", Guess: "custard"}}),
+		domainEvent(t, models.DomainEventTranscriptAdded, models.TranscriptAddedData{Chunk: models.TranscriptChunk{Text: "pudding", Flags: []models.ClueEvidence{{CardWord: "Custard"}}}}),
+		domainEvent(t, models.DomainEventViolationReported, models.ViolationRecordedData{Violation: models.Violation{ID: "v1", Evidence: &models.ClueEvidence{CardWord: "dessert"}}}),
+	}}
+	handler := handlers.NewEventLogHandler(eventLog)
+	handler.SetRoleResolver(fakeRoles{"a1": models.PlayerRoleClueGiver, "a2": models.PlayerRoleGuesser})
+	router := gin.New()
+	routes.NewEventLogRoutes(handler, handlers.RequireSession(headerSessions{})).RegisterRoutes(router)
+
+	get := func(path, playerID string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/games/game1"+path, nil)
+		if playerID != "" {
+			req.Header.Set("X-Test-Player", playerID)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("the log needs a session", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, get("/events", "").Code)
+		assert.Equal(t, http.StatusUnauthorized, get("/replay?version=1", "").Code)
+		assert.Equal(t, http.StatusOK, get("/replay?version=1", "a2").Code)
+	})
+
+	t.Run("players who cannot see the card get no card words", func(t *testing.T) {
+		for _, playerID := range []string{"a2", "spectator"} {
+			w := get("/events", playerID)
+			require.Equal(t, http.StatusOK, w.Code)
+			body := w.Body.String()
+			for _, secret := range []string{"Custard", "dessert", "flags", "evidence", `"guess"`} {
+				assert.NotContains(t, body, secret, playerID)
+			}
+			assert.Contains(t, body, "card-1", "events keep what is not secret")
+		}
+	})
+
+	t.Run("players who can see the card get the whole log", func(t *testing.T) {
+		w := get("/events", "a1")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Custard")
+		assert.Contains(t, w.Body.String(), "evidence")
+	})
+}
@@ -11,6 +11,7 @@ import (
 
 	"taboo-game/handlers"
 	"taboo-game/models"
+	"taboo-game/routes"
 	"taboo-game/tests/mocks"
 
 	"github.com/gin-gonic/gin"
@@ -77,3 +78,122 @@ func TestCreateGame(t *testing.T) {
 		})
 	}
 }
+
+// newGameContext builds a request for a game. An X-Test-Player header
+// makes that player the session's caller.
+func newGameContext(method, gameID, body string, headers map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, "/games/"+gameID, strings.NewReader(body))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "gameId", Value: gameID}}
+	if headers["X-Test-Player"] != "" {
+		handlers.RequireSession(headerSessions{})(c)
+	}
+	return c, w
+}
+
+func TestGameETags(t *testing.T) {
+	var updated *models.Game
+	mockService := &mocks.MockGameService{
+		GetGameFunc: func(gameID string) (*models.Game, error) {
+			return &models.Game{ID: gameID, Status: models.GameStatusWaiting, HostID: "host", Version: 3, Rules: models.DefaultGameRules()}, nil
+		},
+		StartGameFunc: func(gameID string, expected models.VersionMatch) (*models.Game, error) {
+			// The version is compared by the service, under the game's lock
+			if !expected.Allows(3) {
+				return nil, models.ErrVersionConflict
+			}
+			return &models.Game{ID: gameID, Status: models.GameStatusInProgress, Version: 4}, nil
+		},
+		UpdateGameFunc: func(game *models.Game) error {
+			updated = game
+			return nil
+		},
+	}
+	handler := handlers.NewGameHandler(mockService)
+
+	t.Run("reads carry the game's version as ETag", func(t *testing.T) {
+		c, w := newGameContext("GET", "game-1", "", nil)
+		handler.GetGame(c)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+		c, w = newGameContext("GET", "game-1", "", map[string]string{"If-None-Match": `"3"`})
+		handler.GetGame(c)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("stale writes are refused", func(t *testing.T) {
+		c, w := newGameContext("POST", "game-1", "", map[string]string{"If-Match": `"2"`})
+		handler.StartGame(c)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+		c, w = newGameContext("PUT", "game-1", `{"guessing":{"maxTypos":2,"closeDistance":3}}`, map[string]string{"If-Match": `"2"`, "X-Test-Player": "host"})
+		handler.UpdateRules(c)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		assert.Nil(t, updated)
+	})
+
+	t.Run("only the host changes the rules", func(t *testing.T) {
+		c, w := newGameContext("PUT", "game-1", `{"guessing":{"maxTypos":2,"closeDistance":3}}`, map[string]string{"X-Test-Player": "player-2"})
+		handler.UpdateRules(c)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Nil(t, updated)
+	})
+
+	t.Run("current writes go ahead", func(t *testing.T) {
+		for _, ifMatch := range []string{`"3"`, `"1", "3"`, "*", ""} {
+			c, w := newGameContext("POST", "game-1", "", map[string]string{"If-Match": ifMatch})
+			handler.StartGame(c)
+			assert.Equal(t, http.StatusOK, w.Code, ifMatch)
+			assert.Equal(t, `"4"`, w.Header().Get("ETag"), ifMatch)
+		}
+
+		c, w := newGameContext("PUT", "game-1", `{"guessing":{"maxTypos":2,"closeDistance":3}}`, map[string]string{"If-Match": `"3"`, "X-Test-Player": "host"})
+		handler.UpdateRules(c)
+		assert.Equal(t, http.StatusOK, w.Code)
+		if assert.NotNil(t, updated) {
+			assert.Equal(t, 3, updated.Version, "the service checks the version the handler read")
+			assert.Equal(t, 2, updated.Rules.Guessing.MaxTypos)
+		}
+	})
+
+	t.Run("a game changed between read and write is a conflict", func(t *testing.T) {
+		mockService.UpdateGameFunc = func(game *models.Game) error {
+			return models.ErrVersionConflict
+		}
+		c, w := newGameContext("PUT", "game-1", `{}`, map[string]string{"X-Test-Player": "host"})
+		handler.UpdateRules(c)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		c, w = newGameContext("PUT", "game-1", `{}`, map[string]string{"If-Match": `"3"`, "X-Test-Player": "host"})
+		handler.UpdateRules(c)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+}
+
+func TestGameRoutesNeedSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := &mocks.MockGameService{
+		GetGameFunc: func(gameID string) (*models.Game, error) {
+			return &models.Game{ID: gameID, Status: models.GameStatusWaiting, HostID: "host", Rules: models.DefaultGameRules()}, nil
+		},
+	}
+	router := gin.New()
+	routes.NewGameRoutes(handlers.NewGameHandler(mockService), handlers.RequireSession(headerSessions{})).RegisterRoutes(router)
+
+	for _, path := range []string{"/start", "/end", "/rules"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("PUT", "/api/v1/games/game-1"+path, strings.NewReader(`{}`)))
+		assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/games/game-1", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, "reading a game needs no session")
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"taboo-game/auth"
+	"taboo-game/handlers"
+	"taboo-game/models"
+	"taboo-game/routes"
+	"taboo-game/services"
+	"taboo-game/tests/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	gameService := services.NewGameService()
+	matchService := services.NewMatchService(gameService, &mocks.MockWebSocketManager{})
+	signer, err := auth.NewTokenSigner([]byte(strings.Repeat("s", 32)), time.Hour)
+	require.NoError(t, err)
+	router := gin.New()
+	requireSession := handlers.RequireSession(auth.NewSessionAuthenticator(signer, gameService))
+	routes.NewMatchRoutes(handlers.NewMatchHandler(matchService), requireSession).RegisterRoutes(router)
+
+	game, err := gameService.CreateGame(2)
+	require.NoError(t, err)
+	players := make([]string, 4)
+	for i := range players {
+		player, err := gameService.AddPlayer(game.ID, fmt.Sprintf("Player%d", i+1), nil)
+		require.NoError(t, err)
+		players[i] = player.ID
+	}
+	token, err := signer.Issue(game.ID, players[0])
+	require.NoError(t, err)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/api/v1/games/"+game.ID+"/matches/match-1"+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/start", fmt.Sprintf(`{"teamAssignments":{"teamA":["%s","%s"],"teamB":["%s","%s"]}}`, players[0], players[1], players[2], players[3]))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = send("POST", "/stages", fmt.Sprintf(`{"activeTeamId":"teamA","spottingTeamId":"teamB","clueGivers":["%s"],"guessers":["%s"],"spotters":["%s","%s"]}`, players[0], players[1], players[2], players[3]))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = send("POST", "/guess", `{"cardId":"card-1","teamId":"teamA"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = send("POST", "/guess", `{"cardId":"card-1","violation":true,"violationType":"gesture","teamId":"teamA"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "violations are reported and resolved through the violation workflow")
+	w = send("POST", "/score", `{"isTeamA":true}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = send("POST", "/guess", `{"cardId":"card-1","correct":true,"teamId":"teamA"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "correct guesses are judged by the server")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/games/"+game.ID+"/scores", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var ledger []models.ScoreEntry
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&ledger))
+	require.Len(t, ledger, 1, "neither the missed guess nor the rejected calls score")
+	assert.Equal(t, "teamA", ledger[0].TeamID)
+	assert.Equal(t, models.ScoreReasonHostAward, ledger[0].Reason)
+
+	guesserToken, err := signer.Issue(game.ID, players[1])
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/v1/games/"+game.ID+"/matches/match-1/score", strings.NewReader(`{"isTeamA":true}`))
+	req.Header.Set("Authorization", "Bearer "+guesserToken)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "only the host awards points")
+
+	other, err := gameService.CreateGame(2)
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/v1/games/"+other.ID+"/matches/match-1/score", strings.NewReader(`{"isTeamA":true}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code, "the token is only good for its own game")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/games/"+game.ID+"/matches/match-1/score", strings.NewReader(`{"isTeamA":true}`)))
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "scoring needs a session token")
+}
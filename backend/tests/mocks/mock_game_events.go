@@ -1,15 +1,15 @@
 package mocks
 
 type MockGameEvents struct {
-	StartStageFunc      func(gameID string, stageNum int) error
+	StartStageFunc      func(gameID string, playerID string, stageNum int) error
 	HandleClueFunc      func(gameID string, playerID string, clue string) error
 	HandleGuessFunc     func(gameID string, playerID string, guess string) error
 	HandleViolationFunc func(gameID string, reporterID string, violationType string) error
 }
 
-func (m *MockGameEvents) StartStage(gameID string, stageNum int) error {
+func (m *MockGameEvents) StartStage(gameID string, playerID string, stageNum int) error {
 	if m.StartStageFunc != nil {
-		return m.StartStageFunc(gameID, stageNum)
+		return m.StartStageFunc(gameID, playerID, stageNum)
 	}
 	return nil
 }
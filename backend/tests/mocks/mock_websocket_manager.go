@@ -6,9 +6,11 @@ import (
 )
 
 type MockWebSocketManager struct {
-	SendToGameFunc func(gameID string, message []byte)
-	RegisterFunc   func(client types.WebSocketClientInterface)
-	UnregisterFunc func(client types.WebSocketClientInterface)
+	SendToGameFunc       func(gameID string, message []byte)
+	SendToPlayersFunc    func(gameID string, playerIDs []string, message []byte)
+	SendToGameExceptFunc func(gameID string, excludedPlayerIDs []string, message []byte)
+	RegisterFunc         func(client types.WebSocketClientInterface)
+	UnregisterFunc       func(client types.WebSocketClientInterface)
 }
 
 func (m *MockWebSocketManager) SendToGame(gameID string, message []byte) {
@@ -17,6 +19,22 @@ func (m *MockWebSocketManager) SendToGame(gameID string, message []byte) {
 	}
 }
 
+func (m *MockWebSocketManager) SendToPlayer(gameID, playerID string, message []byte) {
+	m.SendToPlayers(gameID, []string{playerID}, message)
+}
+
+func (m *MockWebSocketManager) SendToPlayers(gameID string, playerIDs []string, message []byte) {
+	if m.SendToPlayersFunc != nil {
+		m.SendToPlayersFunc(gameID, playerIDs, message)
+	}
+}
+
+func (m *MockWebSocketManager) SendToGameExcept(gameID string, excludedPlayerIDs []string, message []byte) {
+	if m.SendToGameExceptFunc != nil {
+		m.SendToGameExceptFunc(gameID, excludedPlayerIDs, message)
+	}
+}
+
 func (m *MockWebSocketManager) Register(client types.WebSocketClientInterface) {
 	if m.RegisterFunc != nil {
 		m.RegisterFunc(client)
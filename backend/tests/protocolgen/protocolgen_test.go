@@ -0,0 +1,52 @@
+package protocolgen
+
+import (
+	"encoding/json"
+	"os"
+	"taboo-game/protocolgen"
+	"taboo-game/websocket"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const frontendTypes = "../../../frontend/src/types/"
+
+// The frontend's copies must be regenerated whenever a payload changes
+func TestGeneratedFilesAreUpToDate(t *testing.T) {
+	ts, err := protocolgen.TypeScript()
+	require.NoError(t, err)
+	committed, err := os.ReadFile(frontendTypes + "protocol.ts")
+	require.NoError(t, err)
+	assert.Equal(t, string(committed), ts, "run go generate ./websocket")
+
+	schema, err := protocolgen.JSONSchema()
+	require.NoError(t, err)
+	committed, err = os.ReadFile(frontendTypes + "protocol.schema.json")
+	require.NoError(t, err)
+	assert.Equal(t, string(committed), string(schema), "run go generate ./websocket")
+}
+
+func TestJSONSchema(t *testing.T) {
+	data, err := protocolgen.JSONSchema()
+	require.NoError(t, err)
+
+	var schema struct {
+		OneOf []map[string]string        `json:"oneOf"`
+		Defs  map[string]json.RawMessage `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(data, &schema))
+	assert.Len(t, schema.OneOf, len(websocket.Messages))
+	assert.Contains(t, schema.Defs, "GiveClueMessage")
+	assert.Contains(t, schema.Defs, "GiveCluePayload")
+
+	var giveClue struct {
+		Properties map[string]map[string]interface{} `json:"properties"`
+		Required   []string                          `json:"required"`
+	}
+	require.NoError(t, json.Unmarshal(schema.Defs["GiveClueMessage"], &giveClue))
+	assert.Equal(t, "GIVE_CLUE", giveClue.Properties["type"]["const"])
+	assert.Equal(t, float64(websocket.ProtocolVersion), giveClue.Properties["v"]["const"])
+	assert.NotContains(t, giveClue.Required, "seq")
+}
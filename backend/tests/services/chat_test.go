@@ -0,0 +1,138 @@
+package services_test
+
+import (
+	"taboo-game/models"
+	"taboo-game/services"
+	"taboo-game/tests/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendChat(t *testing.T) {
+	t.Run("game messages reach everyone and team messages only the team", func(t *testing.T) {
+		ges, match, captured := setupGameEventsService(t)
+
+		message, err := ges.SendChat(match.GameID, "a3", models.ChatChannelGame, " good luck ")
+		require.NoError(t, err)
+		assert.Equal(t, "good luck", message.Text)
+		assert.Contains(t, captured.types(), "CHAT_MESSAGE")
+
+		_, err = ges.SendChat(match.GameID, "b3", "teamB", "watch the rhymes")
+		require.NoError(t, err)
+		sent := captured.targetedOfType("CHAT_MESSAGE")
+		require.Len(t, sent, 1)
+		assert.ElementsMatch(t, match.TeamBPlayers, sent[0].players)
+
+		_, err = ges.SendChat(match.GameID, "a3", "teamB", "hello")
+		assert.Error(t, err, "players cannot post on the other team's channel")
+		_, err = ges.SendChat(match.GameID, "a3", "lobby", "hello")
+		assert.Error(t, err)
+		_, err = ges.SendChat(match.GameID, "a3", models.ChatChannelGame, "  ")
+		assert.Error(t, err)
+	})
+
+	t.Run("clue-givers cannot chat during their stage", func(t *testing.T) {
+		ges, match, _ := setupGameEventsService(t)
+		_, err := ges.SendChat(match.GameID, "a1", "teamA", "it's sweet")
+		assert.Error(t, err)
+		_, err = ges.SendChat(match.GameID, "a1", models.ChatChannelGame, "nice")
+		assert.Error(t, err)
+	})
+
+	t.Run("spotters cannot mention the card on the game channel", func(t *testing.T) {
+		ges, match, _ := setupGameEventsService(t)
+		_, err := ges.SendChat(match.GameID, "b1", models.ChatChannelGame, "say custard, go on")
+		assert.Error(t, err)
+
+		_, err = ges.SendChat(match.GameID, "b1", "teamB", "they'll never say custard")
+		assert.NoError(t, err, "the spotting team's channel stays private to players who know the card")
+		_, err = ges.SendChat(match.GameID, "a3", models.ChatChannelGame, "is it custard?")
+		assert.NoError(t, err, "guessers cannot give away what they don't know")
+	})
+
+	t.Run("players are rate limited", func(t *testing.T) {
+		ges, match, _ := setupGameEventsService(t)
+		ges.SetChatRateLimit(2, time.Minute)
+
+		for i := 0; i < 2; i++ {
+			_, err := ges.SendChat(match.GameID, "a3", models.ChatChannelGame, "hi")
+			require.NoError(t, err)
+		}
+		_, err := ges.SendChat(match.GameID, "a3", models.ChatChannelGame, "hi")
+		assert.Error(t, err)
+		_, err = ges.SendChat(match.GameID, "b3", models.ChatChannelGame, "hi")
+		assert.NoError(t, err, "limits are per player")
+	})
+
+	t.Run("team channels work in the lobby", func(t *testing.T) {
+		captured := &capturedMessages{}
+		wsManager := &mocks.MockWebSocketManager{
+			SendToPlayersFunc: func(gameID string, playerIDs []string, message []byte) {
+				captured.addTargeted(playerIDs, false, message)
+			},
+		}
+		gameService := &mocks.MockGameService{
+			GetGameFunc: func(gameID string) (*models.Game, error) {
+				return &models.Game{ID: gameID, Teams: []models.Team{
+					{ID: "t1", Players: []models.Player{{ID: "p1"}, {ID: "p2"}}},
+					{ID: "t2", Players: []models.Player{{ID: "p3"}}},
+				}}, nil
+			},
+		}
+		ges := services.NewGameEventsService(services.NewMatchService(gameService, wsManager), newTestWordService(t), wsManager)
+
+		_, err := ges.SendChat("lobby-game", "p1", "teamA", "ready?")
+		require.NoError(t, err)
+		sent := captured.targetedOfType("CHAT_MESSAGE")
+		require.Len(t, sent, 1)
+		assert.ElementsMatch(t, []string{"p1", "p2"}, sent[0].players)
+
+		_, err = ges.SendChat("lobby-game", "p3", "teamA", "hello")
+		assert.Error(t, err)
+		assert.Len(t, ges.ChatHistory("lobby-game", "p2"), 1)
+		assert.Empty(t, ges.ChatHistory("lobby-game", "p3"))
+	})
+
+	t.Run("rate limits are forgotten after the window and when players leave", func(t *testing.T) {
+		ges, match, _ := setupGameEventsService(t)
+		ges.SetChatRateLimit(5, 20*time.Millisecond)
+
+		_, err := ges.SendChat(match.GameID, "a3", models.ChatChannelGame, "hi")
+		require.NoError(t, err)
+		_, err = ges.SendChat(match.GameID, "b3", models.ChatChannelGame, "hi")
+		require.NoError(t, err)
+		assert.Equal(t, 2, ges.ChatSenders())
+
+		ges.PresenceChanged(models.PresenceLeft, models.PlayerPresence{GameID: match.GameID, PlayerID: "b3"})
+		assert.Equal(t, 1, ges.ChatSenders())
+
+		time.Sleep(30 * time.Millisecond)
+		_, err = ges.SendChat(match.GameID, "b4", models.ChatChannelGame, "hi")
+		require.NoError(t, err)
+		assert.Equal(t, 1, ges.ChatSenders(), "only the latest sender is still within the window")
+	})
+}
+
+func TestChatHistory(t *testing.T) {
+	ges, match, _ := setupGameEventsService(t)
+	_, err := ges.SendChat(match.GameID, "a3", models.ChatChannelGame, "hello all")
+	require.NoError(t, err)
+	_, err = ges.SendChat(match.GameID, "a3", "teamA", "hello team")
+	require.NoError(t, err)
+	_, err = ges.SendChat(match.GameID, "b3", "teamB", "hello B")
+	require.NoError(t, err)
+
+	texts := func(playerID string) []string {
+		var result []string
+		for _, message := range ges.ChatHistory(match.GameID, playerID) {
+			result = append(result, message.Text)
+		}
+		return result
+	}
+	assert.Equal(t, []string{"hello all", "hello team"}, texts("a2"))
+	assert.Equal(t, []string{"hello all", "hello B"}, texts("b1"))
+	assert.Equal(t, []string{"hello all"}, texts("someone-else"))
+}
@@ -0,0 +1,699 @@
+package services_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"taboo-game/models"
+	"taboo-game/services"
+	"taboo-game/tests/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type capturedMessages struct {
+	mu       sync.Mutex
+	messages []map[string]interface{}
+	targeted []targetedMessage
+}
+
+// targetedMessage is a message sent to, or to everyone except, some players
+type targetedMessage struct {
+	players  []string
+	excluded bool
+	message  map[string]interface{}
+}
+
+func (c *capturedMessages) addTargeted(players []string, excluded bool, message []byte) {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(message, &decoded); err != nil {
+		return
+	}
+	c.mu.Lock()
+	c.targeted = append(c.targeted, targetedMessage{players: players, excluded: excluded, message: decoded})
+	c.mu.Unlock()
+}
+
+// targetedOfType returns the targeted messages of one type, in order
+func (c *capturedMessages) targetedOfType(msgType string) []targetedMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var sent []targetedMessage
+	for _, message := range c.targeted {
+		if message.message["type"] == msgType {
+			sent = append(sent, message)
+		}
+	}
+	return sent
+}
+
+func (c *capturedMessages) add(message []byte) {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(message, &decoded); err != nil {
+		return
+	}
+	c.mu.Lock()
+	c.messages = append(c.messages, decoded)
+	c.mu.Unlock()
+}
+
+func (c *capturedMessages) types() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make([]string, 0, len(c.messages))
+	for _, msg := range c.messages {
+		if msgType, ok := msg["type"].(string); ok {
+			result = append(result, msgType)
+		}
+	}
+	return result
+}
+
+// reportedEvidence returns the evidence of the violation report a player got
+func reportedEvidence(t *testing.T, captured *capturedMessages, playerID string) map[string]interface{} {
+	for _, sent := range captured.targetedOfType("VIOLATION_REPORTED") {
+		if containsPlayer(sent.players, playerID) == sent.excluded {
+			continue
+		}
+		violation := sent.message["payload"].(map[string]interface{})["violation"].(map[string]interface{})
+		evidence, _ := violation["evidence"].(map[string]interface{})
+		return evidence
+	}
+	t.Fatalf("%s got no violation report", playerID)
+	return nil
+}
+
+func containsType(types []string, msgType string) bool {
+	for _, t := range types {
+		if t == msgType {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestWordService(t *testing.T) *services.WordService {
+	card := []string{"Crème Brûlée", "dessert", "custard", "burnt", "1", "food", "burnt cream"}
+	return newWordServiceOf(t, card, card)
+}
+
+// newWordServiceOf builds a word service over one common and one domain card
+func newWordServiceOf(t *testing.T, common, domain []string) *services.WordService {
+	dir := t.TempDir()
+	for file, card := range map[string][]string{"common_words.csv": common, "domain_words.csv": domain} {
+		f, err := os.Create(filepath.Join(dir, file))
+		require.NoError(t, err)
+
+		w := csv.NewWriter(f)
+		w.Write([]string{"target", "taboo1", "taboo2", "taboo3", "difficulty", "category", "alternates"})
+		w.Write(card)
+		w.Flush()
+		f.Close()
+	}
+
+	ws, err := services.NewWordService(dir)
+	require.NoError(t, err)
+	return ws
+}
+
+// testMatch is the match a test starts from, with the service keeping it
+type testMatch struct {
+	*models.MatchDetails
+	matches *services.MatchService
+}
+
+// current returns the match as the service has it now
+func (m *testMatch) current(t *testing.T) *models.MatchDetails {
+	match, err := m.matches.GetMatch(m.GameID, m.ID)
+	require.NoError(t, err)
+	return match
+}
+
+// update changes the match the service has
+func (m *testMatch) update(t *testing.T, change func(match *models.MatchDetails)) {
+	match := m.current(t)
+	change(match)
+	require.NoError(t, m.matches.StoreMatch(match))
+}
+
+func setupGameEventsService(t *testing.T) (*services.GameEventsService, *testMatch, *capturedMessages) {
+	return setupGameEventsServiceWithRules(t, models.GameRules{})
+}
+
+func setupGameEventsServiceWithRules(t *testing.T, rules models.GameRules) (*services.GameEventsService, *testMatch, *capturedMessages) {
+	captured := &capturedMessages{}
+	mockWSManager := &mocks.MockWebSocketManager{
+		SendToGameFunc: func(gameID string, message []byte) {
+			captured.add(message)
+		},
+		SendToPlayersFunc: func(gameID string, playerIDs []string, message []byte) {
+			captured.addTargeted(playerIDs, false, message)
+		},
+		SendToGameExceptFunc: func(gameID string, excludedPlayerIDs []string, message []byte) {
+			captured.addTargeted(excludedPlayerIDs, true, message)
+		},
+	}
+	mockGameService := &mocks.MockGameService{
+		GetGameFunc: func(gameID string) (*models.Game, error) {
+			return &models.Game{ID: gameID, Rules: rules, HostID: "a1", Teams: []models.Team{
+				{ID: "team-1", Players: []models.Player{{ID: "a1"}, {ID: "a2"}, {ID: "a3"}}},
+				{ID: "team-2", Players: []models.Player{{ID: "b1"}, {ID: "b2"}, {ID: "b3"}, {ID: "b4"}}},
+			}}, nil
+		},
+	}
+
+	ms := services.NewMatchService(mockGameService, mockWSManager)
+	match := &models.MatchDetails{
+		ID:           "test-match",
+		GameID:       "test-game",
+		Status:       models.MatchStatusPending,
+		TeamAPlayers: []string{"a1", "a2", "a3"},
+		TeamBPlayers: []string{"b1", "b2", "b3", "b4"},
+		CurrentStage: &models.MatchStage{
+			ID:             "test-stage",
+			MatchID:        "test-match",
+			Number:         1,
+			ActiveTeamID:   "teamA",
+			SpottingTeamID: "teamB",
+			ClueGivers:     []string{"a1", "a2"},
+			Guessers:       []string{"a3"},
+			Spotters:       []string{"b1", "b2"},
+			Status:         "active",
+		},
+	}
+	require.NoError(t, ms.StoreMatch(match))
+
+	ges := services.NewGameEventsService(ms, newTestWordService(t), mockWSManager)
+	require.NoError(t, ges.StartStage(match.GameID, "a1", 1))
+
+	return ges, &testMatch{MatchDetails: match, matches: ms}, captured
+}
+
+func TestStartStageRedactsCard(t *testing.T) {
+	_, match, captured := setupGameEventsService(t)
+
+	started := captured.targetedOfType("START_STAGE")
+	require.Len(t, started, 2)
+
+	for _, sent := range started {
+		assert.ElementsMatch(t, []string{"a1", "a2", "b1", "b2"}, sent.players)
+
+		payload := sent.message["payload"].(map[string]interface{})
+		_, hasCard := payload["wordCard"]
+		if sent.excluded {
+			assert.False(t, hasCard, "guessers and spectators must not get the card")
+		} else {
+			assert.True(t, hasCard, "clue-givers and spotters get the card")
+		}
+	}
+
+	current := match.current(t)
+	assert.NotEmpty(t, current.CurrentWord)
+	assert.Empty(t, current.RedactedFor("a3").CurrentWord)
+	assert.Empty(t, current.RedactedFor("spectator").CurrentWord)
+	assert.Equal(t, current.CurrentWord, current.RedactedFor("a1").CurrentWord)
+	assert.Equal(t, current.CurrentWord, current.RedactedFor("b2").CurrentWord)
+}
+
+func TestStartStageChecksSender(t *testing.T) {
+	ges, match, _ := setupGameEventsService(t)
+
+	assert.Error(t, ges.StartStage(match.GameID, "a3", 1), "guessers cannot start the stage")
+	assert.Error(t, ges.StartStage(match.GameID, "b1", 1), "spotters cannot start the stage")
+	assert.Error(t, ges.StartStage(match.GameID, "a2", 1), "a stage is already running")
+}
+
+func TestViolationWorkflow(t *testing.T) {
+	t.Run("only spotters can report", func(t *testing.T) {
+		ges, match, _ := setupGameEventsService(t)
+
+		_, err := ges.ReportViolation(match.GameID, "a3", "taboo_word", "")
+		assert.Error(t, err)
+		assert.Equal(t, "only spotters can report violations", err.Error())
+	})
+
+	t.Run("undisputed call is upheld when the window closes", func(t *testing.T) {
+		ges, match, captured := setupGameEventsService(t)
+		ges.SetDisputeWindow(20 * time.Millisecond)
+
+		violation, err := ges.ReportViolation(match.GameID, "b1", "taboo_word", "")
+		require.NoError(t, err)
+		assert.Equal(t, models.ViolationStatusPending, violation.Status)
+		assert.Equal(t, 0, match.current(t).TeamBScore, "no points before the call is resolved")
+
+		// The resolution is broadcast after points have been awarded
+		assert.Eventually(t, func() bool {
+			return containsType(captured.types(), "VIOLATION_RESOLVED")
+		}, time.Second, 5*time.Millisecond)
+		assert.Equal(t, models.ViolationStatusUpheld, ges.GetViolations(match.GameID)[0].Status)
+		assert.Equal(t, models.PointsViolationCatch, match.current(t).TeamBScore)
+		assert.Contains(t, captured.types(), "VIOLATION_REPORTED")
+	})
+
+	t.Run("disputed call is settled by majority vote", func(t *testing.T) {
+		ges, match, captured := setupGameEventsService(t)
+
+		violation, err := ges.ReportViolation(match.GameID, "b1", "partial_word", "")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"b3", "b4"}, violation.EligibleVoters)
+
+		_, err = ges.DisputeViolation(match.GameID, violation.ID, "b2")
+		assert.Error(t, err, "spotting team cannot dispute")
+		_, err = ges.DisputeViolation(match.GameID, violation.ID, "a3")
+		assert.Error(t, err, "guessers cannot dispute")
+
+		violation, err = ges.DisputeViolation(match.GameID, violation.ID, "a1")
+		require.NoError(t, err)
+		assert.Equal(t, models.ViolationStatusDisputed, violation.Status)
+
+		for _, playerID := range []string{"a1", "a3", "b1"} {
+			_, err = ges.VoteOnViolation(match.GameID, violation.ID, playerID, false)
+			assert.Error(t, err, "%s is involved and cannot vote", playerID)
+		}
+
+		violation, err = ges.VoteOnViolation(match.GameID, violation.ID, "b3", true)
+		require.NoError(t, err)
+		assert.Equal(t, models.ViolationStatusDisputed, violation.Status)
+
+		violation, err = ges.VoteOnViolation(match.GameID, violation.ID, "b4", false)
+		require.NoError(t, err)
+		assert.Equal(t, models.ViolationStatusRejected, violation.Status)
+		assert.Equal(t, "vote", violation.ResolvedBy)
+		assert.Equal(t, 0, match.current(t).TeamBScore)
+		assert.Contains(t, captured.types(), "VIOLATION_DISPUTED")
+		assert.Contains(t, captured.types(), "VIOLATION_VOTE")
+	})
+
+	t.Run("referee resolves a disputed call", func(t *testing.T) {
+		ges, match, _ := setupGameEventsService(t)
+		require.NoError(t, ges.SetReferee(match.GameID, "a1", "b3"))
+
+		violation, err := ges.ReportViolation(match.GameID, "b2", "gesture", "")
+		require.NoError(t, err)
+		_, err = ges.DisputeViolation(match.GameID, violation.ID, "a2")
+		require.NoError(t, err)
+
+		_, err = ges.ResolveViolation(match.GameID, violation.ID, "b4", true)
+		assert.Error(t, err)
+
+		violation, err = ges.ResolveViolation(match.GameID, violation.ID, "b3", true)
+		require.NoError(t, err)
+		assert.Equal(t, models.ViolationStatusUpheld, violation.Status)
+		assert.Equal(t, models.PointsViolationCatch, match.current(t).TeamBScore)
+	})
+
+	t.Run("only the host appoints a referee who is not playing", func(t *testing.T) {
+		ges, match, _ := setupGameEventsService(t)
+		assert.Error(t, ges.SetReferee(match.GameID, "b3", "b3"), "not the host")
+		assert.Error(t, ges.SetReferee(match.GameID, "a1", "stranger"), "not in the game")
+		for _, playerID := range []string{"a1", "a3", "b1"} {
+			assert.Error(t, ges.SetReferee(match.GameID, "a1", playerID), "%s is playing in the stage", playerID)
+		}
+		assert.NoError(t, ges.SetReferee(match.GameID, "a1", "b4"))
+	})
+
+	t.Run("a call nobody can settle cannot be disputed", func(t *testing.T) {
+		ges, match, _ := setupGameEventsService(t)
+		match.update(t, func(match *models.MatchDetails) {
+			match.CurrentStage.Spotters = match.TeamBPlayers
+		})
+
+		violation, err := ges.ReportViolation(match.GameID, "b1", "gesture", "")
+		require.NoError(t, err)
+		assert.Empty(t, violation.EligibleVoters)
+		_, err = ges.DisputeViolation(match.GameID, violation.ID, "a1")
+		assert.Error(t, err)
+		assert.Equal(t, models.ViolationStatusPending, ges.GetViolations(match.GameID)[0].Status)
+	})
+
+	t.Run("an upheld call whose points cannot be awarded is not announced", func(t *testing.T) {
+		ges, match, captured := setupGameEventsService(t)
+		require.NoError(t, ges.SetReferee(match.GameID, "a1", "b3"))
+		violation, err := ges.ReportViolation(match.GameID, "b1", "gesture", "")
+		require.NoError(t, err)
+
+		match.update(t, func(match *models.MatchDetails) {
+			match.Status = models.MatchStatusCompleted
+		})
+		_, err = ges.ResolveViolation(match.GameID, violation.ID, "b3", true)
+		assert.Error(t, err)
+		assert.Equal(t, models.ViolationStatusPending, ges.GetViolations(match.GameID)[0].Status)
+		assert.NotContains(t, captured.types(), "VIOLATION_RESOLVED")
+	})
+}
+
+func TestRebuildViolationsFromEventLog(t *testing.T) {
+	store := services.NewMemoryEventStore()
+	games := services.NewGameServiceWithStore(store)
+	game, err := games.CreateGame(4)
+	require.NoError(t, err)
+	matches := services.NewMatchService(games, &mocks.MockWebSocketManager{})
+	matches.SetEventRecorder(games)
+	_, err = matches.StartMatch(game.ID, "match-1", map[string][]string{
+		"teamA": {"a1", "a2", "a3"},
+		"teamB": {"b1", "b2", "b3", "b4"},
+	})
+	require.NoError(t, err)
+	_, err = matches.CreateStage(game.ID, "match-1", models.MatchStageDetails{
+		ActiveTeamID:   "teamA",
+		SpottingTeamID: "teamB",
+		ClueGivers:     []string{"a1"},
+		Guessers:       []string{"a2", "a3"},
+		Spotters:       []string{"b1", "b2"},
+	})
+	require.NoError(t, err)
+
+	ges := services.NewGameEventsService(matches, newTestWordService(t), &mocks.MockWebSocketManager{})
+	require.NoError(t, ges.StartStage(game.ID, "a1", 1))
+	violation, err := ges.ReportViolation(game.ID, "b1", "gesture", "")
+	require.NoError(t, err)
+	_, err = ges.DisputeViolation(game.ID, violation.ID, "a1")
+	require.NoError(t, err)
+	_, err = ges.VoteOnViolation(game.ID, violation.ID, "b3", true)
+	require.NoError(t, err)
+
+	rebuilt := services.NewGameEventsService(matches, newTestWordService(t), &mocks.MockWebSocketManager{})
+	require.NoError(t, rebuilt.Rebuild(store))
+	violations := rebuilt.GetViolations(game.ID)
+	require.Len(t, violations, 1)
+	assert.Equal(t, violation.ID, violations[0].ID)
+	assert.Equal(t, models.ViolationStatusDisputed, violations[0].Status)
+	assert.Equal(t, "a1", violations[0].DisputedBy)
+	assert.Equal(t, map[string]bool{"b3": true}, violations[0].Votes)
+
+	_, err = rebuilt.VoteOnViolation(game.ID, violation.ID, "b3", false)
+	assert.Error(t, err, "a rebuilt vote still counts")
+	_, err = rebuilt.VoteOnViolation(game.ID, violation.ID, "b4", true)
+	require.NoError(t, err)
+	assert.Equal(t, models.ViolationStatusUpheld, rebuilt.GetViolations(game.ID)[0].Status)
+}
+
+func TestHandleGuess(t *testing.T) {
+	resultOf := func(captured *capturedMessages) map[string]interface{} {
+		captured.mu.Lock()
+		defer captured.mu.Unlock()
+		for i := len(captured.messages) - 1; i >= 0; i-- {
+			if captured.messages[i]["type"] == "GUESS_RESULT" {
+				return captured.messages[i]["payload"].(map[string]interface{})
+			}
+		}
+		return nil
+	}
+
+	t.Run("only guessers in the active stage can guess", func(t *testing.T) {
+		ges, match, _ := setupGameEventsService(t)
+		assert.Error(t, ges.HandleGuess(match.GameID, "a1", "creme brulee"), "clue-giver")
+		assert.Error(t, ges.HandleGuess(match.GameID, "b1", "creme brulee"), "spotter")
+		assert.Equal(t, 0, match.current(t).TeamAScore)
+	})
+
+	t.Run("normalised answers, alternates and small typos score", func(t *testing.T) {
+		for _, guess := range []string{"creme brulee", "  CRÈME   brûlées ", "burnt-cream", "creme brulle"} {
+			ges, match, captured := setupGameEventsService(t)
+			require.NoError(t, ges.HandleGuess(match.GameID, "a3", guess), guess)
+
+			result := resultOf(captured)
+			require.NotNil(t, result, guess)
+			assert.Equal(t, "correct", result["verdict"], guess)
+			assert.Equal(t, "Crème Brûlée", result["answer"], guess)
+			assert.Equal(t, models.PointsCorrectGuess, match.current(t).TeamAScore, guess)
+		}
+	})
+
+	t.Run("a correct guess draws the next card", func(t *testing.T) {
+		ges, match, captured := setupGameEventsService(t)
+		require.NoError(t, ges.HandleGuess(match.GameID, "a3", "creme brulee"))
+
+		captured.mu.Lock()
+		defer captured.mu.Unlock()
+		last := captured.targeted[len(captured.targeted)-1]
+		assert.Equal(t, "CARD_DRAWN", last.message["type"])
+	})
+
+	t.Run("near misses are hinted as close and wrong guesses score nothing", func(t *testing.T) {
+		ges, match, captured := setupGameEventsService(t)
+		require.NoError(t, ges.HandleGuess(match.GameID, "a3", "creamy brule"))
+		assert.Equal(t, "close", resultOf(captured)["verdict"])
+		_, revealed := resultOf(captured)["answer"]
+		assert.False(t, revealed)
+
+		require.NoError(t, ges.HandleGuess(match.GameID, "a3", "pancake"))
+		assert.Equal(t, "wrong", resultOf(captured)["verdict"])
+		assert.Equal(t, 0, match.current(t).TeamAScore)
+	})
+
+	t.Run("typo tolerance is configurable", func(t *testing.T) {
+		ges, match, captured := setupGameEventsServiceWithRules(t, models.GameRules{
+			Guessing: &models.GuessRules{MaxTypos: 0, CloseDistance: 2},
+		})
+		require.NoError(t, ges.HandleGuess(match.GameID, "a3", "creme brulle"))
+		assert.Equal(t, "close", resultOf(captured)["verdict"])
+	})
+}
+
+func TestHandleClue(t *testing.T) {
+	t.Run("only clue-givers in the active stage can give clues", func(t *testing.T) {
+		ges, match, _ := setupGameEventsService(t)
+		assert.Error(t, ges.HandleClue(match.GameID, "a3", "sweet"), "guesser")
+		assert.Error(t, ges.HandleClue(match.GameID, "b1", "sweet"), "spotter")
+		assert.Error(t, ges.HandleClue(match.GameID, "a1", "   "), "empty clue")
+	})
+
+	t.Run("clean clues are relayed to the team and the spotters", func(t *testing.T) {
+		ges, match, captured := setupGameEventsService(t)
+		require.NoError(t, ges.HandleClue(match.GameID, "a1", "a sweet French pudding"))
+
+		relayed := captured.targetedOfType("GIVE_CLUE")
+		require.Len(t, relayed, 1)
+		assert.ElementsMatch(t, []string{"a1", "a2", "a3", "b1", "b2"}, relayed[0].players)
+		assert.Empty(t, captured.targetedOfType("CLUE_FLAGGED"))
+		assert.Empty(t, ges.GetViolations(match.GameID))
+	})
+
+	t.Run("clear violations are called automatically and not relayed", func(t *testing.T) {
+		for clue, want := range map[string]models.ClueEvidence{
+			"eaten for DESSERT":     {ClueWord: "dessert", CardWord: "dessert", Match: models.ClueMatchExact},
+			"after dinner desserts": {ClueWord: "desserts", CardWord: "dessert", Match: models.ClueMatchStem},
+			"rich and custardy":     {ClueWord: "custardy", CardWord: "custard", Match: models.ClueMatchCompound},
+			"cus tard":              {ClueWord: "cus tard", CardWord: "custard", Match: models.ClueMatchCompound},
+			"the top is brule":      {ClueWord: "brule", CardWord: "Crème Brûlée", Match: models.ClueMatchPart},
+		} {
+			ges, match, captured := setupGameEventsService(t)
+			require.NoError(t, ges.HandleClue(match.GameID, "a1", clue), clue)
+
+			violations := ges.GetViolations(match.GameID)
+			require.Len(t, violations, 1, clue)
+			assert.True(t, violations[0].Automatic, clue)
+			assert.Equal(t, "teamA", violations[0].OffendingTeamID, clue)
+			assert.Nil(t, violations[0].Evidence, "the listing leaves the evidence out")
+
+			evidence := reportedEvidence(t, captured, "a1")
+			require.NotNil(t, evidence, clue)
+			assert.Equal(t, clue, evidence["clue"])
+			assert.Equal(t, want.ClueWord, evidence["clueWord"], clue)
+			assert.Equal(t, want.CardWord, evidence["cardWord"], clue)
+			assert.Equal(t, string(want.Match), evidence["match"], clue)
+			assert.NotEmpty(t, evidence["reason"], clue)
+			assert.Empty(t, captured.targetedOfType("GIVE_CLUE"), clue)
+		}
+	})
+
+	t.Run("guessers never receive the card word of a violation", func(t *testing.T) {
+		ges, match, captured := setupGameEventsService(t)
+		require.NoError(t, ges.HandleClue(match.GameID, "a1", "eaten for DESSERT"))
+
+		assert.NotNil(t, reportedEvidence(t, captured, "b1"), "spotters see the evidence")
+		assert.Nil(t, reportedEvidence(t, captured, "a3"))
+		for _, msg := range captured.messages {
+			if msg["type"] == "VIOLATION_REPORTED" {
+				assert.NotContains(t, msg["payload"].(map[string]interface{})["violation"], "evidence")
+			}
+		}
+	})
+
+	t.Run("borderline clues are relayed and flagged to the spotters", func(t *testing.T) {
+		ges, match, captured := setupGameEventsService(t)
+		require.NoError(t, ges.HandleClue(match.GameID, "a1", "not a desert"))
+
+		assert.Len(t, captured.targetedOfType("GIVE_CLUE"), 1)
+		flagged := captured.targetedOfType("CLUE_FLAGGED")
+		require.Len(t, flagged, 1)
+		assert.ElementsMatch(t, []string{"b1", "b2"}, flagged[0].players)
+		flags := flagsOf(flagged[0])
+		require.Len(t, flags, 1)
+		assert.Equal(t, "desert", flags[0]["clueWord"])
+		assert.Equal(t, "near", flags[0]["match"])
+		assert.Empty(t, ges.GetViolations(match.GameID))
+	})
+
+	t.Run("short or inner parts of card words are only flagged", func(t *testing.T) {
+		for clue, want := range map[string]models.ClueEvidence{
+			"burn it":            {ClueWord: "burn", CardWord: "burnt", Match: models.ClueMatchPart},
+			"supercustardy pies": {ClueWord: "supercustardy", CardWord: "custard", Match: models.ClueMatchCompound},
+		} {
+			ges, match, captured := setupGameEventsService(t)
+			require.NoError(t, ges.HandleClue(match.GameID, "a1", clue), clue)
+
+			assert.Empty(t, ges.GetViolations(match.GameID), clue)
+			assert.Len(t, captured.targetedOfType("GIVE_CLUE"), 1, clue)
+			var found bool
+			for _, flagged := range captured.targetedOfType("CLUE_FLAGGED") {
+				for _, flag := range flagsOf(flagged) {
+					found = found || (flag["clueWord"] == want.ClueWord && flag["cardWord"] == want.CardWord && flag["match"] == string(want.Match))
+				}
+			}
+			assert.True(t, found, clue)
+		}
+	})
+
+	t.Run("clues that sound like or rhyme with the card are flagged", func(t *testing.T) {
+		ges, match, captured := setupGameEventsService(t)
+		require.NoError(t, ges.HandleClue(match.GameID, "a1", "made with cream, from the stem of a vanilla pod"))
+
+		flagged := captured.targetedOfType("CLUE_FLAGGED")
+		require.Len(t, flagged, 1)
+		flags := flagsOf(flagged[0])
+		require.Len(t, flags, 2)
+		assert.Equal(t, "cream", flags[0]["clueWord"])
+		assert.Equal(t, "Crème Brûlée", flags[0]["cardWord"])
+		assert.Equal(t, "sounds_like", flags[0]["match"])
+		assert.Contains(t, flags[0]["reason"], "sounds like")
+		assert.Equal(t, "stem", flags[1]["clueWord"])
+		assert.Equal(t, "Crème Brûlée", flags[1]["cardWord"])
+		assert.Equal(t, "rhyme", flags[1]["match"])
+	})
+
+	t.Run("sounds-like sensitivity is configurable", func(t *testing.T) {
+		for sensitivity, want := range map[models.SoundsLikeSensitivity]int{
+			models.SoundsLikeOff:    0,
+			models.SoundsLikeLow:    1, // Same sound only
+			models.SoundsLikeMedium: 1,
+			models.SoundsLikeHigh:   2, // Also rhymes on the last syllable
+		} {
+			ges, match, captured := setupGameEventsServiceWithRules(t, models.GameRules{
+				Clues: &models.ClueRules{SoundsLike: sensitivity},
+			})
+			require.NoError(t, ges.HandleClue(match.GameID, "a1", "cream, as good as a concert"))
+
+			flags := 0
+			for _, flagged := range captured.targetedOfType("CLUE_FLAGGED") {
+				flags += len(flagsOf(flagged))
+			}
+			assert.Equal(t, want, flags, sensitivity)
+		}
+	})
+}
+
+// flagsOf returns the evidence of each flag in a CLUE_FLAGGED message
+func flagsOf(sent targetedMessage) []map[string]interface{} {
+	var evidence []map[string]interface{}
+	for _, flag := range sent.message["payload"].(map[string]interface{})["flags"].([]interface{}) {
+		evidence = append(evidence, flag.(map[string]interface{})["evidence"].(map[string]interface{}))
+	}
+	return evidence
+}
+
+func TestHandleTranscript(t *testing.T) {
+	t.Run("only clue-givers in the active stage can send transcripts", func(t *testing.T) {
+		ges, match, _ := setupGameEventsService(t)
+		assert.Error(t, ges.HandleTranscript(match.GameID, "a3", models.TranscriptChunk{Text: "sweet"}))
+		assert.Error(t, ges.HandleTranscript(match.GameID, "a1", models.TranscriptChunk{Text: " "}))
+	})
+
+	t.Run("transcripts are kept on the stage and checked without calling violations", func(t *testing.T) {
+		ges, match, captured := setupGameEventsService(t)
+		require.NoError(t, ges.HandleTranscript(match.GameID, "a1", models.TranscriptChunk{Text: "a French pudding", TimestampMS: 1000}))
+		require.NoError(t, ges.HandleTranscript(match.GameID, "a1", models.TranscriptChunk{Text: "it's a dessert with cream", TimestampMS: 4000, DurationMS: 1500}))
+
+		current := match.current(t)
+		transcript := current.CurrentStage.Transcript
+		require.Len(t, transcript, 2)
+		assert.Equal(t, "a1", transcript[0].PlayerID)
+		assert.Empty(t, transcript[0].Flags)
+		require.Len(t, transcript[1].Flags, 2)
+		assert.Equal(t, models.ClueMatchExact, transcript[1].Flags[0].Match)
+		assert.Equal(t, models.ClueMatchSoundsLike, transcript[1].Flags[1].Match)
+		assert.Empty(t, ges.GetViolations(match.GameID), "speech-to-text mistakes must not cost points")
+
+		flagged := captured.targetedOfType("CLUE_FLAGGED")
+		require.Len(t, flagged, 1)
+		assert.ElementsMatch(t, []string{"b1", "b2"}, flagged[0].players)
+		assert.Equal(t, float64(4000), flagged[0].message["payload"].(map[string]interface{})["timestampMs"])
+
+		summary := current.CurrentStage.Summary()
+		assert.Equal(t, current.CurrentStage.ID, summary.StageID)
+		assert.Len(t, summary.Transcript, 2)
+	})
+
+	t.Run("guessers do not see what the flags name", func(t *testing.T) {
+		ges, match, _ := setupGameEventsService(t)
+		require.NoError(t, ges.HandleTranscript(match.GameID, "a1", models.TranscriptChunk{Text: "a dessert"}))
+
+		current := match.current(t)
+		assert.NotEmpty(t, current.RedactedFor("b1").CurrentStage.Transcript[0].Flags)
+		assert.Empty(t, current.RedactedFor("a3").CurrentStage.Transcript[0].Flags)
+		assert.Equal(t, "a dessert", current.RedactedFor("a3").CurrentStage.Transcript[0].Text)
+		assert.NotEmpty(t, current.CurrentStage.Transcript[0].Flags, "redacting leaves the match alone")
+	})
+}
+
+func TestStageEnd(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		stageNumber int
+		ended       func(match *models.MatchDetails) bool
+	}{
+		{"an earlier stage is closed for the next one", 1, func(match *models.MatchDetails) bool {
+			return match.CurrentStage.Status == string(models.StageStatusCompleted) && match.Status != models.MatchStatusCompleted
+		}},
+		{"the last stage completes the match", 4, func(match *models.MatchDetails) bool {
+			return match.Status == models.MatchStatusCompleted
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			captured := &capturedMessages{}
+			mockWSManager := &mocks.MockWebSocketManager{
+				SendToGameFunc: func(gameID string, message []byte) {
+					captured.add(message)
+				},
+			}
+			mockGameService := &mocks.MockGameService{
+				GetGameFunc: func(gameID string) (*models.Game, error) {
+					return &models.Game{ID: gameID}, nil
+				},
+			}
+			ms := services.NewMatchService(mockGameService, mockWSManager)
+			ms.StoreMatch(&models.MatchDetails{
+				ID:           "test-match",
+				GameID:       "test-game",
+				Status:       models.MatchStatusPending,
+				TeamAPlayers: []string{"a1", "a2"},
+				TeamBPlayers: []string{"b1", "b2"},
+				CurrentStage: &models.MatchStage{ID: "test-stage", MatchID: "test-match", Number: tc.stageNumber, ClueGivers: []string{"a1"}, Status: "active"},
+			})
+
+			ges := services.NewGameEventsService(ms, newTestWordService(t), mockWSManager)
+			ges.SetStageDuration(20 * time.Millisecond)
+			require.NoError(t, ges.StartStage("test-game", "a1", tc.stageNumber))
+
+			assert.Eventually(t, func() bool {
+				match, err := ms.GetMatch("test-game", "test-match")
+				return err == nil && tc.ended(match)
+			}, time.Second, 5*time.Millisecond)
+			assert.True(t, containsType(captured.types(), "STAGE_END"))
+
+			// The ended stage takes no more play
+			_, err := ms.GetActiveMatch("test-game")
+			assert.Error(t, err)
+			assert.Error(t, ms.ProcessGuessAttempt("test-game", "test-match", &models.GuessAttempt{TeamID: "teamA"}))
+			snapshot, err := ges.Snapshot("test-game", "a1")
+			require.NoError(t, err)
+			assert.Nil(t, snapshot.WordCard, "the ended stage's card is dropped")
+		})
+	}
+}
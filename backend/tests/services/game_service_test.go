@@ -1,11 +1,14 @@
 package services
 
 import (
+	"fmt"
+	"sync"
 	"taboo-game/models"
 	"taboo-game/services"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGameService(t *testing.T) {
@@ -26,7 +29,7 @@ func TestGameService(t *testing.T) {
 		svc := services.NewGameService()
 		game, _ := svc.CreateGame(4)
 
-		player, err := svc.AddPlayer(game.ID, "TestPlayer")
+		player, err := svc.AddPlayer(game.ID, "TestPlayer", nil)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, player.ID)
@@ -37,17 +40,28 @@ func TestGameService(t *testing.T) {
 		assert.Len(t, updatedGame.Teams[0].Players, 1)
 	})
 
+	t.Run("First player is host", func(t *testing.T) {
+		svc := services.NewGameService()
+		game, _ := svc.CreateGame(4)
+
+		first, _ := svc.AddPlayer(game.ID, "First", nil)
+		svc.AddPlayer(game.ID, "Second", nil)
+
+		updatedGame, _ := svc.GetGame(game.ID)
+		assert.Equal(t, first.ID, updatedGame.HostID)
+	})
+
 	t.Run("StartGame", func(t *testing.T) {
 		svc := services.NewGameService()
 		game, _ := svc.CreateGame(2)
 
 		// Add players to fill teams
-		svc.AddPlayer(game.ID, "Player1")
-		svc.AddPlayer(game.ID, "Player2")
-		svc.AddPlayer(game.ID, "Player3")
-		svc.AddPlayer(game.ID, "Player4")
+		svc.AddPlayer(game.ID, "Player1", nil)
+		svc.AddPlayer(game.ID, "Player2", nil)
+		svc.AddPlayer(game.ID, "Player3", nil)
+		svc.AddPlayer(game.ID, "Player4", nil)
 
-		startedGame, err := svc.StartGame(game.ID)
+		startedGame, err := svc.StartGame(game.ID, nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, models.GameStatusInProgress, startedGame.Status)
@@ -59,13 +73,13 @@ func TestGameService(t *testing.T) {
 		game, _ := svc.CreateGame(2)
 
 		// Setup game with players and start it
-		svc.AddPlayer(game.ID, "Player1")
-		svc.AddPlayer(game.ID, "Player2")
-		svc.AddPlayer(game.ID, "Player3")
-		svc.AddPlayer(game.ID, "Player4")
-		svc.StartGame(game.ID)
+		svc.AddPlayer(game.ID, "Player1", nil)
+		svc.AddPlayer(game.ID, "Player2", nil)
+		svc.AddPlayer(game.ID, "Player3", nil)
+		svc.AddPlayer(game.ID, "Player4", nil)
+		svc.StartGame(game.ID, nil)
 
-		endedGame, err := svc.EndGame(game.ID)
+		endedGame, err := svc.EndGame(game.ID, nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, models.GameStatusCompleted, endedGame.Status)
@@ -81,3 +95,161 @@ func TestGameService(t *testing.T) {
 		assert.Equal(t, "game not found", err.Error())
 	})
 }
+
+func TestGameEventLog(t *testing.T) {
+	t.Run("RebuildFromFileStore", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := services.NewFileEventStore(dir)
+		assert.NoError(t, err)
+
+		svc := services.NewGameServiceWithStore(store)
+		game, _ := svc.CreateGame(2)
+		svc.AddPlayer(game.ID, "Player1", nil)
+		svc.AddPlayer(game.ID, "Player2", nil)
+		svc.AddPlayer(game.ID, "Player3", nil)
+		svc.AddPlayer(game.ID, "Player4", nil)
+		started, _ := svc.StartGame(game.ID, nil)
+
+		// A fresh service over the same directory simulates a restart
+		reopened, err := services.NewFileEventStore(dir)
+		assert.NoError(t, err)
+		restarted := services.NewGameServiceWithStore(reopened)
+		assert.NoError(t, restarted.Rebuild())
+
+		rebuilt, err := restarted.GetGame(game.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.GameStatusInProgress, rebuilt.Status)
+		assert.Len(t, rebuilt.Matches, 3)
+		assert.Equal(t, started.Matches[0].ID, rebuilt.Matches[0].ID)
+		assert.Len(t, rebuilt.Teams[0].Players, 2)
+		assert.Len(t, rebuilt.Teams[1].Players, 2)
+	})
+
+	t.Run("ReplayStepByStep", func(t *testing.T) {
+		svc := services.NewGameService()
+		game, _ := svc.CreateGame(4)
+		svc.AddPlayer(game.ID, "Player1", nil)
+		svc.AddPlayer(game.ID, "Player2", nil)
+
+		events, err := svc.Events(game.ID)
+		assert.NoError(t, err)
+		assert.Len(t, events, 3)
+		assert.Equal(t, models.DomainEventGameCreated, events[0].Type)
+		assert.Equal(t, 3, events[2].Version)
+
+		replayed, err := svc.ReplayGame(game.ID, 2)
+		assert.NoError(t, err)
+		assert.Len(t, replayed.Teams[0].Players, 1)
+
+		current, _ := svc.GetGame(game.ID)
+		assert.Len(t, current.Teams[0].Players, 2)
+	})
+
+	t.Run("ReplayKeepsStageEnds", func(t *testing.T) {
+		svc := services.NewGameService()
+		game, _ := svc.CreateGame(1)
+		svc.AddPlayer(game.ID, "Player1", nil)
+		svc.AddPlayer(game.ID, "Player2", nil)
+		started, err := svc.StartGame(game.ID, nil)
+		require.NoError(t, err)
+		matchID := started.Matches[0].ID
+		require.NoError(t, svc.RecordEvent(game.ID, models.DomainEventStageStarted, models.StageStartedData{Stage: models.MatchStage{ID: "stage-1", MatchID: matchID, Number: 1}}))
+		require.NoError(t, svc.RecordEvent(game.ID, models.DomainEventStageEnded, models.StageEndedData{MatchID: matchID, StageID: "stage-1"}))
+
+		events, err := svc.Events(game.ID)
+		require.NoError(t, err)
+		replayed, err := svc.ReplayGame(game.ID, len(events))
+		require.NoError(t, err)
+		stage := replayed.Matches[0].Stages[0]
+		assert.Equal(t, models.StageStatusCompleted, stage.Status)
+		assert.False(t, stage.EndedAt.IsZero())
+		beforeEnd, err := svc.ReplayGame(game.ID, len(events)-1)
+		require.NoError(t, err)
+		assert.Equal(t, models.StageStatusActive, beforeEnd.Matches[0].Stages[0].Status)
+	})
+}
+
+func TestGameVersions(t *testing.T) {
+	t.Run("each change moves the game to a new version", func(t *testing.T) {
+		svc := services.NewGameService()
+		game, _ := svc.CreateGame(4)
+		assert.Equal(t, 1, game.Version)
+
+		svc.AddPlayer(game.ID, "Player1", nil)
+		current, _ := svc.GetGame(game.ID)
+		assert.Equal(t, 2, current.Version)
+		assert.Equal(t, 1, game.Version, "returned games are copies")
+	})
+
+	t.Run("gameplay events leave the version alone", func(t *testing.T) {
+		svc := services.NewGameService()
+		game, _ := svc.CreateGame(4)
+		require.NoError(t, svc.RecordEvent(game.ID, models.DomainEventCardDrawn, models.CardDrawnData{Card: models.WordCard{ID: "card-1"}}))
+		require.NoError(t, svc.RecordEvent(game.ID, models.DomainEventGuessRecorded, models.GuessRecordedData{}))
+
+		current, _ := svc.GetGame(game.ID)
+		assert.Equal(t, 1, current.Version)
+		_, err := svc.AddPlayer(game.ID, "Player1", models.VersionMatch{1})
+		assert.NoError(t, err, "a client that read the game before the draw is still current")
+	})
+
+	t.Run("writes expecting another version are refused", func(t *testing.T) {
+		svc := services.NewGameService()
+		game, _ := svc.CreateGame(1)
+		_, err := svc.AddPlayer(game.ID, "Player1", models.VersionMatch{1})
+		require.NoError(t, err)
+		_, err = svc.AddPlayer(game.ID, "Player2", models.VersionMatch{1})
+		assert.ErrorIs(t, err, models.ErrVersionConflict)
+
+		_, err = svc.AddPlayer(game.ID, "Player2", models.VersionMatch{2})
+		require.NoError(t, err)
+		_, err = svc.StartGame(game.ID, models.VersionMatch{2})
+		assert.ErrorIs(t, err, models.ErrVersionConflict)
+		started, err := svc.StartGame(game.ID, models.VersionMatch{3})
+		require.NoError(t, err)
+		_, err = svc.EndGame(game.ID, models.VersionMatch{started.Version - 1})
+		assert.ErrorIs(t, err, models.ErrVersionConflict)
+	})
+
+	t.Run("updates from a stale copy are refused", func(t *testing.T) {
+		svc := services.NewGameService()
+		game, _ := svc.CreateGame(4)
+		stale, _ := svc.GetGame(game.ID)
+
+		fresh, _ := svc.GetGame(game.ID)
+		fresh.Rules.Guessing.MaxTypos = 2
+		assert.NoError(t, svc.UpdateGame(fresh))
+		assert.Equal(t, 2, fresh.Version)
+
+		stale.Rules.Guessing.MaxTypos = 0
+		assert.ErrorIs(t, svc.UpdateGame(stale), models.ErrVersionConflict)
+		current, _ := svc.GetGame(game.ID)
+		assert.Equal(t, 2, current.Rules.Guessing.MaxTypos)
+	})
+
+	t.Run("concurrent changes are serialised", func(t *testing.T) {
+		svc := services.NewGameService()
+		game, _ := svc.CreateGame(4)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				svc.AddPlayer(game.ID, fmt.Sprintf("Player%d", i), nil)
+			}(i)
+			go func() {
+				defer wg.Done()
+				if current, err := svc.GetGame(game.ID); err == nil {
+					_ = len(current.Teams[0].Players)
+				}
+			}()
+		}
+		wg.Wait()
+
+		current, _ := svc.GetGame(game.ID)
+		assert.Len(t, current.Teams[0].Players, 4)
+		assert.Len(t, current.Teams[1].Players, 4)
+		assert.Equal(t, 9, current.Version)
+	})
+}
@@ -1,12 +1,16 @@
 package services_test
 
 import (
+	"encoding/json"
+	"sync"
 	"taboo-game/models"
 	"taboo-game/services"
 	"taboo-game/tests/mocks"
+	"taboo-game/websocket"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func setupMatchService(t *testing.T) (*services.MatchService, *models.MatchDetails) {
@@ -15,6 +19,7 @@ func setupMatchService(t *testing.T) (*services.MatchService, *models.MatchDetai
 			return &models.Game{
 				ID:     "test-game",
 				Status: "in_progress",
+				HostID: "host",
 			}, nil
 		},
 	}
@@ -33,6 +38,13 @@ func setupMatchService(t *testing.T) (*services.MatchService, *models.MatchDetai
 	return ms, match
 }
 
+// storedMatch returns a match as the service has it now
+func storedMatch(t *testing.T, ms *services.MatchService, match *models.MatchDetails) *models.MatchDetails {
+	stored, err := ms.GetMatch(match.GameID, match.ID)
+	require.NoError(t, err)
+	return stored
+}
+
 func createTestMatch(t *testing.T) *models.MatchDetails {
 	return &models.MatchDetails{
 		ID:           "test-match",
@@ -137,8 +149,9 @@ func TestProcessGuessAttempt(t *testing.T) {
 
 	err := ms.ProcessGuessAttempt(match.GameID, match.ID, attempt)
 	assert.NoError(t, err)
-	assert.Equal(t, models.PointsCorrectGuess, match.CurrentStage.TeamAScore)
-	assert.Equal(t, models.PointsCorrectGuess, match.TeamAScore)
+	stored := storedMatch(t, ms, match)
+	assert.Equal(t, models.PointsCorrectGuess, stored.CurrentStage.TeamAScore)
+	assert.Equal(t, models.PointsCorrectGuess, stored.TeamAScore)
 
 	// Test violation scoring
 	attempt.Correct = false
@@ -147,8 +160,9 @@ func TestProcessGuessAttempt(t *testing.T) {
 	err = ms.ProcessGuessAttempt(match.GameID, match.ID, attempt)
 	assert.NoError(t, err)
 
-	assert.Equal(t, models.PointsViolationCatch, match.CurrentStage.TeamBScore)
-	assert.Equal(t, models.PointsViolationCatch, match.TeamBScore)
+	stored = storedMatch(t, ms, match)
+	assert.Equal(t, models.PointsViolationCatch, stored.CurrentStage.TeamBScore)
+	assert.Equal(t, models.PointsViolationCatch, stored.TeamBScore)
 }
 
 func TestFinalizeStageScores(t *testing.T) {
@@ -158,8 +172,9 @@ func TestFinalizeStageScores(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Team A has 3 players, should get base points
-	assert.Equal(t, models.BasePointsTeamOfThree, match.TeamAScore)
-	assert.Equal(t, models.BasePointsTeamOfThree, match.CurrentStage.TeamAScore)
+	stored := storedMatch(t, ms, match)
+	assert.Equal(t, models.BasePointsTeamOfThree, stored.TeamAScore)
+	assert.Equal(t, models.BasePointsTeamOfThree, stored.CurrentStage.TeamAScore)
 }
 
 func TestSwitchTeam(t *testing.T) {
@@ -199,3 +214,166 @@ func TestSwitchTeam(t *testing.T) {
 		assert.Contains(t, err.Error(), "player not found in any team")
 	})
 }
+
+func TestViolationCategoryScoring(t *testing.T) {
+	rules := models.DefaultGameRules()
+	rules.ViolationRules[models.ViolationTypePartial] = models.ViolationRule{SpotterReward: 2, OffenderPenalty: 1}
+
+	mockGameService := &mocks.MockGameService{
+		GetGameFunc: func(gameID string) (*models.Game, error) {
+			return &models.Game{ID: gameID, Rules: rules}, nil
+		},
+	}
+	ms := services.NewMatchService(mockGameService, &mocks.MockWebSocketManager{})
+	match := createTestMatch(t)
+	ms.StoreMatch(match)
+
+	attempt := &models.GuessAttempt{
+		Violation:     true,
+		ViolationType: models.ViolationTypePartial,
+		TeamID:        "teamA",
+		StageID:       match.CurrentStage.ID,
+	}
+	assert.NoError(t, ms.ProcessGuessAttempt(match.GameID, match.ID, attempt))
+	stored := storedMatch(t, ms, match)
+	assert.Equal(t, 2, stored.TeamBScore)
+	assert.Equal(t, -1, stored.TeamAScore)
+
+	attempt.ViolationType = models.ViolationTypeGesture
+	assert.NoError(t, ms.ProcessGuessAttempt(match.GameID, match.ID, attempt))
+	assert.Equal(t, 3, storedMatch(t, ms, match).TeamBScore)
+
+	attempt.ViolationType = "whistling"
+	assert.Error(t, ms.ProcessGuessAttempt(match.GameID, match.ID, attempt))
+
+	stored = storedMatch(t, ms, match)
+	assert.Equal(t, 1, stored.CurrentStage.ViolationCounts[models.ViolationTypePartial])
+	assert.Equal(t, 1, stored.CurrentStage.ViolationCounts[models.ViolationTypeGesture])
+
+	stats, err := ms.GetGameStats(match.GameID)
+	assert.NoError(t, err)
+	assert.Len(t, stats.Matches, 1)
+	assert.Equal(t, 1, stats.ViolationCounts[models.ViolationTypePartial])
+	assert.Equal(t, 3, stats.TeamBScore)
+}
+
+func TestScoringLedger(t *testing.T) {
+	var events []websocket.Message
+	mockWSManager := &mocks.MockWebSocketManager{
+		SendToGameFunc: func(gameID string, message []byte) {
+			var event websocket.Message
+			if err := json.Unmarshal(message, &event); err == nil {
+				events = append(events, event)
+			}
+		},
+	}
+	mockGameService := &mocks.MockGameService{
+		GetGameFunc: func(gameID string) (*models.Game, error) {
+			return &models.Game{ID: gameID, HostID: "host", Rules: models.DefaultGameRules()}, nil
+		},
+	}
+	ms := services.NewMatchService(mockGameService, mockWSManager)
+	match := createTestMatch(t)
+	ms.StoreMatch(match)
+
+	_, err := ms.ScorePoint(match.ID, "player1", true)
+	assert.Error(t, err, "only the host awards points")
+	_, err = ms.ScorePoint(match.ID, "host", true)
+	assert.NoError(t, err)
+	err = ms.ProcessGuessAttempt(match.GameID, match.ID, &models.GuessAttempt{Correct: true, TeamID: "teamA"})
+	assert.NoError(t, err)
+	err = ms.ProcessGuessAttempt(match.GameID, match.ID, &models.GuessAttempt{Violation: true, TeamID: "teamA"})
+	assert.NoError(t, err)
+
+	ledger := ms.GetScoreLedger(match.GameID)
+	assert.Len(t, ledger, 3)
+	for i, entry := range ledger {
+		assert.Equal(t, i+1, entry.Seq)
+		assert.Equal(t, match.CurrentStage.ID, entry.StageID)
+		assert.False(t, entry.Timestamp.IsZero())
+	}
+	assert.Equal(t, models.ScoreReasonHostAward, ledger[0].Reason)
+	assert.Equal(t, models.ScoreReasonCorrectGuess, ledger[1].Reason)
+	assert.Equal(t, models.ScoreReasonViolationCatch, ledger[2].Reason)
+
+	stored := storedMatch(t, ms, match)
+	assert.Equal(t, 2, stored.TeamAScore)
+	assert.Equal(t, 1, stored.TeamBScore)
+	assert.Equal(t, 2, stored.CurrentStage.TeamAScore)
+
+	// Both REST paths broadcast the same event format
+	assert.Len(t, events, 3)
+	for _, event := range events {
+		assert.Equal(t, websocket.ScoreUpdate, event.Type)
+		var payload websocket.ScoreUpdatePayload
+		assert.NoError(t, event.DecodePayload(&payload))
+		assert.Equal(t, match.ID, payload.MatchID)
+		assert.NotEmpty(t, payload.Entries)
+	}
+
+	stored.CurrentStage = nil
+	require.NoError(t, ms.StoreMatch(stored))
+	_, err = ms.ScorePoint(match.ID, "host", true)
+	assert.Error(t, err)
+}
+
+func TestRebuildMatchesFromEventLog(t *testing.T) {
+	store := services.NewMemoryEventStore()
+	gameService := services.NewGameServiceWithStore(store)
+	game, _ := gameService.CreateGame(4)
+
+	ms := services.NewMatchService(gameService, &mocks.MockWebSocketManager{})
+	ms.SetEventRecorder(gameService)
+
+	teamAssignments := map[string][]string{
+		"teamA": {"player1", "player2", "player3"},
+		"teamB": {"player4", "player5", "player6", "player7"},
+	}
+	_, err := ms.StartMatch(game.ID, "match-1", teamAssignments)
+	assert.NoError(t, err)
+	stage, err := ms.CreateStage(game.ID, "match-1", models.MatchStageDetails{ActiveTeamID: "teamA", SpottingTeamID: "teamB"})
+	assert.NoError(t, err)
+	assert.NoError(t, ms.ProcessGuessAttempt(game.ID, "match-1", &models.GuessAttempt{Correct: true, TeamID: "teamA"}))
+	_, err = ms.SwitchTeam("match-1", "player7")
+	assert.NoError(t, err)
+	_, err = ms.ChangeTurn("match-1")
+	assert.NoError(t, err)
+	assert.NoError(t, ms.NextStage(game.ID))
+
+	rebuilt := services.NewMatchService(gameService, &mocks.MockWebSocketManager{})
+	assert.NoError(t, rebuilt.Rebuild(store))
+
+	match, err := rebuilt.GetMatch(game.ID, "match-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, match.TeamAScore)
+	assert.Equal(t, stage.ID, match.CurrentStage.ID)
+	assert.Equal(t, string(models.StageStatusCompleted), match.CurrentStage.Status, "the stage stays ended")
+	assert.False(t, match.TeamATurn, "the turn change is kept")
+	assert.True(t, containsPlayer(match.TeamAPlayers, "player7"))
+	assert.Len(t, rebuilt.GetScoreLedger(game.ID), 1)
+}
+
+func TestConcurrentScoring(t *testing.T) {
+	ms, match := setupMatchService(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			ms.ScorePoint(match.ID, "host", true)
+		}()
+		go func() {
+			defer wg.Done()
+			if current, err := ms.GetMatch(match.GameID, match.ID); err == nil {
+				_ = current.CurrentStage.TeamAScore
+			}
+		}()
+	}
+	wg.Wait()
+
+	current, err := ms.GetMatch(match.GameID, match.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 20, current.TeamAScore)
+	assert.Equal(t, 20, current.CurrentStage.TeamAScore)
+}
@@ -0,0 +1,226 @@
+package services_test
+
+import (
+	"encoding/json"
+	"os"
+	"taboo-game/models"
+	"taboo-game/services"
+	"taboo-game/tests/mocks"
+	"taboo-game/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recoveryServer is one run of the server's services over an event store
+type recoveryServer struct {
+	games    *services.GameService
+	matches  *services.MatchService
+	events   *services.GameEventsService
+	words    *services.WordService
+	recovery *services.RecoveryService
+	captured *capturedMessages
+}
+
+func newRecoveryServer(t *testing.T, store types.EventStoreInterface, snapshotDir string) *recoveryServer {
+	server := &recoveryServer{captured: &capturedMessages{}}
+	wsManager := &mocks.MockWebSocketManager{
+		SendToGameFunc: func(gameID string, message []byte) {
+			server.captured.add(message)
+		},
+	}
+
+	server.games = services.NewGameServiceWithStore(store)
+	require.NoError(t, server.games.Rebuild())
+	server.matches = services.NewMatchService(server.games, wsManager)
+	server.matches.SetEventRecorder(server.games)
+	require.NoError(t, server.matches.Rebuild(store))
+	server.words = newWordServiceOf(t,
+		[]string{"Crème Brûlée", "dessert", "custard", "burnt", "1", "food"},
+		[]string{"Lighthouse", "coast", "beam", "ships", "1", "places"})
+	server.events = services.NewGameEventsService(server.matches, server.words, wsManager)
+
+	recovery, err := services.NewRecoveryService(snapshotDir, server.games, server.events)
+	require.NoError(t, err)
+	server.recovery = recovery
+	return server
+}
+
+// startStage creates a game with a match whose first stage is running and
+// has a point scored
+func (s *recoveryServer) startStage(t *testing.T) *models.Game {
+	game, err := s.games.CreateGame(2)
+	require.NoError(t, err)
+	_, err = s.matches.StartMatch(game.ID, "match-1", map[string][]string{
+		"teamA": {"a1", "a2"},
+		"teamB": {"b1", "b2"},
+	})
+	require.NoError(t, err)
+	_, err = s.matches.CreateStage(game.ID, "match-1", models.MatchStageDetails{
+		ActiveTeamID:   "teamA",
+		SpottingTeamID: "teamB",
+		ClueGivers:     []string{"a1"},
+		Guessers:       []string{"a2"},
+		Spotters:       []string{"b1", "b2"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.matches.ProcessGuessAttempt(game.ID, "match-1", &models.GuessAttempt{Correct: true, TeamID: "teamA"}))
+	require.NoError(t, s.events.StartStage(game.ID, "a1", 1))
+	return game
+}
+
+func TestRecovery(t *testing.T) {
+	t.Run("restores games the event log lost", func(t *testing.T) {
+		dir := t.TempDir()
+		before := newRecoveryServer(t, services.NewMemoryEventStore(), dir)
+		game := before.startStage(t)
+		current, err := before.events.Snapshot(game.ID, "a1")
+		require.NoError(t, err)
+		drawn := current.WordCard
+		require.NotNil(t, drawn)
+		assert.Nil(t, current.RestoredAt)
+		require.NoError(t, before.recovery.Save())
+
+		after := newRecoveryServer(t, services.NewMemoryEventStore(), dir)
+		restored, err := after.recovery.Restore()
+		require.NoError(t, err)
+		assert.Equal(t, []string{game.ID}, restored)
+
+		restoredGame, err := after.games.GetGame(game.ID)
+		require.NoError(t, err)
+		assert.Equal(t, game.Teams[0].ID, restoredGame.Teams[0].ID)
+		match, err := after.matches.GetMatch(game.ID, "match-1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, match.TeamAScore)
+		assert.Len(t, after.matches.GetScoreLedger(game.ID), 1)
+
+		snapshot, err := after.events.Snapshot(game.ID, "a1")
+		require.NoError(t, err)
+		require.NotNil(t, snapshot.RestoredAt)
+		assert.InDelta(t, 180, snapshot.Remaining, 2, "the stage timer resumes where it was")
+		require.NotNil(t, snapshot.WordCard)
+		assert.Equal(t, drawn.ID, snapshot.WordCard.ID)
+
+		next, err := after.words.GetNextCard()
+		require.NoError(t, err)
+		assert.NotEqual(t, drawn.TargetWord, next.TargetWord, "cards drawn before the restart stay out of the deck")
+	})
+
+	t.Run("keeps what the event log rebuilt when it is newer", func(t *testing.T) {
+		dir := t.TempDir()
+		store := services.NewMemoryEventStore()
+		before := newRecoveryServer(t, store, dir)
+		game := before.startStage(t)
+		require.NoError(t, before.recovery.Save())
+		require.NoError(t, before.matches.ProcessGuessAttempt(game.ID, "match-1", &models.GuessAttempt{Correct: true, TeamID: "teamB"}))
+
+		after := newRecoveryServer(t, store, dir)
+		restored, err := after.recovery.Restore()
+		require.NoError(t, err)
+		assert.Empty(t, restored, "the game was not taken from the snapshot")
+
+		assert.Len(t, after.matches.GetScoreLedger(game.ID), 2)
+		snapshot, err := after.events.Snapshot(game.ID, "b1")
+		require.NoError(t, err)
+		assert.Nil(t, snapshot.RestoredAt)
+		assert.InDelta(t, 180, snapshot.Remaining, 2, "the stage the log is still in resumes")
+	})
+
+	t.Run("does not resume a stage the event log has moved past", func(t *testing.T) {
+		dir := t.TempDir()
+		store := services.NewMemoryEventStore()
+		before := newRecoveryServer(t, store, dir)
+		game := before.startStage(t)
+		require.NoError(t, before.recovery.Save())
+		_, err := before.matches.CreateStage(game.ID, "match-1", models.MatchStageDetails{
+			ActiveTeamID:   "teamB",
+			SpottingTeamID: "teamA",
+			ClueGivers:     []string{"b1"},
+			Guessers:       []string{"b2"},
+			Spotters:       []string{"a1", "a2"},
+		})
+		require.NoError(t, err)
+
+		snapshot, err := before.recovery.Load()
+		require.NoError(t, err)
+		require.Len(t, snapshot.Stages, 1)
+		snapshot.Stages[0].EndTime = time.Now().Add(-time.Minute)
+		data, err := json.Marshal(snapshot)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(before.recovery.Path(), data, 0644))
+
+		after := newRecoveryServer(t, store, dir)
+		restored, err := after.recovery.Restore()
+		require.NoError(t, err)
+		assert.Empty(t, restored)
+		assert.Never(t, func() bool {
+			return containsType(after.captured.types(), "STAGE_END")
+		}, 100*time.Millisecond, 10*time.Millisecond, "the timer of the earlier stage does not end the next one")
+	})
+
+	t.Run("a stage that ran out while the server was down ends", func(t *testing.T) {
+		dir := t.TempDir()
+		before := newRecoveryServer(t, services.NewMemoryEventStore(), dir)
+		before.startStage(t)
+		require.NoError(t, before.recovery.Save())
+
+		snapshot, err := before.recovery.Load()
+		require.NoError(t, err)
+		require.Len(t, snapshot.Stages, 1)
+		snapshot.Stages[0].EndTime = time.Now().Add(-time.Minute)
+		data, err := json.Marshal(snapshot)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(before.recovery.Path(), data, 0644))
+
+		after := newRecoveryServer(t, services.NewMemoryEventStore(), dir)
+		_, err = after.recovery.Restore()
+		require.NoError(t, err)
+		assert.Eventually(t, func() bool {
+			return containsType(after.captured.types(), "STAGE_END")
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("the snapshot file is replaced whole", func(t *testing.T) {
+		dir := t.TempDir()
+		server := newRecoveryServer(t, services.NewMemoryEventStore(), dir)
+
+		restored, err := server.recovery.Restore()
+		require.NoError(t, err)
+		assert.Empty(t, restored, "nothing to restore before the first snapshot")
+
+		server.startStage(t)
+		require.NoError(t, server.recovery.Save())
+		require.NoError(t, server.recovery.Save())
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1, "no temporary files are left behind")
+		assert.Equal(t, "snapshot.json", entries[0].Name())
+
+		snapshot, err := server.recovery.Load()
+		require.NoError(t, err)
+		assert.Len(t, snapshot.Games, 1)
+		assert.Len(t, snapshot.Matches, 1)
+		assert.NotEmpty(t, snapshot.UsedCards)
+	})
+
+	t.Run("ended games are left out", func(t *testing.T) {
+		server := newRecoveryServer(t, services.NewMemoryEventStore(), t.TempDir())
+		game, err := server.games.CreateGame(1)
+		require.NoError(t, err)
+		for _, name := range []string{"Player1", "Player2"} {
+			_, err = server.games.AddPlayer(game.ID, name, nil)
+			require.NoError(t, err)
+		}
+		_, err = server.games.StartGame(game.ID, nil)
+		require.NoError(t, err)
+		_, err = server.games.EndGame(game.ID, nil)
+		require.NoError(t, err)
+
+		snapshot, err := server.recovery.Take()
+		require.NoError(t, err)
+		assert.Empty(t, snapshot.Games)
+	})
+}
@@ -0,0 +1,186 @@
+package services_test
+
+import (
+	"path/filepath"
+	"taboo-game/models"
+	"taboo-game/services"
+	"taboo-game/tests/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	t.Run("migrations are applied once", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "taboo.db")
+		store, err := services.OpenSQLiteStore(path)
+		require.NoError(t, err)
+		version, err := store.SchemaVersion()
+		require.NoError(t, err)
+		assert.Positive(t, version)
+		require.NoError(t, store.Close())
+
+		reopened, err := services.OpenSQLiteStore(path)
+		require.NoError(t, err)
+		defer reopened.Close()
+		again, err := reopened.SchemaVersion()
+		require.NoError(t, err)
+		assert.Equal(t, version, again)
+	})
+
+	t.Run("games and matches survive reopening", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "taboo.db")
+		store, err := services.OpenSQLiteStore(path)
+		require.NoError(t, err)
+
+		game := &models.Game{ID: "game-1", Status: models.GameStatusWaiting, Rules: models.DefaultGameRules()}
+		require.NoError(t, store.SaveGames(game))
+		require.NoError(t, store.SaveMatches(
+			&models.MatchDetails{ID: "match-2", GameID: "game-1", TeamAPlayers: []string{"a1"}},
+			&models.MatchDetails{ID: "match-1", GameID: "game-1", CurrentStage: &models.MatchStage{ID: "stage-1"}},
+		))
+		game.Status = models.GameStatusInProgress
+		require.NoError(t, store.SaveGames(game))
+		require.NoError(t, store.Close())
+
+		reopened, err := services.OpenSQLiteStore(path)
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		loaded, err := reopened.GetGame("game-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.GameStatusInProgress, loaded.Status)
+		assert.Equal(t, game.Rules, loaded.Rules)
+
+		matches, err := reopened.ListMatches()
+		require.NoError(t, err)
+		require.Len(t, matches, 2)
+		assert.Equal(t, "match-1", matches[0].ID)
+		assert.Equal(t, "stage-1", matches[0].CurrentStage.ID)
+		assert.Equal(t, []string{"a1"}, matches[1].TeamAPlayers)
+
+		_, err = reopened.GetMatch("missing")
+		assert.Error(t, err)
+	})
+
+	t.Run("events are numbered per game", func(t *testing.T) {
+		store, err := services.OpenSQLiteStore(filepath.Join(t.TempDir(), "taboo.db"))
+		require.NoError(t, err)
+		defer store.Close()
+
+		for _, gameID := range []string{"game-b", "game-a", "game-b"} {
+			event, err := services.NewDomainEvent(gameID, models.DomainEventRulesUpdated, models.RulesUpdatedData{})
+			require.NoError(t, err)
+			_, err = store.Append(event)
+			require.NoError(t, err)
+		}
+
+		events, err := store.Load("game-b")
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, 1, events[0].Version)
+		assert.Equal(t, 2, events[1].Version)
+		assert.Equal(t, models.DomainEventRulesUpdated, events[1].Type)
+
+		ids, err := store.GameIDs()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"game-a", "game-b"}, ids)
+	})
+}
+
+func TestServicesOverSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "taboo.db")
+	store, err := services.OpenSQLiteStore(path)
+	require.NoError(t, err)
+
+	gameService := services.NewGameServiceWithStore(store)
+	gameService.SetRepository(store)
+	game, err := gameService.CreateGame(2)
+	require.NoError(t, err)
+	host, err := gameService.AddPlayer(game.ID, "Player1", nil)
+	require.NoError(t, err)
+
+	matchService := services.NewMatchService(gameService, &mocks.MockWebSocketManager{})
+	matchService.SetEventRecorder(gameService)
+	matchService.SetRepository(store)
+	_, err = matchService.StartMatch(game.ID, "match-1", map[string][]string{
+		"teamA": {"player1", "player2"},
+		"teamB": {"player3", "player4"},
+	})
+	require.NoError(t, err)
+	_, err = matchService.CreateStage(game.ID, "match-1", models.MatchStageDetails{ActiveTeamID: "teamA", SpottingTeamID: "teamB"})
+	require.NoError(t, err)
+	_, err = matchService.ScorePoint("match-1", host.ID, true)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	// A fresh set of services over the same database simulates a deploy
+	reopened, err := services.OpenSQLiteStore(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	restartedGames := services.NewGameServiceWithStore(reopened)
+	restartedGames.SetRepository(reopened)
+	saved, err := restartedGames.GetGame(game.ID)
+	require.NoError(t, err, "saved games are readable before any replay")
+	assert.Len(t, saved.Teams[0].Players, 1)
+
+	require.NoError(t, restartedGames.Rebuild())
+	restartedMatches := services.NewMatchService(restartedGames, &mocks.MockWebSocketManager{})
+	restartedMatches.SetRepository(reopened)
+	require.NoError(t, restartedMatches.Rebuild(reopened))
+
+	match, err := restartedMatches.GetMatch(game.ID, "match-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, match.TeamAScore)
+	assert.NotNil(t, match.CurrentStage)
+	assert.Len(t, restartedMatches.GetScoreLedger(game.ID), 1)
+}
+
+func TestFailedWritesChangeNothing(t *testing.T) {
+	store, err := services.OpenSQLiteStore(filepath.Join(t.TempDir(), "taboo.db"))
+	require.NoError(t, err)
+
+	gameService := services.NewGameServiceWithStore(store)
+	gameService.SetRepository(store)
+	game, err := gameService.CreateGame(2)
+	require.NoError(t, err)
+	host, err := gameService.AddPlayer(game.ID, "Player1", nil)
+	require.NoError(t, err)
+
+	matchService := services.NewMatchService(gameService, &mocks.MockWebSocketManager{})
+	matchService.SetEventRecorder(gameService)
+	matchService.SetRepository(store)
+	_, err = matchService.StartMatch(game.ID, "match-1", map[string][]string{
+		"teamA": {"player1", "player2"},
+		"teamB": {"player3", "player4"},
+	})
+	require.NoError(t, err)
+	_, err = matchService.CreateStage(game.ID, "match-1", models.MatchStageDetails{ActiveTeamID: "teamA", SpottingTeamID: "teamB"})
+	require.NoError(t, err)
+	before, err := gameService.GetGame(game.ID)
+	require.NoError(t, err)
+
+	// Every write fails once the database is closed
+	require.NoError(t, store.Close())
+
+	_, err = gameService.AddPlayer(game.ID, "Player2", nil)
+	assert.Error(t, err)
+	after, err := gameService.GetGame(game.ID)
+	require.NoError(t, err)
+	assert.Equal(t, before.Version, after.Version)
+	assert.Len(t, after.Teams[0].Players, 1)
+
+	_, err = matchService.ScorePoint("match-1", host.ID, true)
+	assert.Error(t, err)
+	_, err = matchService.EndMatch(game.ID, "match-1")
+	assert.Error(t, err)
+
+	match, err := matchService.GetMatch(game.ID, "match-1")
+	require.NoError(t, err)
+	assert.Zero(t, match.TeamAScore)
+	assert.Zero(t, match.CurrentStage.TeamAScore)
+	assert.Equal(t, models.MatchStatusPending, match.Status)
+	assert.Empty(t, matchService.GetScoreLedger(game.ID))
+}
@@ -0,0 +1,60 @@
+package services_test
+
+import (
+	"encoding/json"
+	"taboo-game/models"
+	"taboo-game/websocket"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoiceRooms(t *testing.T) {
+	// Team A gives clues in the stage set up by the helper: a1 and a2 give
+	// clues, a3 guesses, b1 and b2 spot
+	ges, match, captured := setupGameEventsService(t)
+
+	roomsOf := func(playerID string) map[string]models.VoiceRoom {
+		rooms := make(map[string]models.VoiceRoom)
+		for _, sent := range captured.targetedOfType("VOICE_ROOMS") {
+			if sent.players[0] != playerID {
+				continue
+			}
+			data, _ := json.Marshal(sent.message["payload"])
+			var payload websocket.VoiceRoomsPayload
+			require.NoError(t, json.Unmarshal(data, &payload))
+			for _, room := range payload.Rooms {
+				rooms[room.ID] = room
+			}
+		}
+		return rooms
+	}
+
+	t.Run("stage start tells every player who they hear", func(t *testing.T) {
+		spotter := roomsOf("b1")
+		assert.ElementsMatch(t, []string{"a1", "a2", "a3", "b2", "b3", "b4"}, spotter[models.VoiceRoomGame].Hears)
+		assert.ElementsMatch(t, []string{"b2", "b3", "b4"}, spotter[models.VoiceRoomGame].HeardBy)
+
+		clueGiver := roomsOf("a1")
+		assert.ElementsMatch(t, []string{"a2", "a3"}, clueGiver[models.VoiceRoomGame].Hears)
+		assert.Empty(t, clueGiver["teamA"].Hears, "the clue-giving team's room is closed during the stage")
+		assert.NotContains(t, clueGiver, "teamB")
+	})
+
+	t.Run("signaling follows who can hear whom", func(t *testing.T) {
+		assert.NoError(t, ges.AuthorizeSignal(match.GameID, "b1", "a1", models.VoiceRoomGame), "spotters hear the clue-givers")
+		assert.NoError(t, ges.AuthorizeSignal(match.GameID, "b1", "b2", "teamB"))
+		assert.Error(t, ges.AuthorizeSignal(match.GameID, "a1", "a3", "teamA"))
+		assert.Error(t, ges.AuthorizeSignal(match.GameID, "a1", "b1", "teamB"))
+		assert.Error(t, ges.AuthorizeSignal(match.GameID, "a1", "stranger", models.VoiceRoomGame))
+	})
+
+	t.Run("rooms open up between stages", func(t *testing.T) {
+		match.update(t, func(match *models.MatchDetails) {
+			match.CurrentStage = nil
+		})
+		assert.NoError(t, ges.AuthorizeSignal(match.GameID, "a1", "a3", "teamA"))
+		assert.True(t, match.current(t).CanHear(models.VoiceRoomGame, "a1", "b3"))
+	})
+}
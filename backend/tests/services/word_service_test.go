@@ -41,8 +41,9 @@ func TestWordService(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, ws)
 
-	// Test getting unique cards
-	usedCards := make(map[string]bool)
+	// Test getting unique cards. Each word is in both files, so a full
+	// round of the deck deals it twice.
+	dealt := make(map[string]int)
 	expectedCards := len(testWords) * len(files)
 
 	for i := 0; i < expectedCards; i++ {
@@ -51,9 +52,17 @@ func TestWordService(t *testing.T) {
 		assert.NotNil(t, card)
 
 		t.Logf("Got card #%d: %s", i+1, card.ID)
-		assert.False(t, usedCards[card.ID], "Card should not be repeated")
-		usedCards[card.ID] = true
+		assert.NotContains(t, card.ID, ".csv", "Card IDs must not point back to the word lists")
+		dealt[card.TargetWord]++
 	}
 
-	assert.Equal(t, expectedCards, len(usedCards), "Should have received all unique cards")
+	for _, word := range testWords {
+		assert.Equal(t, len(files), dealt[word[0]], "Card should not be repeated")
+	}
+
+	first, err := ws.GetNextCard()
+	assert.NoError(t, err)
+	again, err := ws.GetNextCard()
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.ID, again.ID, "Every draw gets its own ID")
 }
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"taboo-game/helpers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeAnswer(t *testing.T) {
+	cases := map[string]string{
+		"  Crème   Brûlée! ": "creme brulee",
+		"Ice-Cream":          "ice cream",
+		"strawberries":       "strawberry",
+		"glasses":            "glass",
+		"boxes":              "box",
+		"houses":             "house",
+		"bus":                "bus",
+		"cats":               "cat",
+	}
+	for input, want := range cases {
+		assert.Equal(t, want, helpers.NormalizeAnswer(input), input)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, helpers.EditDistance("taboo", "taboo"))
+	assert.Equal(t, 1, helpers.EditDistance("taboo", "tabo"))
+	assert.Equal(t, 1, helpers.EditDistance("taboo", "tabou"))
+	assert.Equal(t, 3, helpers.EditDistance("kitten", "sitting"))
+	assert.Equal(t, 5, helpers.EditDistance("", "taboo"))
+}
+
+func TestStem(t *testing.T) {
+	for _, words := range [][]string{
+		{"run", "runs", "running", "runner"},
+		{"bake", "baked", "baker", "bakes"},
+		{"stop", "stopped", "stopping"},
+		{"quick", "quickly", "quickest"},
+	} {
+		for _, word := range words[1:] {
+			assert.Equal(t, helpers.Stem(words[0]), helpers.Stem(word), word)
+		}
+	}
+	assert.NotEqual(t, helpers.Stem("desert"), helpers.Stem("dessert"))
+
+	// Letters outside ASCII are compared whole, never byte by byte
+	assert.Equal(t, "abcက", helpers.Stem("abcကing"))
+	assert.Equal(t, "mø", helpers.Stem("møøing"))
+	for _, word := range helpers.Tokenize("Crème Brûlée brûléed") {
+		assert.True(t, utf8.ValidString(helpers.Stem(word)), word)
+	}
+}
+
+func TestDoubleMetaphone(t *testing.T) {
+	for _, pair := range [][2]string{
+		{"dessert", "desert"},
+		{"knight", "night"},
+		{"phone", "fone"},
+		{"write", "right"},
+		{"cream", "creme"},
+		{"cat", "kat"},
+	} {
+		first, _ := helpers.DoubleMetaphone(pair[0])
+		second, _ := helpers.DoubleMetaphone(pair[1])
+		assert.Equal(t, first, second, pair[0]+" / "+pair[1])
+	}
+
+	primary, alternate := helpers.DoubleMetaphone("smith")
+	assert.Equal(t, "SM0", primary)
+	assert.Equal(t, "XMT", alternate)
+}
+
+func TestRhymeKey(t *testing.T) {
+	assert.Equal(t, "ak", helpers.RhymeKey("cake", 1))
+	assert.Equal(t, helpers.RhymeKey("cake", 1), helpers.RhymeKey("bake", 1))
+	assert.Equal(t, helpers.RhymeKey("custard", 2), helpers.RhymeKey("mustard", 2))
+	assert.NotEqual(t, helpers.RhymeKey("custard", 2), helpers.RhymeKey("hard", 2))
+	assert.Equal(t, helpers.RhymeKey("custard", 1), helpers.RhymeKey("hard", 1))
+}
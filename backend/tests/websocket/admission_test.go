@@ -0,0 +1,61 @@
+package websocket
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	gorilla "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenAuthenticator accepts the token "valid" for player1 only
+type tokenAuthenticator struct{}
+
+func (tokenAuthenticator) Authenticate(r *http.Request, gameID, playerID string) (int, error) {
+	if r.URL.Query().Get("token") != "valid" {
+		return http.StatusUnauthorized, errors.New("session token is required")
+	}
+	if playerID != "player1" {
+		return http.StatusForbidden, errors.New("session token is for another player")
+	}
+	return http.StatusOK, nil
+}
+
+func TestAdmission(t *testing.T) {
+	server, manager, _, _ := setupTestServer(t)
+	defer server.Close()
+	defer manager.Stop()
+	manager.SetAuthenticator(tokenAuthenticator{})
+	manager.SetAllowedOrigins([]string{"https://taboo.example"})
+
+	dial := func(path, origin string) (*gorilla.Conn, int) {
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + path
+		conn, resp, err := gorilla.DefaultDialer.Dial(wsURL, header)
+		if err != nil {
+			require.NotNil(t, resp, "rejected before upgrading")
+			return nil, resp.StatusCode
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn, resp.StatusCode
+	}
+
+	_, status := dial("/ws/game1/player1", "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	_, status = dial("/ws/game1/player2?token=valid", "")
+	assert.Equal(t, http.StatusForbidden, status)
+
+	_, status = dial("/ws/game1/player1?token=valid", "https://evil.example")
+	assert.Equal(t, http.StatusForbidden, status)
+
+	conn, status := dial("/ws/game1/player1?token=valid", "https://taboo.example")
+	assert.NotNil(t, conn)
+	assert.Equal(t, http.StatusSwitchingProtocols, status)
+}
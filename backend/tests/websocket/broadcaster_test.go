@@ -0,0 +1,116 @@
+package websocket
+
+import (
+	"encoding/json"
+	"taboo-game/websocket"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startInstance runs a manager as one of several server instances sharing
+// a Redis
+func startInstance(t *testing.T, server *miniredis.Miniredis) *websocket.Manager {
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	manager := startManager(t)
+	require.NoError(t, manager.SetBroadcaster(websocket.NewRedisBroadcaster(client, "test:")))
+	return manager
+}
+
+func TestRedisBroadcaster(t *testing.T) {
+	t.Run("messages reach clients on every instance", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		instanceA := startInstance(t, server)
+		instanceB := startInstance(t, server)
+
+		onA := NewMockClient("a1", "game1")
+		onB := NewMockClient("b1", "game1")
+		instanceA.Register(onA)
+		instanceB.Register(onB)
+		waitForPresenceOn(t, instanceA, "a1")
+		waitForPresenceOn(t, instanceB, "b1")
+
+		instanceA.SendToGame("game1", message(websocket.TimerUpdate))
+		instanceB.SendToPlayers("game1", []string{"a1"}, message(websocket.StartStage))
+
+		fromA := drainFor(onA, 2)
+		fromB := drainFor(onB, 1)
+		require.Len(t, fromA, 2)
+		require.Len(t, fromB, 1)
+
+		// Both instances number the shared message the same way
+		assert.Equal(t, fromA[0]["seq"], fromB[0]["seq"])
+		assert.Equal(t, "START_STAGE", fromA[1]["type"])
+		assert.Equal(t, float64(2), fromA[1]["seq"])
+	})
+
+	t.Run("clients are resynced when a message cannot be published", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+		t.Cleanup(func() { client.Close() })
+		manager := startManager(t)
+		manager.SetSnapshotProvider(mockSnapshots{})
+		require.NoError(t, manager.SetBroadcaster(websocket.NewRedisBroadcaster(client, "test:")))
+
+		connected := NewMockClient("p1", "game1")
+		manager.Register(connected)
+		waitForPresenceOn(t, manager, "p1")
+		manager.SendToGame("game1", message(websocket.TimerUpdate))
+		require.Len(t, drainFor(connected, 1), 1)
+
+		server.Close()
+		manager.SendToGame("game1", message(websocket.StageEnd))
+		resynced := drainFor(connected, 1)
+		require.Len(t, resynced, 1)
+		assert.Equal(t, "SYNC_SNAPSHOT", resynced[0]["type"], "the message is not delivered with a number of its own")
+		assert.Equal(t, float64(1), resynced[0]["seq"])
+
+		// A client resuming from before the lost message needs a snapshot too
+		resuming := &resumingClient{MockClient: NewMockClient("p2", "game1"), lastSeq: 1}
+		manager.Register(resuming)
+		replayed := drainFor(resuming.MockClient, 1)
+		require.Len(t, replayed, 1)
+		assert.Equal(t, "SYNC_SNAPSHOT", replayed[0]["type"])
+	})
+
+	t.Run("local broadcaster is the default", func(t *testing.T) {
+		manager := startManager(t)
+		client := NewMockClient("p1", "game1")
+		manager.Register(client)
+		waitForPresenceOn(t, manager, "p1")
+
+		manager.SendToGame("game1", message(websocket.TimerUpdate))
+		assert.Len(t, drain(client), 1)
+	})
+}
+
+func waitForPresenceOn(t *testing.T, manager *websocket.Manager, playerID string) {
+	require.Eventually(t, func() bool {
+		_, ok := manager.PresenceState("game1", playerID)
+		return ok
+	}, time.Second, 5*time.Millisecond)
+}
+
+// drainFor waits up to a second for count messages
+func drainFor(client *MockClient, count int) []map[string]interface{} {
+	messages := make([]map[string]interface{}, 0, count)
+	deadline := time.NewTimer(time.Second)
+	defer deadline.Stop()
+	for len(messages) < count {
+		select {
+		case data := <-client.Send:
+			var msg map[string]interface{}
+			json.Unmarshal(data, &msg)
+			messages = append(messages, msg)
+		case <-deadline.C:
+			return messages
+		}
+	}
+	return messages
+}
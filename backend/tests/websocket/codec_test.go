@@ -0,0 +1,80 @@
+package websocket
+
+import (
+	"net/http"
+	"strings"
+	"taboo-game/websocket"
+	"testing"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestMessagePackEncoding(t *testing.T) {
+	server, manager, _, _ := setupTestServer(t)
+	defer server.Close()
+	defer manager.Stop()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/game1/player1"
+
+	dialer := gorilla.Dialer{Subprotocols: []string{"taboo.v1.msgpack"}, EnableCompression: true}
+	conn, resp, err := dialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, "taboo.v1.msgpack", conn.Subprotocol())
+	assert.Contains(t, resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+
+	t.Run("actions are decoded and broadcasts encoded from the payload structs", func(t *testing.T) {
+		frame, err := msgpack.Marshal(map[string]interface{}{
+			"v": 1, "type": "GIVE_CLUE", "gameId": "game1", "playerId": "player1",
+			"payload": map[string]interface{}{"clue": "fruit"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, conn.WriteMessage(gorilla.BinaryMessage, frame))
+
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		frameType, reply, err := conn.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, gorilla.BinaryMessage, frameType)
+
+		var msg map[string]interface{}
+		require.NoError(t, msgpack.Unmarshal(reply, &msg))
+		assert.Equal(t, "GIVE_CLUE", msg["type"])
+		assert.Equal(t, map[string]interface{}{"clue": "fruit"}, msg["payload"])
+		assert.NotZero(t, msg["seq"])
+	})
+
+	t.Run("errors are sent in the connection's encoding", func(t *testing.T) {
+		require.NoError(t, conn.WriteMessage(gorilla.BinaryMessage, []byte("not msgpack")))
+
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, reply, err := conn.ReadMessage()
+		require.NoError(t, err)
+
+		var msg struct {
+			Type    string                 `json:"type"`
+			Payload websocket.ErrorPayload `json:"payload"`
+		}
+		decoder := msgpack.NewDecoder(strings.NewReader(string(reply)))
+		decoder.SetCustomStructTag("json")
+		require.NoError(t, decoder.Decode(&msg))
+		assert.Equal(t, "ERROR", msg.Type)
+		assert.Equal(t, websocket.ErrorCodeInvalidMessage, msg.Payload.Code)
+	})
+
+	t.Run("unknown encodings are refused", func(t *testing.T) {
+		_, resp, err := gorilla.DefaultDialer.Dial(wsURL+"?encoding=xml", nil)
+		require.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestEventStreamIsJSONOnly(t *testing.T) {
+	server, _ := setupStreamServer(t)
+	resp, err := http.Get(server.URL + "/sse/game1?encoding=msgpack")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,134 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"taboo-game/websocket"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStreamServer(t *testing.T) (*httptest.Server, *websocket.Manager) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	manager := startManager(t)
+	router.GET("/sse/:gameId", func(c *gin.Context) {
+		manager.HandleEventStream(c.Writer, c.Request, c.Param("gameId"), "")
+	})
+	router.GET("/sse/:gameId/:playerId", func(c *gin.Context) {
+		manager.HandleEventStream(c.Writer, c.Request, c.Param("gameId"), c.Param("playerId"))
+	})
+	router.POST("/actions/:gameId/:playerId", func(c *gin.Context) {
+		manager.HandleAction(c.Writer, c.Request, c.Param("gameId"), c.Param("playerId"))
+	})
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, manager
+}
+
+// openStream returns a channel of the stream's "id|data" events
+func openStream(t *testing.T, url, lastEventID string) <-chan string {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := make(chan string, 16)
+	go func() {
+		defer resp.Body.Close()
+		scanner := bufio.NewScanner(resp.Body)
+		id, data := "", ""
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			case line == "" && data != "":
+				events <- id + "|" + data
+				id, data = "", ""
+			}
+		}
+	}()
+	return events
+}
+
+func nextEvent(t *testing.T, events <-chan string) string {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return ""
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	t.Run("streams broadcasts with sequence ids", func(t *testing.T) {
+		server, manager := setupStreamServer(t)
+		events := openStream(t, server.URL+"/sse/game1", "")
+
+		require.Eventually(t, func() bool {
+			manager.SendToGame("game1", message(websocket.TimerUpdate))
+			select {
+			case event := <-events:
+				return strings.Contains(event, "TIMER_UPDATE")
+			case <-time.After(20 * time.Millisecond):
+				return false
+			}
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("resumes from Last-Event-ID", func(t *testing.T) {
+		server, manager := setupStreamServer(t)
+		manager.SendToGame("game1", message(websocket.TimerUpdate))
+		manager.SendToGame("game1", message(websocket.StageEnd))
+
+		events := openStream(t, server.URL+"/sse/game1", "1")
+		event := nextEvent(t, events)
+		assert.True(t, strings.HasPrefix(event, "2|"), event)
+		assert.Contains(t, event, "STAGE_END")
+	})
+
+	t.Run("scoreboard does not get messages for players", func(t *testing.T) {
+		server, manager := setupStreamServer(t)
+		manager.SendToGame("game1", message(websocket.TimerUpdate))
+		manager.SendToPlayers("game1", []string{"a1"}, message(websocket.StartStage))
+		manager.SendToGameExcept("game1", []string{"a1"}, message(websocket.StageEnd))
+
+		scoreboard := openStream(t, server.URL+"/sse/game1", "1")
+		player := openStream(t, server.URL+"/sse/game1/a1", "1")
+		assert.Contains(t, nextEvent(t, scoreboard), "STAGE_END")
+		assert.Contains(t, nextEvent(t, player), "START_STAGE")
+	})
+
+	t.Run("actions are posted over REST", func(t *testing.T) {
+		server, _ := setupStreamServer(t)
+		post := func(body string) *http.Response {
+			resp, err := http.Post(server.URL+"/actions/game1/a1", "application/json", strings.NewReader(body))
+			require.NoError(t, err)
+			resp.Body.Close()
+			return resp
+		}
+
+		assert.Equal(t, http.StatusNoContent, post(`{"type":"START_STAGE","gameId":"game1","playerId":"a1","payload":{"stageNum":1}}`).StatusCode)
+		assert.Equal(t, http.StatusForbidden, post(`{"type":"START_STAGE","gameId":"game1","playerId":"b1","payload":{"stageNum":1}}`).StatusCode)
+		assert.Equal(t, http.StatusBadRequest, post(`{"type":"START_STAGE","gameId":"game1","playerId":"a1"}`).StatusCode)
+	})
+}
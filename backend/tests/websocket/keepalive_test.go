@@ -0,0 +1,102 @@
+package websocket
+
+import (
+	"strings"
+	"taboo-game/models"
+	"taboo-game/websocket"
+	"testing"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dialGame(t *testing.T, serverURL, path string) *gorilla.Conn {
+	wsURL := "ws" + strings.TrimPrefix(serverURL, "http") + path
+	conn, _, err := gorilla.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestConnectionCleanup(t *testing.T) {
+	t.Run("closed connection is unregistered", func(t *testing.T) {
+		server, manager, _, _ := setupTestServer(t)
+		defer server.Close()
+		defer manager.Stop()
+
+		conn := dialGame(t, server.URL, "/ws/game1/player1")
+		waitForPresence(t, manager, "player1", models.PresenceConnected)
+
+		conn.Close()
+		waitForPresence(t, manager, "player1", models.PresenceReconnecting)
+	})
+
+	t.Run("peer that stops answering pings is dropped", func(t *testing.T) {
+		server, manager, _, _ := setupTestServer(t)
+		defer server.Close()
+		defer manager.Stop()
+		manager.SetKeepalive(20*time.Millisecond, 100*time.Millisecond)
+
+		// Pongs are only sent while the peer reads, so this one never answers
+		dialGame(t, server.URL, "/ws/game1/player1")
+		waitForPresence(t, manager, "player1", models.PresenceConnected)
+		waitForPresence(t, manager, "player1", models.PresenceReconnecting)
+	})
+
+	t.Run("peer that answers pings stays connected", func(t *testing.T) {
+		server, manager, _, _ := setupTestServer(t)
+		defer server.Close()
+		defer manager.Stop()
+		manager.SetKeepalive(20*time.Millisecond, 100*time.Millisecond)
+
+		conn := dialGame(t, server.URL, "/ws/game1/player1")
+		go func() {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		time.Sleep(300 * time.Millisecond)
+		state, _ := manager.PresenceState("game1", "player1")
+		assert.Equal(t, models.PresenceConnected, state)
+	})
+}
+
+func TestSlowConsumer(t *testing.T) {
+	manager := startManager(t)
+
+	slow := &MockClient{ID: "slow", GameID: "game1", Send: make(chan []byte, 1)}
+	fast := NewMockClient("fast", "game1")
+	manager.Register(slow)
+	manager.Register(fast)
+	waitForPresence(t, manager, "slow", models.PresenceConnected)
+	waitForPresence(t, manager, "fast", models.PresenceConnected)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			manager.SendToGame("game1", message(websocket.TimerUpdate))
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sending blocked on a slow client")
+	}
+
+	state, _ := manager.PresenceState("game1", "slow")
+	assert.Equal(t, models.PresenceReconnecting, state, "slow client should be dropped")
+	timerUpdates := 0
+	for _, msg := range drain(fast) {
+		if msg["type"] == "TIMER_UPDATE" {
+			timerUpdates++
+		}
+	}
+	assert.Equal(t, 3, timerUpdates)
+}
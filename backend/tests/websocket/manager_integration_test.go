@@ -3,6 +3,7 @@ package websocket
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http/httptest"
 	"strings"
 	"taboo-game/websocket"
@@ -16,15 +17,15 @@ import (
 
 // MockGameEvents implements websocket.GameEventsHandler
 type MockGameEvents struct {
-	StartStageFunc      func(gameID string, stageNum int) error
+	StartStageFunc      func(gameID string, playerID string, stageNum int) error
 	HandleClueFunc      func(gameID string, playerID string, clue string) error
 	HandleGuessFunc     func(gameID string, playerID string, guess string) error
 	HandleViolationFunc func(gameID string, reporterID string, violationType string) error
 }
 
-func (m *MockGameEvents) StartStage(gameID string, stageNum int) error {
+func (m *MockGameEvents) StartStage(gameID string, playerID string, stageNum int) error {
 	if m.StartStageFunc != nil {
-		return m.StartStageFunc(gameID, stageNum)
+		return m.StartStageFunc(gameID, playerID, stageNum)
 	}
 	return nil
 }
@@ -55,12 +56,25 @@ func setupTestServer(t *testing.T) (*httptest.Server, *websocket.Manager, *MockG
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
+	// The mocks broadcast like the real game events service does
+	var manager *websocket.Manager
+	broadcast := func(msgType websocket.MessageType, gameID string, payload interface{}) {
+		manager.SendToGame(gameID, websocket.NewMessage(msgType, gameID, "", payload).Encode())
+	}
 	mockEvents := &MockGameEvents{
-		StartStageFunc: func(gameID string, stageNum int) error {
+		StartStageFunc: func(gameID string, playerID string, stageNum int) error {
+			broadcast(websocket.StartStage, gameID, websocket.StartStagePayload{StageNum: stageNum})
+			return nil
+		},
+		HandleClueFunc: func(gameID string, playerID string, clue string) error {
+			broadcast(websocket.GiveClue, gameID, websocket.GiveCluePayload{Clue: clue})
 			return nil
 		},
+		HandleGuessFunc: func(gameID string, playerID string, guess string) error {
+			return errors.New("not a guesser")
+		},
 	}
-	manager := websocket.NewManager(mockEvents)
+	manager = websocket.NewManager(mockEvents)
 
 	router.GET("/ws/:gameId/:playerId", func(c *gin.Context) {
 		gameID := c.Param("gameId")
@@ -107,14 +121,7 @@ func TestWebSocketManager_Integration(t *testing.T) {
 		defer conn.Close()
 
 		// Test sending a stage start message
-		message := websocket.Message{
-			Type:     websocket.StartStage,
-			GameID:   "game1",
-			PlayerID: "player1",
-			Payload: map[string]interface{}{
-				"stage_num": 1,
-			},
-		}
+		message := websocket.NewMessage(websocket.StartStage, "game1", "player1", websocket.StartStagePayload{StageNum: 1})
 
 		// Send message with timeout
 		writeCtx, writeCancel := context.WithTimeout(ctx, time.Second)
@@ -187,14 +194,7 @@ func TestWebSocketManager_Integration(t *testing.T) {
 		defer cancel()
 
 		// Send message from one client
-		message := websocket.Message{
-			Type:     websocket.GiveClue,
-			GameID:   "game1",
-			PlayerID: "player1",
-			Payload: map[string]interface{}{
-				"clue": "test clue",
-			},
-		}
+		message := websocket.NewMessage(websocket.GiveClue, "game1", "player1", websocket.GiveCluePayload{Clue: "test clue"})
 
 		data, _ := json.Marshal(message)
 		err := conn1.WriteMessage(gorilla.TextMessage, data)
@@ -227,4 +227,55 @@ func TestWebSocketManager_Integration(t *testing.T) {
 			assert.Equal(t, message.GameID, response.GameID)
 		}
 	})
+
+	t.Run("Invalid messages get typed errors", func(t *testing.T) {
+		server, manager, _, managerDone := setupTestServer(t)
+		defer func() {
+			server.Close()
+			manager.Stop()
+			<-managerDone
+		}()
+
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/game1/player1"
+		conn, _, err := gorilla.DefaultDialer.Dial(wsURL, nil)
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		cases := []struct {
+			name    string
+			message interface{}
+			code    string
+		}{
+			{"not JSON", "not json", websocket.ErrorCodeInvalidMessage},
+			{"impersonation", websocket.NewMessage(websocket.GiveClue, "game1", "player2", websocket.GiveCluePayload{Clue: "fruit"}), websocket.ErrorCodeIdentityMismatch},
+			{"other version", websocket.Message{Version: 99, Type: websocket.GiveClue, GameID: "game1", PlayerID: "player1"}, websocket.ErrorCodeVersionMismatch},
+			{"unknown type", websocket.Message{Type: "DANCE", GameID: "game1", PlayerID: "player1"}, websocket.ErrorCodeUnknownType},
+			{"missing payload", websocket.Message{Type: websocket.StartStage, GameID: "game1", PlayerID: "player1"}, websocket.ErrorCodeInvalidPayload},
+			{"server-only type", websocket.NewMessage(websocket.TimerUpdate, "game1", "player1", websocket.TimerUpdatePayload{}), websocket.ErrorCodeUnknownType},
+			{"rejected action", websocket.NewMessage(websocket.MakeGuess, "game1", "player1", websocket.MakeGuessPayload{Guess: "apple"}), websocket.ErrorCodeActionFailed},
+		}
+
+		for _, tc := range cases {
+			var data []byte
+			if text, ok := tc.message.(string); ok {
+				data = []byte(text)
+			} else {
+				data, _ = json.Marshal(tc.message)
+			}
+			assert.NoError(t, conn.WriteMessage(gorilla.TextMessage, data))
+
+			conn.SetReadDeadline(time.Now().Add(time.Second))
+			_, reply, err := conn.ReadMessage()
+			if !assert.NoError(t, err, tc.name) {
+				return
+			}
+
+			var response websocket.Message
+			assert.NoError(t, json.Unmarshal(reply, &response), tc.name)
+			assert.Equal(t, websocket.ErrorMessage, response.Type, tc.name)
+			var payload websocket.ErrorPayload
+			assert.NoError(t, response.DecodePayload(&payload), tc.name)
+			assert.Equal(t, tc.code, payload.Code, tc.name)
+		}
+	})
 }
@@ -41,18 +41,27 @@ func (m *MockClient) GetGameID() string {
 func (m *MockClient) Read()  {}
 func (m *MockClient) Write() {}
 
+func (m *MockClient) Enqueue(message []byte) bool {
+	select {
+	case m.Send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
 // MockGameEventsHandler implements websocket.GameEventsHandler
 type MockGameEventsHandler struct {
-	StartStageFunc      func(gameID string, stageNum int) error
+	StartStageFunc      func(gameID string, playerID string, stageNum int) error
 	HandleClueFunc      func(gameID string, playerID string, clue string) error
 	HandleGuessFunc     func(gameID string, playerID string, guess string) error
 	HandleViolationFunc func(gameID string, reporterID string, violationType string) error
 }
 
 // Implement all interface methods
-func (h *MockGameEventsHandler) StartStage(gameID string, stageNum int) error {
+func (h *MockGameEventsHandler) StartStage(gameID string, playerID string, stageNum int) error {
 	if h.StartStageFunc != nil {
-		return h.StartStageFunc(gameID, stageNum)
+		return h.StartStageFunc(gameID, playerID, stageNum)
 	}
 	return nil
 }
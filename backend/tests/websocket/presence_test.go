@@ -0,0 +1,115 @@
+package websocket
+
+import (
+	"sync"
+	"taboo-game/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type presenceRecorder struct {
+	mu         sync.Mutex
+	events     []models.PresenceEvent
+	spectators int
+}
+
+func (r *presenceRecorder) SpectatorsChanged(gameID string, count int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spectators = count
+}
+
+func (r *presenceRecorder) spectatorCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.spectators
+}
+
+func (r *presenceRecorder) PresenceChanged(event models.PresenceEvent, presence models.PlayerPresence) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *presenceRecorder) recorded() []models.PresenceEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.PresenceEvent(nil), r.events...)
+}
+
+func TestPresence(t *testing.T) {
+	t.Run("one player with two tabs is one entry", func(t *testing.T) {
+		manager := startManager(t)
+		tab1 := NewMockClient("p1", "game1")
+		tab2 := NewMockClient("p1", "game1")
+		manager.Register(tab1)
+		manager.Register(tab2)
+
+		assert.Eventually(t, func() bool {
+			presence := manager.GamePresence("game1")
+			return len(presence) == 1 && presence[0].Connections == 2
+		}, time.Second, 5*time.Millisecond)
+
+		// Closing one tab keeps the player connected
+		manager.Unregister(tab1)
+		assert.Eventually(t, func() bool {
+			return manager.GamePresence("game1")[0].Connections == 1
+		}, time.Second, 5*time.Millisecond)
+		waitForPresence(t, manager, "p1", models.PresenceConnected)
+	})
+
+	t.Run("changes are reported to the listener", func(t *testing.T) {
+		manager := startManager(t)
+		recorder := &presenceRecorder{}
+		manager.SetPresenceListener(recorder)
+		manager.SetGracePeriod(20 * time.Millisecond)
+
+		client := NewMockClient("p1", "game1")
+		manager.Register(client)
+		manager.Unregister(client)
+		reconnected := NewMockClient("p1", "game1")
+		manager.Register(reconnected)
+		manager.Unregister(reconnected)
+
+		assert.Eventually(t, func() bool {
+			return len(recorder.recorded()) == 5
+		}, time.Second, 5*time.Millisecond)
+		assert.Equal(t, []models.PresenceEvent{
+			models.PresenceJoined,
+			models.PresenceDisconnected,
+			models.PresenceReconnected,
+			models.PresenceDisconnected,
+			models.PresenceLeft,
+		}, recorder.recorded())
+	})
+
+	t.Run("silent player goes idle", func(t *testing.T) {
+		manager := startManager(t)
+		recorder := &presenceRecorder{}
+		manager.SetPresenceListener(recorder)
+		manager.SetIdleTimeout(20 * time.Millisecond)
+
+		manager.Register(NewMockClient("p1", "game1"))
+
+		assert.Eventually(t, func() bool {
+			presence := manager.GamePresence("game1")
+			return len(presence) == 1 && presence[0].Idle
+		}, time.Second, 5*time.Millisecond)
+		assert.Contains(t, recorder.recorded(), models.PresenceIdle)
+	})
+
+	t.Run("all present check", func(t *testing.T) {
+		manager := startManager(t)
+		manager.Register(NewMockClient("a1", "game1"))
+		manager.Register(NewMockClient("a2", "game1"))
+		waitForPresence(t, manager, "a1", models.PresenceConnected)
+		waitForPresence(t, manager, "a2", models.PresenceConnected)
+
+		assert.True(t, manager.AllPresent("game1", []string{"a1", "a2"}))
+		assert.False(t, manager.AllPresent("game1", []string{"a1", "a3"}))
+		require.False(t, manager.AllPresent("game2", []string{"a1"}))
+	})
+}
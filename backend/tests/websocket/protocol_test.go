@@ -0,0 +1,56 @@
+package websocket
+
+import (
+	"net/http"
+	"strings"
+	"taboo-game/websocket"
+	"testing"
+
+	gorilla "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionNegotiation(t *testing.T) {
+	server, manager, _, _ := setupTestServer(t)
+	defer server.Close()
+	defer manager.Stop()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/game1/player1"
+
+	t.Run("subprotocol", func(t *testing.T) {
+		dialer := gorilla.Dialer{Subprotocols: []string{"taboo.v9", websocket.Subprotocol(websocket.ProtocolVersion)}}
+		conn, _, err := dialer.Dial(wsURL, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		assert.Equal(t, "taboo.v1", conn.Subprotocol())
+	})
+
+	t.Run("unsupported subprotocol", func(t *testing.T) {
+		dialer := gorilla.Dialer{Subprotocols: []string{"taboo.v9"}}
+		_, resp, err := dialer.Dial(wsURL, nil)
+		require.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("query parameter", func(t *testing.T) {
+		conn, _, err := gorilla.DefaultDialer.Dial(wsURL+"?v=1", nil)
+		require.NoError(t, err)
+		conn.Close()
+
+		_, resp, err := gorilla.DefaultDialer.Dial(wsURL+"?v=9", nil)
+		require.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("messages carry the version", func(t *testing.T) {
+		conn := dialGame(t, server.URL, "/ws/game1/player1")
+		require.NoError(t, conn.WriteJSON(websocket.NewMessage(websocket.GiveClue, "game1", "player1", websocket.GiveCluePayload{Clue: "fruit"})))
+
+		var reply websocket.Message
+		require.NoError(t, conn.ReadJSON(&reply))
+		assert.Equal(t, websocket.ProtocolVersion, reply.Version)
+		var payload websocket.GiveCluePayload
+		require.NoError(t, reply.DecodePayload(&payload))
+		assert.Equal(t, "fruit", payload.Clue)
+	})
+}
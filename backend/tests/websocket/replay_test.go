@@ -0,0 +1,201 @@
+package websocket
+
+import (
+	"encoding/json"
+	"taboo-game/models"
+	"taboo-game/websocket"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resumingClient reconnects with the last sequence number it saw
+type resumingClient struct {
+	*MockClient
+	lastSeq int64
+}
+
+func (c *resumingClient) GetLastSeq() int64 {
+	return c.lastSeq
+}
+
+// rejectingClient has no room for its nth queued message
+type rejectingClient struct {
+	*resumingClient
+	reject   int
+	enqueued int
+}
+
+func (c *rejectingClient) Enqueue(message []byte) bool {
+	c.enqueued++
+	if c.enqueued == c.reject {
+		return false
+	}
+	return c.resumingClient.Enqueue(message)
+}
+
+type mockSnapshots struct{}
+
+func (mockSnapshots) Snapshot(gameID, playerID string) (*models.GameSnapshot, error) {
+	return &models.GameSnapshot{Game: &models.Game{ID: gameID}}, nil
+}
+
+func startManager(t *testing.T) *websocket.Manager {
+	manager := websocket.NewManager(&MockGameEventsHandler{})
+	go manager.Run()
+	t.Cleanup(manager.Stop)
+	return manager
+}
+
+func waitForPresence(t *testing.T, manager *websocket.Manager, playerID string, want models.PresenceState) {
+	assert.Eventually(t, func() bool {
+		state, _ := manager.PresenceState("game1", playerID)
+		return state == want
+	}, time.Second, 5*time.Millisecond, "player %s should be %s", playerID, want)
+}
+
+func drain(client *MockClient) []map[string]interface{} {
+	messages := make([]map[string]interface{}, 0)
+	for {
+		select {
+		case data := <-client.Send:
+			var msg map[string]interface{}
+			json.Unmarshal(data, &msg)
+			messages = append(messages, msg)
+		case <-time.After(50 * time.Millisecond):
+			return messages
+		}
+	}
+}
+
+func message(msgType websocket.MessageType) []byte {
+	data, _ := json.Marshal(websocket.Message{Type: msgType, GameID: "game1"})
+	return data
+}
+
+func TestSessionResume(t *testing.T) {
+	t.Run("replays only messages addressed to the player, with sequence numbers", func(t *testing.T) {
+		manager := startManager(t)
+		client := NewMockClient("p1", "game1")
+		manager.Register(client)
+		waitForPresence(t, manager, "p1", models.PresenceConnected)
+
+		manager.Unregister(client)
+		waitForPresence(t, manager, "p1", models.PresenceReconnecting)
+
+		manager.SendToGame("game1", message(websocket.TimerUpdate))
+		manager.SendToPlayers("game1", []string{"p2"}, message(websocket.StartStage))
+		manager.SendToGameExcept("game1", []string{"p2"}, message(websocket.StageEnd))
+
+		reconnected := NewMockClient("p1", "game1")
+		manager.Register(reconnected)
+
+		replayed := drain(reconnected)
+		require.Len(t, replayed, 2)
+		assert.Equal(t, "TIMER_UPDATE", replayed[0]["type"])
+		assert.Equal(t, "STAGE_END", replayed[1]["type"])
+		assert.Equal(t, replayed[0]["seq"].(float64)+2, replayed[1]["seq"], "the message to p2 is skipped")
+	})
+
+	t.Run("resumes after the last seen sequence number", func(t *testing.T) {
+		manager := startManager(t)
+		for i := 0; i < 3; i++ {
+			manager.SendToGame("game1", message(websocket.TimerUpdate))
+		}
+
+		client := &resumingClient{MockClient: NewMockClient("p1", "game1"), lastSeq: 2}
+		manager.Register(client)
+
+		replayed := drain(client.MockClient)
+		require.Len(t, replayed, 1)
+		assert.Equal(t, float64(3), replayed[0]["seq"])
+	})
+
+	t.Run("sends a snapshot when the gap is too large", func(t *testing.T) {
+		manager := startManager(t)
+		manager.SetReplayBufferSize(2)
+		manager.SetSnapshotProvider(mockSnapshots{})
+		for i := 0; i < 5; i++ {
+			manager.SendToGame("game1", message(websocket.TimerUpdate))
+		}
+
+		client := &resumingClient{MockClient: NewMockClient("p1", "game1"), lastSeq: 1}
+		manager.Register(client)
+
+		replayed := drain(client.MockClient)
+		require.Len(t, replayed, 1)
+		assert.Equal(t, "SYNC_SNAPSHOT", replayed[0]["type"])
+		assert.Equal(t, float64(5), replayed[0]["seq"])
+	})
+
+	t.Run("sends a snapshot when the replay does not fit", func(t *testing.T) {
+		manager := startManager(t)
+		manager.SetSnapshotProvider(mockSnapshots{})
+		for i := 0; i < 4; i++ {
+			manager.SendToGame("game1", message(websocket.TimerUpdate))
+		}
+
+		client := &rejectingClient{resumingClient: &resumingClient{MockClient: NewMockClient("p1", "game1"), lastSeq: 1}, reject: 2}
+		manager.Register(client)
+
+		replayed := drain(client.MockClient)
+		require.Len(t, replayed, 2)
+		assert.Equal(t, float64(2), replayed[0]["seq"])
+		assert.Equal(t, "SYNC_SNAPSHOT", replayed[1]["type"])
+		assert.Equal(t, float64(4), replayed[1]["seq"])
+		waitForPresence(t, manager, "p1", models.PresenceConnected)
+	})
+
+	t.Run("drops a client whose replay does not fit without snapshots", func(t *testing.T) {
+		manager := startManager(t)
+		for i := 0; i < 4; i++ {
+			manager.SendToGame("game1", message(websocket.TimerUpdate))
+		}
+
+		client := &resumingClient{MockClient: &MockClient{ID: "p1", GameID: "game1", Send: make(chan []byte, 1)}, lastSeq: 1}
+		manager.Register(client)
+
+		waitForPresence(t, manager, "p1", models.PresenceReconnecting)
+		replayed := drain(client.MockClient)
+		require.Len(t, replayed, 1, "it resumes after the last message it got")
+		assert.Equal(t, float64(2), replayed[0]["seq"])
+	})
+
+	t.Run("sends a snapshot to each player first connecting after a restore", func(t *testing.T) {
+		manager := startManager(t)
+		manager.SetSnapshotProvider(mockSnapshots{})
+		manager.GameRestored("game1")
+
+		client := &resumingClient{MockClient: NewMockClient("p1", "game1"), lastSeq: 40}
+		manager.Register(client)
+		replayed := drain(client.MockClient)
+		require.Len(t, replayed, 1)
+		assert.Equal(t, "SYNC_SNAPSHOT", replayed[0]["type"])
+
+		manager.SendToGame("game1", message(websocket.TimerUpdate))
+		drain(client.MockClient)
+		manager.Unregister(client)
+		waitForPresence(t, manager, "p1", models.PresenceReconnecting)
+
+		again := &resumingClient{MockClient: NewMockClient("p1", "game1"), lastSeq: 0}
+		manager.Register(again)
+		replayed = drain(again.MockClient)
+		require.Len(t, replayed, 1, "later reconnects replay as usual")
+		assert.Equal(t, "TIMER_UPDATE", replayed[0]["type"])
+	})
+
+	t.Run("player is gone after the grace period", func(t *testing.T) {
+		manager := startManager(t)
+		manager.SetGracePeriod(20 * time.Millisecond)
+
+		client := NewMockClient("p1", "game1")
+		manager.Register(client)
+		waitForPresence(t, manager, "p1", models.PresenceConnected)
+
+		manager.Unregister(client)
+		waitForPresence(t, manager, "p1", models.PresenceReconnecting)
+		waitForPresence(t, manager, "p1", models.PresenceGone)
+	})
+}
@@ -0,0 +1,95 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"taboo-game/websocket"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gorilla "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSpectatorServer(t *testing.T) (*httptest.Server, *websocket.Manager, *presenceRecorder) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	manager := startManager(t)
+	recorder := &presenceRecorder{}
+	manager.SetPresenceListener(recorder)
+	router.GET("/spectate/:gameId", func(c *gin.Context) {
+		manager.HandleSpectator(c.Writer, c.Request, c.Param("gameId"))
+	})
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, manager, recorder
+}
+
+func readMessage(t *testing.T, conn *gorilla.Conn, timeout time.Duration) map[string]interface{} {
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+
+	var msg map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &msg))
+	return msg
+}
+
+func TestSpectators(t *testing.T) {
+	t.Run("spectators cannot send actions", func(t *testing.T) {
+		server, _, _ := setupSpectatorServer(t)
+		conn := dialGame(t, server.URL, "/spectate/game1")
+
+		require.NoError(t, conn.WriteJSON(websocket.NewMessage(websocket.StartStage, "game1", "", websocket.StartStagePayload{StageNum: 1})))
+
+		msg := readMessage(t, conn, time.Second)
+		assert.Equal(t, "ERROR", msg["type"])
+		assert.Equal(t, websocket.ErrorCodeReadOnly, msg["payload"].(map[string]interface{})["code"])
+	})
+
+	t.Run("spectators only get what players without a role get", func(t *testing.T) {
+		server, manager, recorder := setupSpectatorServer(t)
+		conn := dialGame(t, server.URL, "/spectate/game1")
+		require.Eventually(t, func() bool {
+			return recorder.spectatorCount() == 1
+		}, time.Second, 5*time.Millisecond)
+		assert.Equal(t, 1, manager.SpectatorCount("game1"))
+
+		manager.SendToPlayers("game1", []string{"a1", "b1"}, message(websocket.StartStage))
+		manager.SendToGameExcept("game1", []string{"a1", "b1"}, message(websocket.StageEnd))
+
+		assert.Equal(t, "STAGE_END", readMessage(t, conn, time.Second)["type"])
+
+		conn.Close()
+		assert.Eventually(t, func() bool {
+			return recorder.spectatorCount() == 0
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("delayed feed", func(t *testing.T) {
+		server, manager, recorder := setupSpectatorServer(t)
+		conn := dialGame(t, server.URL, "/spectate/game1?delay=1")
+		require.Eventually(t, func() bool {
+			return recorder.spectatorCount() == 1
+		}, time.Second, 5*time.Millisecond)
+
+		sentAt := time.Now()
+		manager.SendToGame("game1", message(websocket.TimerUpdate))
+
+		assert.Equal(t, "TIMER_UPDATE", readMessage(t, conn, 2*time.Second)["type"])
+		assert.GreaterOrEqual(t, time.Since(sentAt), time.Second)
+	})
+}
+
+func TestSpectatorStream(t *testing.T) {
+	server, manager := setupStreamServer(t)
+	openStream(t, server.URL+"/sse/game1", "")
+
+	assert.Eventually(t, func() bool {
+		return manager.SpectatorCount("game1") == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Empty(t, manager.GamePresence("game1"), "spectators have no presence")
+}
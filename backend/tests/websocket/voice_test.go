@@ -0,0 +1,108 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"taboo-game/models"
+	"taboo-game/websocket"
+	"testing"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// voiceGameEvents lets a1 and b1 talk in the game room and nobody else
+type voiceGameEvents struct {
+	*MockGameEvents
+	manager *websocket.Manager
+}
+
+func (v *voiceGameEvents) SendVoiceRooms(gameID, playerID string) error {
+	msg := websocket.NewMessage(websocket.VoiceRooms, gameID, playerID, websocket.VoiceRoomsPayload{
+		Rooms: []models.VoiceRoom{{ID: models.VoiceRoomGame, Hears: []string{"b1"}, HeardBy: []string{"b1"}}},
+	})
+	v.manager.SendToPlayer(gameID, playerID, msg.Encode())
+	return nil
+}
+
+func (v *voiceGameEvents) AuthorizeSignal(gameID, fromID, toID, roomID string) error {
+	if roomID != models.VoiceRoomGame || fromID+toID != "a1b1" && fromID+toID != "b1a1" {
+		return errors.New("players cannot hear each other in this voice room")
+	}
+	return nil
+}
+
+// readType reads messages until one of the given type arrives
+func readType(t *testing.T, conn *gorilla.Conn, msgType websocket.MessageType) websocket.Message {
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	for {
+		_, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+		var msg websocket.Message
+		require.NoError(t, json.Unmarshal(data, &msg))
+		if msg.Type == msgType {
+			return msg
+		}
+	}
+}
+
+func TestVoiceSignaling(t *testing.T) {
+	server, manager, mockEvents, _ := setupTestServer(t)
+	defer server.Close()
+	defer manager.Stop()
+	manager.SetGameEvents(&voiceGameEvents{MockGameEvents: mockEvents, manager: manager})
+
+	a1 := dialGame(t, server.URL, "/ws/game1/a1")
+	b1 := dialGame(t, server.URL, "/ws/game1/b1")
+	waitForPresence(t, manager, "a1", models.PresenceConnected)
+	waitForPresence(t, manager, "b1", models.PresenceConnected)
+
+	send := func(conn *gorilla.Conn, msgType websocket.MessageType, playerID string, payload interface{}) {
+		require.NoError(t, conn.WriteMessage(gorilla.TextMessage, websocket.NewMessage(msgType, "game1", playerID, payload).Encode()))
+	}
+
+	t.Run("players can ask for their rooms", func(t *testing.T) {
+		send(a1, websocket.VoiceRooms, "a1", websocket.VoiceRoomsPayload{})
+
+		var payload websocket.VoiceRoomsPayload
+		require.NoError(t, readType(t, a1, websocket.VoiceRooms).DecodePayload(&payload))
+		require.Len(t, payload.Rooms, 1)
+		assert.Equal(t, []string{"b1"}, payload.Rooms[0].Hears)
+	})
+
+	t.Run("offer, answer and candidates reach the peer", func(t *testing.T) {
+		send(a1, websocket.VoiceOffer, "a1", websocket.VoiceSignalPayload{Room: "game", To: "b1", SDP: "v=0 offer"})
+		offer := readType(t, b1, websocket.VoiceOffer)
+		assert.Equal(t, "a1", offer.PlayerID)
+		assert.Zero(t, offer.Seq, "signaling is not numbered for replay")
+		var offerPayload websocket.VoiceSignalPayload
+		require.NoError(t, offer.DecodePayload(&offerPayload))
+		assert.Equal(t, "v=0 offer", offerPayload.SDP)
+
+		send(b1, websocket.VoiceAnswer, "b1", websocket.VoiceSignalPayload{Room: "game", To: "a1", SDP: "v=0 answer"})
+		assert.Equal(t, "b1", readType(t, a1, websocket.VoiceAnswer).PlayerID)
+
+		index := 0
+		send(a1, websocket.VoiceCandidate, "a1", websocket.VoiceSignalPayload{
+			Room: "game", To: "b1",
+			Candidate: &websocket.ICECandidate{Candidate: "candidate:1 1 udp 2122260223 127.0.0.1 54321 typ host", SDPMid: "0", SDPMLineIndex: &index},
+		})
+		var candidate websocket.VoiceSignalPayload
+		require.NoError(t, readType(t, b1, websocket.VoiceCandidate).DecodePayload(&candidate))
+		require.NotNil(t, candidate.Candidate)
+		assert.Equal(t, "0", candidate.Candidate.SDPMid)
+	})
+
+	t.Run("signaling outside the allowed rooms is refused", func(t *testing.T) {
+		send(a1, websocket.VoiceOffer, "a1", websocket.VoiceSignalPayload{Room: "teamA", To: "b1", SDP: "v=0"})
+		var refused websocket.ErrorPayload
+		require.NoError(t, readType(t, a1, websocket.ErrorMessage).DecodePayload(&refused))
+		assert.Equal(t, websocket.ErrorCodeActionFailed, refused.Code)
+
+		send(a1, websocket.VoiceOffer, "a1", websocket.VoiceSignalPayload{Room: "game", To: "b1"})
+		require.NoError(t, readType(t, a1, websocket.ErrorMessage).DecodePayload(&refused))
+		assert.Equal(t, websocket.ErrorCodeInvalidPayload, refused.Code)
+	})
+}
@@ -5,10 +5,10 @@ import "net/http"
 
 type GameServiceInterface interface {
 	CreateGame(teamSize int) (*models.Game, error)
-	AddPlayer(gameID string, playerName string) (*models.Player, error)
+	AddPlayer(gameID string, playerName string, expected models.VersionMatch) (*models.Player, error)
 	GetGame(gameID string) (*models.Game, error)
-	StartGame(gameID string) (*models.Game, error)
-	EndGame(gameID string) (*models.Game, error)
+	StartGame(gameID string, expected models.VersionMatch) (*models.Game, error)
+	EndGame(gameID string, expected models.VersionMatch) (*models.Game, error)
 	UpdateGame(game *models.Game) error
 }
 
@@ -16,30 +16,140 @@ type MatchServiceInterface interface {
 	GetMatch(gameID, matchID string) (*models.MatchDetails, error)
 	StartMatch(gameID, matchID string, teamAssignments map[string][]string) (*models.MatchDetails, error)
 	EndMatch(gameID, matchID string) (*models.MatchDetails, error)
-	ScorePoint(matchID string, isTeamA bool) (*models.MatchDetails, error)
+	ScorePoint(matchID, hostID string, isTeamA bool) (*models.MatchDetails, error)
 	CreateStage(gameID, matchID string, stageDetails models.MatchStageDetails) (*models.MatchStage, error)
 	SwitchTeam(matchID string, playerID string) (*models.MatchDetails, error)
 	ProcessGuessAttempt(gameID, matchID string, attempt *models.GuessAttempt) error
+	GetGameStats(gameID string) (*models.GameStats, error)
+	GetScoreLedger(gameID string) []models.ScoreEntry
 }
 
 type GameEventsServiceInterface interface {
-	StartStage(gameID string, stageNum int) error
+	StartStage(gameID string, playerID string, stageNum int) error
 	HandleClue(gameID string, playerID string, clue string) error
 	HandleGuess(gameID string, playerID string, guess string) error
 	HandleViolation(gameID string, reporterID string, violationType string) error
 }
 
+type ViolationServiceInterface interface {
+	ReportViolation(gameID, reporterID, violationType, cardID string) (*models.Violation, error)
+	DisputeViolation(gameID, violationID, playerID string) (*models.Violation, error)
+	VoteOnViolation(gameID, violationID, playerID string, uphold bool) (*models.Violation, error)
+	ResolveViolation(gameID, violationID, refereeID string, uphold bool) (*models.Violation, error)
+	GetViolations(gameID string) []models.Violation
+	SetReferee(gameID, hostID, playerID string) error
+}
+
+type VoiceServiceInterface interface {
+	SendVoiceRooms(gameID, playerID string) error
+	AuthorizeSignal(gameID, fromID, toID, roomID string) error
+}
+
+type TranscriptServiceInterface interface {
+	HandleTranscript(gameID, playerID string, chunk models.TranscriptChunk) error
+}
+
+type ChatServiceInterface interface {
+	SendChat(gameID, playerID, channel, text string) (*models.ChatMessage, error)
+	SendChatHistory(gameID, playerID string) error
+}
+
+type EventStoreInterface interface {
+	Append(events ...models.DomainEvent) ([]models.DomainEvent, error) // All or none are appended
+	Load(gameID string) ([]models.DomainEvent, error)
+	GameIDs() ([]string, error)
+}
+
+// Committer is an event store that keeps games and matches too, and writes
+// an operation's events with the games and matches they change in one
+// transaction. Reads see none of them until it commits.
+type Committer interface {
+	Commit(events []models.DomainEvent, games []*models.Game, matches []*models.MatchDetails) ([]models.DomainEvent, error)
+}
+
+// GameRepository stores the current state of every game
+type GameRepository interface {
+	GetGame(gameID string) (*models.Game, error)
+	ListGames() ([]*models.Game, error)
+	SaveGames(games ...*models.Game) error // All or none are saved
+}
+
+// MatchRepository stores the current state of every match
+type MatchRepository interface {
+	GetMatch(matchID string) (*models.MatchDetails, error)
+	ListMatches() ([]*models.MatchDetails, error)
+	SaveMatches(matches ...*models.MatchDetails) error // All or none are saved
+}
+
+type EventRecorder interface {
+	// RecordEvents appends events to a game's log and applies them to the
+	// game. The matches they change are saved to their repository with them,
+	// in the same transaction when the event store keeps matches too.
+	RecordEvents(gameID string, events []models.DomainEvent, repository MatchRepository, matches ...*models.MatchDetails) error
+}
+
+type EventLogInterface interface {
+	Events(gameID string) ([]models.DomainEvent, error)
+	ReplayGame(gameID string, version int) (*models.Game, error)
+}
+
+type RoleResolver interface {
+	// PlayerRole returns the player's role in the game's current stage
+	PlayerRole(gameID, playerID string) models.PlayerRole
+}
+
 type WebSocketClientInterface interface {
 	GetID() string
 	GetGameID() string
 	Read()
 	Write()
+	Enqueue(message []byte) bool
+}
+
+type GameSnapshotProvider interface {
+	Snapshot(gameID, playerID string) (*models.GameSnapshot, error)
 }
 
 type WebSocketManagerInterface interface {
 	Register(client WebSocketClientInterface)
 	Unregister(client WebSocketClientInterface)
 	SendToGame(gameID string, message []byte)
+	SendToPlayer(gameID, playerID string, message []byte)
+	SendToPlayers(gameID string, playerIDs []string, message []byte)
+	SendToGameExcept(gameID string, excludedPlayerIDs []string, message []byte)
 	HandleConnection(w http.ResponseWriter, r *http.Request, gameID, playerID string)
 	Run()
 }
+
+// RestoreListener is told about games restored after a server restart, so
+// that clients reconnecting to them can be sent the restored state
+type RestoreListener interface {
+	GameRestored(gameID string)
+}
+
+type PresenceTracker interface {
+	GamePresence(gameID string) []models.PlayerPresence
+	AllPresent(gameID string, playerIDs []string) bool
+	SpectatorCount(gameID string) int
+}
+
+type PresenceListener interface {
+	PresenceChanged(event models.PresenceEvent, presence models.PlayerPresence)
+	SpectatorsChanged(gameID string, count int)
+}
+
+type SessionIssuer interface {
+	Issue(gameID, playerID string) (string, error)
+}
+
+type ConnectionAuthenticator interface {
+	// Authenticate returns the HTTP status to reject the request with
+	// along with the reason, or a nil error to admit it
+	Authenticate(r *http.Request, gameID, playerID string) (int, error)
+}
+
+type SessionIdentifier interface {
+	// Identify returns the player a request's session token belongs to, or
+	// the HTTP status to reject the request with along with the reason
+	Identify(r *http.Request, gameID string) (string, int, error)
+}
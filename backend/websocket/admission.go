@@ -0,0 +1,55 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"taboo-game/types"
+)
+
+// SetAllowedOrigins limits the browser origins that may connect. Requests
+// without an Origin header come from outside a browser and are not
+// affected. No list, the default, allows every origin.
+func (m *Manager) SetAllowedOrigins(origins []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.allowedOrigins = toSet(origins)
+}
+
+// SetAuthenticator makes player connections prove who they are before
+// they are upgraded
+func (m *Manager) SetAuthenticator(authenticator types.ConnectionAuthenticator) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.authenticator = authenticator
+}
+
+// admit checks a request before it is upgraded or streamed to and writes
+// the rejection if it fails. Spectators, with no playerID, only need an
+// allowed origin.
+func (m *Manager) admit(w http.ResponseWriter, r *http.Request, gameID, playerID string) bool {
+	m.mu.RLock()
+	allowedOrigins := m.allowedOrigins
+	authenticator := m.authenticator
+	m.mu.RUnlock()
+
+	origin := r.Header.Get("Origin")
+	if origin != "" && len(allowedOrigins) > 0 && !allowedOrigins[origin] && !allowedOrigins["*"] {
+		rejectRequest(w, http.StatusForbidden, "origin not allowed")
+		return false
+	}
+
+	if playerID == "" || authenticator == nil {
+		return true
+	}
+	if status, err := authenticator.Authenticate(r, gameID, playerID); err != nil {
+		rejectRequest(w, status, err.Error())
+		return false
+	}
+	return true
+}
+
+func rejectRequest(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,72 @@
+package websocket
+
+// Audience says which connections of a game a delivery is for
+type Audience string
+
+const (
+	AudienceGame    Audience = "game"    // Every connection
+	AudiencePlayers Audience = "players" // Only the listed players
+	AudienceExcept  Audience = "except"  // Everyone but the listed players
+)
+
+// Delivery is an outbound message for some or all connections of a game,
+// as passed between server instances
+type Delivery struct {
+	GameID   string   `json:"gameId"`
+	Audience Audience `json:"audience"`
+	Players  []string `json:"players,omitempty"`
+	Seq      int64    `json:"seq,omitempty"` // Set by broadcasters that number messages across instances
+	Message  []byte   `json:"message"`
+
+	// Transient deliveries, such as voice signaling, are neither numbered
+	// nor kept for replay
+	Transient bool `json:"transient,omitempty"`
+}
+
+// includes reports whether a player is part of the delivery's audience
+func (d Delivery) includes() func(playerID string) bool {
+	switch d.Audience {
+	case AudiencePlayers:
+		players := toSet(d.Players)
+		return func(playerID string) bool { return players[playerID] }
+	case AudienceExcept:
+		excluded := toSet(d.Players)
+		return func(playerID string) bool { return !excluded[playerID] }
+	}
+	return func(playerID string) bool { return true }
+}
+
+// Broadcaster carries deliveries to every server instance, so a message sent
+// on one instance reaches the game's clients connected to any of them
+type Broadcaster interface {
+	// Publish sends a delivery to every subscribed instance, this one included
+	Publish(delivery Delivery) error
+	// Subscribe sets the function deliveries are handed to on this instance
+	Subscribe(deliver func(Delivery)) error
+	Close() error
+}
+
+// LocalBroadcaster delivers within this process only. It is the default.
+type LocalBroadcaster struct {
+	deliver func(Delivery)
+}
+
+func NewLocalBroadcaster() *LocalBroadcaster {
+	return &LocalBroadcaster{}
+}
+
+func (b *LocalBroadcaster) Publish(delivery Delivery) error {
+	if b.deliver != nil {
+		b.deliver(delivery)
+	}
+	return nil
+}
+
+func (b *LocalBroadcaster) Subscribe(deliver func(Delivery)) error {
+	b.deliver = deliver
+	return nil
+}
+
+func (b *LocalBroadcaster) Close() error {
+	return nil
+}
@@ -1,22 +1,58 @@
 package websocket
 
 import (
+	"log"
+	"sync"
+	"time"
+
 	"github.com/gorilla/websocket"
 )
 
+const (
+	// Time allowed to write a message to the peer
+	writeWait = 10 * time.Second
+
+	// Largest message accepted from the peer
+	maxMessageSize = 64 * 1024
+)
+
 type Client struct {
-	ID     string
-	GameID string
-	Socket *websocket.Conn
-	Send   chan []byte
+	ID        string
+	GameID    string
+	Socket    *websocket.Conn
+	Send      chan []byte
+	LastSeq   int64 // Last sequence number the client saw before reconnecting
+	Version   int   // Negotiated protocol version
+	Spectator bool  // Read-only; never gets card data
+	codec     Codec // Negotiated encoding
+	manager   *Manager
+	delay     *delayLine
+
+	mu         sync.Mutex
+	closed     bool
+	closeFrame []byte
+}
+
+// resumableClient is implemented by clients that can resume from the last
+// sequence number they received
+type resumableClient interface {
+	GetLastSeq() int64
+}
+
+// closableClient is implemented by clients whose send queue the manager
+// closes when it drops them
+type closableClient interface {
+	close(closeCode int, reason string)
 }
 
 func NewClient(id, gameID string, socket *websocket.Conn) *Client {
 	return &Client{
-		ID:     id,
-		GameID: gameID,
-		Socket: socket,
-		Send:   make(chan []byte, 256),
+		ID:      id,
+		GameID:  gameID,
+		Socket:  socket,
+		Send:    make(chan []byte, 256),
+		Version: ProtocolVersion,
+		codec:   codecs[EncodingJSON],
 	}
 }
 
@@ -32,21 +68,139 @@ func (c *Client) GetSocket() *websocket.Conn {
 	return c.Socket
 }
 
+func (c *Client) GetLastSeq() int64 {
+	return c.LastSeq
+}
+
+func (c *Client) IsSpectator() bool {
+	return c.Spectator
+}
+
+// Enqueue queues a message for the client without blocking. It returns
+// false when the client's queue is full.
+func (c *Client) Enqueue(message []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+	if c.delay != nil {
+		return c.delay.push(message)
+	}
+	select {
+	case c.Send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// close closes the send queue so the Write goroutine sends a close frame
+// and exits. Messages enqueued afterwards are dropped.
+func (c *Client) close(closeCode int, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		c.closeFrame = websocket.FormatCloseMessage(closeCode, reason)
+		if c.delay != nil {
+			c.delay.stop() // Closes Send once it exits
+		} else {
+			close(c.Send)
+		}
+	}
+}
+
+// Read handles inbound frames until the connection fails or the peer stops
+// answering pings, then unregisters the client
 func (c *Client) Read() {
-	defer c.Socket.Close()
+	defer func() {
+		if c.manager != nil {
+			c.manager.Unregister(c)
+		}
+		c.Socket.Close()
+	}()
+
+	pongWait := defaultPongWait
+	if c.manager != nil {
+		pongWait = c.manager.keepalive().pongWait
+	}
+	c.Socket.SetReadLimit(maxMessageSize)
+	c.Socket.SetReadDeadline(time.Now().Add(pongWait))
+	c.Socket.SetPongHandler(func(string) error {
+		return c.Socket.SetReadDeadline(time.Now().Add(pongWait))
+	})
 
 	for {
-		_, message, err := c.Socket.ReadMessage()
+		_, frame, err := c.Socket.ReadMessage()
 		if err != nil {
 			break
 		}
 
-		c.Send <- message
+		message, err := c.codec.Decode(frame)
+		if err != nil {
+			c.sendError("", &routeError{ErrorCodeInvalidMessage, err.Error()})
+			continue
+		}
+		if c.manager != nil {
+			c.manager.handleInbound(c, message)
+		}
 	}
 }
 
+// Write drains the send queue and pings the peer until the queue is closed
+// or a write fails. It is the only goroutine that writes to the socket.
 func (c *Client) Write() {
-	for message := range c.Send {
-		c.Socket.WriteMessage(websocket.TextMessage, message)
+	pingPeriod := defaultPingPeriod
+	if c.manager != nil {
+		pingPeriod = c.manager.keepalive().pingPeriod
+	}
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.Socket.Close()
+	}()
+
+	for {
+		select {
+		case message, ok := <-c.Send:
+			c.Socket.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.mu.Lock()
+				closeFrame := c.closeFrame
+				c.mu.Unlock()
+				c.Socket.WriteMessage(websocket.CloseMessage, closeFrame)
+				return
+			}
+			frame, err := c.codec.Encode(message)
+			if err != nil {
+				log.Printf("Error encoding message for client %s: %v", c.ID, err)
+				continue
+			}
+			if err := c.Socket.WriteMessage(c.codec.FrameType(), frame); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.Socket.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.Socket.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
 	}
 }
+
+// sendError queues a typed error reply for this client only
+func (c *Client) sendError(requestType MessageType, err error) {
+	code := ErrorCodeActionFailed
+	if routeErr, ok := err.(*routeError); ok {
+		code = routeErr.code
+	}
+
+	c.Enqueue(NewMessage(ErrorMessage, c.GameID, c.ID, ErrorPayload{
+		Code:        code,
+		Message:     err.Error(),
+		RequestType: requestType,
+	}).Encode())
+}
@@ -0,0 +1,145 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Encodings a client can choose for its connection
+const (
+	EncodingJSON    = "json"
+	EncodingMsgPack = "msgpack"
+)
+
+// Encodings lists the supported encodings, the default first
+var Encodings = []string{EncodingJSON, EncodingMsgPack}
+
+// Codec encodes messages in one wire format. Messages travel through the
+// manager, the broadcaster and the replay buffer as JSON and are re-encoded
+// for each connection from the payload structs registered in Messages.
+type Codec interface {
+	Name() string
+	FrameType() int // websocket.TextMessage or websocket.BinaryMessage
+
+	// Encode turns a JSON message into a frame
+	Encode(message []byte) ([]byte, error)
+
+	// Decode turns a frame from a client into a JSON message
+	Decode(frame []byte) ([]byte, error)
+}
+
+var codecs = map[string]Codec{
+	EncodingJSON:    jsonCodec{},
+	EncodingMsgPack: msgpackCodec{},
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return EncodingJSON
+}
+
+func (jsonCodec) FrameType() int {
+	return websocket.TextMessage
+}
+
+func (jsonCodec) Encode(message []byte) ([]byte, error) {
+	return message, nil
+}
+
+func (jsonCodec) Decode(frame []byte) ([]byte, error) {
+	return frame, nil
+}
+
+// msgpackCodec encodes the envelope and its payload struct as MessagePack,
+// with the same field names as JSON
+type msgpackCodec struct{}
+
+// wireMessage is a Message whose payload is its registered struct rather
+// than embedded JSON
+type wireMessage[P any] struct {
+	Version  int         `json:"v"`
+	Type     MessageType `json:"type"`
+	GameID   string      `json:"gameId"`
+	PlayerID string      `json:"playerId"`
+	Payload  P           `json:"payload"`
+	Seq      int64       `json:"seq,omitempty"`
+}
+
+func (msgpackCodec) Name() string {
+	return EncodingMsgPack
+}
+
+func (msgpackCodec) FrameType() int {
+	return websocket.BinaryMessage
+}
+
+func (msgpackCodec) Encode(message []byte) ([]byte, error) {
+	var msg Message
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+	payload := newPayload(msg.Type)
+	if err := msg.DecodePayload(payload); err != nil {
+		return nil, err
+	}
+
+	var frame bytes.Buffer
+	encoder := msgpack.NewEncoder(&frame)
+	encoder.SetCustomStructTag("json")
+	err := encoder.Encode(wireMessage[interface{}]{
+		Version:  msg.Version,
+		Type:     msg.Type,
+		GameID:   msg.GameID,
+		PlayerID: msg.PlayerID,
+		Payload:  payload,
+		Seq:      msg.Seq,
+	})
+	return frame.Bytes(), err
+}
+
+func (msgpackCodec) Decode(frame []byte) ([]byte, error) {
+	var wire wireMessage[msgpack.RawMessage]
+	decoder := msgpack.NewDecoder(bytes.NewReader(frame))
+	decoder.SetCustomStructTag("json")
+	if err := decoder.Decode(&wire); err != nil {
+		return nil, errors.New("message must be a MessagePack map")
+	}
+
+	payload := newPayload(wire.Type)
+	if len(wire.Payload) > 0 {
+		decoder = msgpack.NewDecoder(bytes.NewReader(wire.Payload))
+		decoder.SetCustomStructTag("json")
+		if err := decoder.Decode(payload); err != nil {
+			return nil, err
+		}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(Message{
+		Version:  wire.Version,
+		Type:     wire.Type,
+		GameID:   wire.GameID,
+		PlayerID: wire.PlayerID,
+		Payload:  data,
+		Seq:      wire.Seq,
+	})
+}
+
+// newPayload returns a pointer to a new payload struct for the message
+// type, or to an empty interface for unknown types
+func newPayload(msgType MessageType) interface{} {
+	spec, known := Messages[msgType]
+	if !known {
+		return new(interface{})
+	}
+	return reflect.New(reflect.TypeOf(spec.Payload)).Interface()
+}
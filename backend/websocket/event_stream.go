@@ -0,0 +1,233 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// streamClient receives a game's messages over Server-Sent Events. It goes
+// through the same broadcast path, replay buffer and slow-consumer handling
+// as a WebSocket client.
+type streamClient struct {
+	id        string
+	gameID    string
+	lastSeq   int64
+	spectator bool
+	send      chan []byte
+	delay     *delayLine
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func newStreamClient(id, gameID string) *streamClient {
+	return &streamClient{
+		id:     id,
+		gameID: gameID,
+		send:   make(chan []byte, 256),
+	}
+}
+
+func (c *streamClient) GetID() string {
+	return c.id
+}
+
+func (c *streamClient) GetGameID() string {
+	return c.gameID
+}
+
+func (c *streamClient) GetLastSeq() int64 {
+	return c.lastSeq
+}
+
+func (c *streamClient) IsSpectator() bool {
+	return c.spectator
+}
+
+// Read does nothing: actions from stream clients come in over REST
+func (c *streamClient) Read() {}
+
+// Write does nothing: HandleEventStream writes the stream itself
+func (c *streamClient) Write() {}
+
+func (c *streamClient) Enqueue(message []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+	if c.delay != nil {
+		return c.delay.push(message)
+	}
+	select {
+	case c.send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *streamClient) close(closeCode int, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		if c.delay != nil {
+			c.delay.stop() // Closes send once it exits
+		} else {
+			close(c.send)
+		}
+	}
+}
+
+// HandleEventStream streams a game's messages as Server-Sent Events until
+// the request ends. An empty playerID makes a spectator stream, which can be
+// delayed by ?delay=N seconds, and ?v=N picks the protocol version. Each
+// event's id is the message's sequence number, so a reconnecting
+// EventSource resumes through the Last-Event-ID header.
+func (m *Manager) HandleEventStream(w http.ResponseWriter, r *http.Request, gameID, playerID string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	if !m.admit(w, r, gameID, playerID) {
+		return
+	}
+	negotiated, err := negotiate(r)
+	if err == nil && negotiated.codec.Name() != EncodingJSON {
+		err = errors.New("event streams are only sent as JSON")
+	}
+	if err != nil {
+		rejectRequest(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	client := newStreamClient(playerID, gameID)
+	if playerID == "" {
+		client.id = newSpectatorID()
+		client.spectator = true
+		if delay := spectatorDelay(r); delay > 0 {
+			client.delay = startDelayLine(delay, client.send)
+		}
+	}
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+	if lastSeq, err := strconv.ParseInt(lastEventID, 10, 64); err == nil && lastSeq > 0 {
+		client.lastSeq = lastSeq
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Keep proxies from buffering the stream
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	m.Register(client)
+	defer m.Unregister(client)
+
+	ticker := time.NewTicker(m.keepalive().pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case message, ok := <-client.send:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			// A comment line keeps idle proxies from closing the stream
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes one message as an SSE event, using its sequence number
+// as the event id
+func writeEvent(w io.Writer, message []byte) error {
+	var header struct {
+		Seq int64 `json:"seq"`
+	}
+	json.Unmarshal(message, &header)
+
+	var event bytes.Buffer
+	if header.Seq > 0 {
+		fmt.Fprintf(&event, "id: %d\n", header.Seq)
+	}
+	for _, line := range bytes.Split(message, []byte("\n")) {
+		fmt.Fprintf(&event, "data: %s\n", line)
+	}
+	event.WriteString("\n")
+
+	_, err := w.Write(event.Bytes())
+	return err
+}
+
+// HandleAction runs an action posted over REST by a client that cannot use
+// a WebSocket. The body is the same message a WebSocket client would send.
+func (m *Manager) HandleAction(w http.ResponseWriter, r *http.Request, gameID, playerID string) {
+	if !m.admit(w, r, gameID, playerID) {
+		return
+	}
+
+	var msg Message
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		writeActionError(w, http.StatusBadRequest, "", &routeError{ErrorCodeInvalidMessage, "message must be a JSON object"})
+		return
+	}
+	if msg.Version != 0 && !supportsVersion(msg.Version) {
+		writeActionError(w, http.StatusBadRequest, msg.Type, versionMismatch(msg.Version))
+		return
+	}
+	if msg.GameID != gameID || msg.PlayerID != playerID {
+		writeActionError(w, http.StatusForbidden, msg.Type, &routeError{ErrorCodeIdentityMismatch, "gameId and playerId must match the request path"})
+		return
+	}
+
+	m.markActive(gameID, playerID)
+	if err := m.dispatch(msg); err != nil {
+		status := http.StatusBadRequest
+		if routeErr, ok := err.(*routeError); ok && routeErr.code == ErrorCodeUnavailable {
+			status = http.StatusServiceUnavailable
+		}
+		writeActionError(w, status, msg.Type, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeActionError(w http.ResponseWriter, status int, requestType MessageType, err error) {
+	code := ErrorCodeActionFailed
+	if routeErr, ok := err.(*routeError); ok {
+		code = routeErr.code
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":       err.Error(),
+		"code":        code,
+		"requestType": requestType,
+	})
+}
@@ -0,0 +1,31 @@
+package websocket
+
+import "time"
+
+const (
+	// Time allowed to read the next pong from the peer
+	defaultPongWait = 60 * time.Second
+
+	// Pings are sent at this period, which must be less than the pong wait
+	defaultPingPeriod = (defaultPongWait * 9) / 10
+)
+
+type keepaliveSettings struct {
+	pingPeriod time.Duration
+	pongWait   time.Duration
+}
+
+// SetKeepalive changes how often new connections are pinged and how long
+// they may stay silent before being dropped as dead
+func (m *Manager) SetKeepalive(pingPeriod, pongWait time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pingPeriod = pingPeriod
+	m.pongWait = pongWait
+}
+
+func (m *Manager) keepalive() keepaliveSettings {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return keepaliveSettings{pingPeriod: m.pingPeriod, pongWait: m.pongWait}
+}
@@ -4,55 +4,257 @@ import (
 	"github.com/gorilla/websocket"
 	"log"
 	"net/http"
+	"strconv"
 	"sync"
+	"taboo-game/models"
 	"taboo-game/types"
+	"time"
 )
 
 var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
+	ReadBufferSize:    1024,
+	WriteBufferSize:   1024,
+	EnableCompression: true, // Only used when the client offers permessage-deflate
 	CheckOrigin: func(r *http.Request) bool {
-		return true // In production, implement proper origin checking
+		return true // Checked by Manager.admit before upgrading
 	},
 }
 
 type Manager struct {
-	mu              sync.RWMutex
-	gameConnections map[string]map[*websocket.Conn]bool
-	gameEvents      types.GameEventsServiceInterface
-	register        chan types.WebSocketClientInterface
-	unregister      chan types.WebSocketClientInterface
-	shutdown        chan struct{}
+	mu               sync.RWMutex
+	gameConnections  map[string]map[types.WebSocketClientInterface]bool
+	gameEvents       types.GameEventsServiceInterface
+	broadcaster      Broadcaster
+	authenticator    types.ConnectionAuthenticator
+	allowedOrigins   map[string]bool
+	snapshots        types.GameSnapshotProvider
+	replay           map[string]*replayBuffer
+	replaySize       int
+	restored         map[string]map[string]bool // Restored games and the players sent their state since
+	presence         map[string]map[string]*playerPresence
+	pendingPresence  []presenceUpdate
+	pendingCounts    map[string]bool // Games whose spectator count changed
+	presenceListener types.PresenceListener
+	gracePeriod      time.Duration
+	idleTimeout      time.Duration
+	pingPeriod       time.Duration
+	pongWait         time.Duration
+	register         chan types.WebSocketClientInterface
+	unregister       chan types.WebSocketClientInterface
+	shutdown         chan struct{}
 }
 
 func NewManager(gameEvents types.GameEventsServiceInterface) *Manager {
-	return &Manager{
-		gameConnections: make(map[string]map[*websocket.Conn]bool),
+	m := &Manager{
+		gameConnections: make(map[string]map[types.WebSocketClientInterface]bool),
 		gameEvents:      gameEvents,
+		replay:          make(map[string]*replayBuffer),
+		replaySize:      defaultReplayBufferSize,
+		restored:        make(map[string]map[string]bool),
+		presence:        make(map[string]map[string]*playerPresence),
+		gracePeriod:     defaultGracePeriod,
+		idleTimeout:     defaultIdleTimeout,
+		pingPeriod:      defaultPingPeriod,
+		pongWait:        defaultPongWait,
 		register:        make(chan types.WebSocketClientInterface),
 		unregister:      make(chan types.WebSocketClientInterface),
 		shutdown:        make(chan struct{}),
 	}
+	m.SetBroadcaster(NewLocalBroadcaster())
+	return m
+}
+
+// SetBroadcaster changes how outbound messages reach the game's clients on
+// every server instance
+func (m *Manager) SetBroadcaster(broadcaster Broadcaster) error {
+	if err := broadcaster.Subscribe(m.deliver); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	previous := m.broadcaster
+	m.broadcaster = broadcaster
+	m.mu.Unlock()
+
+	if previous != nil {
+		return previous.Close()
+	}
+	return nil
+}
+
+// SetSnapshotProvider sets where the state snapshot sent to clients that
+// missed more messages than the replay buffer holds comes from
+func (m *Manager) SetSnapshotProvider(snapshots types.GameSnapshotProvider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshots = snapshots
+}
+
+// SetReplayBufferSize changes how many messages are kept per game for replay
+func (m *Manager) SetReplayBufferSize(size int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.replaySize = size
+}
+
+// GameRestored makes the first connection of each player to a game restored
+// after a server restart get a snapshot, since what the player saw before
+// the restart cannot be replayed
+func (m *Manager) GameRestored(gameID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.restored[gameID] = make(map[string]bool)
 }
 
 func (m *Manager) Register(client types.WebSocketClientInterface) {
-	m.register <- client
+	select {
+	case m.register <- client:
+	case <-m.shutdown:
+	}
 }
 
 func (m *Manager) Unregister(client types.WebSocketClientInterface) {
-	m.unregister <- client
+	select {
+	case m.unregister <- client:
+	case <-m.shutdown:
+	}
 }
 
 func (m *Manager) SendToGame(gameID string, message []byte) {
+	m.publish(Delivery{GameID: gameID, Audience: AudienceGame, Message: message})
+}
+
+// SendToPlayer delivers a message to every connection of one player
+func (m *Manager) SendToPlayer(gameID, playerID string, message []byte) {
+	m.publish(Delivery{GameID: gameID, Audience: AudiencePlayers, Players: []string{playerID}, Message: message})
+}
+
+// SendToPlayers delivers a message to the connections of the given players
+func (m *Manager) SendToPlayers(gameID string, playerIDs []string, message []byte) {
+	m.publish(Delivery{GameID: gameID, Audience: AudiencePlayers, Players: playerIDs, Message: message})
+}
+
+// SendToGameExcept delivers a message to every connection of a game except
+// those of the given players
+func (m *Manager) SendToGameExcept(gameID string, excludedPlayerIDs []string, message []byte) {
+	m.publish(Delivery{GameID: gameID, Audience: AudienceExcept, Players: excludedPlayerIDs, Message: message})
+}
+
+// publish hands a delivery to the broadcaster. If that fails a transient
+// message still reaches this instance's clients. Any other message cannot
+// be numbered the way the other instances number the game's messages, so
+// this instance's clients it was for are sent a snapshot instead, and
+// clients resuming from before it will be too.
+func (m *Manager) publish(delivery Delivery) {
 	m.mu.RLock()
-	connections := m.gameConnections[gameID]
+	broadcaster := m.broadcaster
 	m.mu.RUnlock()
 
-	for conn := range connections {
-		if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
-			log.Printf("Error sending message: %v", err)
+	err := broadcaster.Publish(delivery)
+	if err == nil {
+		return
+	}
+	if delivery.Transient {
+		log.Printf("Error broadcasting to game %s, delivering locally only: %v", delivery.GameID, err)
+		m.deliver(delivery)
+		return
+	}
+
+	log.Printf("Error broadcasting to game %s, resyncing its clients on this instance: %v", delivery.GameID, err)
+	m.mu.Lock()
+	buffer, exists := m.replay[delivery.GameID]
+	if !exists {
+		buffer = &replayBuffer{}
+		m.replay[delivery.GameID] = buffer
+	}
+	buffer.markMissed()
+	m.mu.Unlock()
+
+	// The sender may hold locks the snapshot provider needs
+	go m.resync(delivery.GameID, delivery.includes())
+}
+
+// resync sends a snapshot to the included players' connections of a game
+func (m *Manager) resync(gameID string, include func(playerID string) bool) {
+	m.mu.RLock()
+	snapshots := m.snapshots
+	var clients []types.WebSocketClientInterface
+	for client := range m.gameConnections[gameID] {
+		if include(client.GetID()) {
+			clients = append(clients, client)
 		}
 	}
+	m.mu.RUnlock()
+
+	if snapshots == nil {
+		return
+	}
+	for _, client := range clients {
+		m.sendSnapshot(client, snapshots)
+	}
+}
+
+// deliver queues a delivery from any instance for this instance's clients
+func (m *Manager) deliver(delivery Delivery) {
+	if delivery.Transient {
+		m.sendTransient(delivery.GameID, delivery.Message, delivery.includes())
+		return
+	}
+	m.sendFiltered(delivery.GameID, delivery.Message, delivery.includes(), delivery.Seq)
+}
+
+// sendFiltered stamps a message with its sequence number, or the game's
+// next one when the broadcaster did not number it, keeps it for replay and
+// queues it for the included players' connections.
+// It never blocks on a connection: a client whose queue is full is dropped
+// and resumes from its last sequence number when it reconnects.
+func (m *Manager) sendFiltered(gameID string, message []byte, include func(playerID string) bool, seq int64) {
+	m.mu.Lock()
+	buffer, exists := m.replay[gameID]
+	if !exists {
+		buffer = &replayBuffer{}
+		m.replay[gameID] = buffer
+	}
+	stamped := buffer.add(message, include, m.replaySize, seq)
+	dropped := m.enqueueLocked(gameID, stamped.data, include)
+	m.mu.Unlock()
+
+	if dropped {
+		m.flushPresence()
+	}
+}
+
+// sendTransient queues a message for the included players' connections
+// without numbering it or keeping it for replay
+func (m *Manager) sendTransient(gameID string, message []byte, include func(playerID string) bool) {
+	m.mu.Lock()
+	dropped := m.enqueueLocked(gameID, message, include)
+	m.mu.Unlock()
+
+	if dropped {
+		m.flushPresence()
+	}
+}
+
+// enqueueLocked queues a message for the included players' connections and
+// drops any whose queue is full. It reports whether presence changes are
+// waiting to be flushed. Must be called with m.mu held.
+func (m *Manager) enqueueLocked(gameID string, message []byte, include func(playerID string) bool) bool {
+	for client := range m.gameConnections[gameID] {
+		if include(client.GetID()) && !client.Enqueue(message) {
+			log.Printf("Dropping slow client %s in game %s", client.GetID(), gameID)
+			m.dropLocked(client, websocket.CloseTryAgainLater, "slow consumer")
+		}
+	}
+	return len(m.pendingPresence) > 0 || len(m.pendingCounts) > 0
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
 }
 
 func (m *Manager) Run() {
@@ -73,36 +275,139 @@ func (m *Manager) Stop() {
 }
 
 func (m *Manager) handleRegister(client types.WebSocketClientInterface) {
+	gameID, playerID := client.GetGameID(), client.GetID()
+
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	if _, exists := m.gameConnections[gameID]; !exists {
+		m.gameConnections[gameID] = make(map[types.WebSocketClientInterface]bool)
+	}
+	m.gameConnections[gameID][client] = true
+
+	// Spectators have no presence, only a count
+	var previous models.PresenceState
+	if isSpectator(client) {
+		m.noteSpectatorsLocked(gameID)
+	} else {
+		previous = m.connectLocked(gameID, playerID)
+	}
 
-	if _, exists := m.gameConnections[client.GetGameID()]; !exists {
-		m.gameConnections[client.GetGameID()] = make(map[*websocket.Conn]bool)
+	// A returning player, or any client that says what it saw last, gets
+	// what it missed. Replaying under the lock keeps it ahead of new messages.
+	lastSeq := int64(0)
+	if resumable, ok := client.(resumableClient); ok {
+		lastSeq = resumable.GetLastSeq()
 	}
-	if wsClient, ok := client.(*Client); ok {
-		m.gameConnections[client.GetGameID()][wsClient.Socket] = true
+	needsSnapshot := false
+	if resynced, restored := m.restored[gameID]; restored && !resynced[playerID] {
+		resynced[playerID] = true
+		needsSnapshot = m.snapshots != nil
+	} else if buffer, exists := m.replay[gameID]; exists && (lastSeq > 0 || previous == models.PresenceReconnecting || previous == models.PresenceGone) {
+		missed, complete := buffer.since(lastSeq, playerID)
+		needsSnapshot = !complete && m.snapshots != nil
+		if !needsSnapshot {
+			for _, msg := range missed {
+				if client.Enqueue(msg.data) {
+					continue
+				}
+				// The rest does not fit in the client's queue, so it gets
+				// the current state instead, or resumes again later
+				needsSnapshot = m.snapshots != nil
+				if !needsSnapshot {
+					log.Printf("Dropping client %s in game %s whose replay does not fit", playerID, gameID)
+					m.dropLocked(client, websocket.CloseTryAgainLater, "slow consumer")
+				}
+				break
+			}
+		}
+	}
+	snapshots := m.snapshots
+	m.mu.Unlock()
+	m.flushPresence()
+
+	// The snapshot is taken outside the lock since the provider may be
+	// sending messages itself
+	if needsSnapshot {
+		m.sendSnapshot(client, snapshots)
 	}
 }
 
+// sendSnapshot sends a client the current game state, stamped with the
+// last sequence number it reflects
+func (m *Manager) sendSnapshot(client types.WebSocketClientInterface, snapshots types.GameSnapshotProvider) {
+	snapshot, err := snapshots.Snapshot(client.GetGameID(), client.GetID())
+	if err != nil {
+		log.Printf("Error building snapshot for game %s: %v", client.GetGameID(), err)
+		return
+	}
+
+	m.mu.Lock()
+	msg := NewMessage(SyncSnapshot, client.GetGameID(), client.GetID(), SyncSnapshotPayload{State: snapshot})
+	if buffer, exists := m.replay[client.GetGameID()]; exists {
+		msg.Seq = buffer.lastSeq
+	}
+	dropped := !client.Enqueue(msg.Encode())
+	if dropped {
+		log.Printf("Dropping client %s in game %s with no room for a snapshot", client.GetID(), client.GetGameID())
+		m.dropLocked(client, websocket.CloseTryAgainLater, "slow consumer")
+	}
+	m.mu.Unlock()
+
+	if dropped {
+		m.flushPresence()
+	}
+}
+
+// handleUnregister drops a connection and closes its send queue. It is safe
+// to call more than once for the same client.
 func (m *Manager) handleUnregister(client types.WebSocketClientInterface) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.dropLocked(client, websocket.CloseNormalClosure, "")
+	m.mu.Unlock()
+	m.flushPresence()
+}
 
-	if wsClient, ok := client.(*Client); ok {
-		if conns, exists := m.gameConnections[client.GetGameID()]; exists {
-			delete(conns, wsClient.Socket)
-		}
+// dropLocked removes a connection and closes its send queue; the writer
+// then closes the socket with the given close code.
+// Must be called with m.mu held.
+func (m *Manager) dropLocked(client types.WebSocketClientInterface, closeCode int, reason string) {
+	gameID := client.GetGameID()
+	conns, exists := m.gameConnections[gameID]
+	if !exists || !conns[client] {
+		return
+	}
+	delete(conns, client)
+	if len(conns) == 0 {
+		delete(m.gameConnections, gameID)
+	}
+	if isSpectator(client) {
+		m.noteSpectatorsLocked(gameID)
+	} else {
+		m.disconnectLocked(gameID, client.GetID())
+	}
+
+	if closable, ok := client.(closableClient); ok {
+		closable.close(closeCode, reason)
 	}
 }
 
 func (m *Manager) HandleConnection(w http.ResponseWriter, r *http.Request, gameID string, playerID string) {
-	conn, err := upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Printf("Failed to upgrade connection: %v", err)
+	if !m.admit(w, r, gameID, playerID) {
+		return
+	}
+
+	conn, negotiated, ok := upgrade(w, r)
+	if !ok {
 		return
 	}
 
 	client := NewClient(playerID, gameID, conn)
+	client.manager = m
+	client.Version = negotiated.version
+	client.codec = negotiated.codec
+	// Reconnecting clients pass the last sequence number they received
+	if lastSeq, err := strconv.ParseInt(r.URL.Query().Get("lastSeq"), 10, 64); err == nil && lastSeq > 0 {
+		client.LastSeq = lastSeq
+	}
 	m.Register(client)
 
 	// Handle connection in goroutines
@@ -1,19 +1,99 @@
 package websocket
 
-// Message represents a WebSocket message structure
+import "encoding/json"
+
+// Message is the envelope of every message sent over a connection. Its
+// payload is the struct registered for its type in Messages.
 type Message struct {
-	Type     MessageType            `json:"type"`
-	GameID   string                 `json:"gameId"`
-	PlayerID string                 `json:"playerId"`
-	Payload  map[string]interface{} `json:"payload"`
+	Version  int             `json:"v"` // Protocol version
+	Type     MessageType     `json:"type"`
+	GameID   string          `json:"gameId"`
+	PlayerID string          `json:"playerId"`
+	Payload  json.RawMessage `json:"payload"`
+	Seq      int64           `json:"seq,omitempty"` // Per-game sequence number, set by the manager on send
+}
+
+// NewMessage builds a message of the current protocol version
+func NewMessage(msgType MessageType, gameID, playerID string, payload interface{}) Message {
+	data, _ := json.Marshal(payload)
+	return Message{
+		Version:  ProtocolVersion,
+		Type:     msgType,
+		GameID:   gameID,
+		PlayerID: playerID,
+		Payload:  data,
+	}
+}
+
+// Encode returns the message as sent on the wire
+func (m Message) Encode() []byte {
+	data, _ := json.Marshal(m)
+	return data
+}
+
+// DecodePayload unmarshals the payload into its struct
+func (m Message) DecodePayload(payload interface{}) error {
+	if len(m.Payload) == 0 {
+		return json.Unmarshal([]byte("{}"), payload)
+	}
+	return json.Unmarshal(m.Payload, payload)
 }
 
 type MessageType string
 
 const (
-	StartStage  MessageType = "START_STAGE"
-	GiveClue    MessageType = "GIVE_CLUE"
-	TimerUpdate MessageType = "TIMER_UPDATE"
-	StageEnd    MessageType = "STAGE_END"
-	GameEnd     MessageType = "GAME_END"
+	StartStage   MessageType = "START_STAGE"
+	GiveClue     MessageType = "GIVE_CLUE"
+	MakeGuess    MessageType = "MAKE_GUESS"
+	TimerUpdate  MessageType = "TIMER_UPDATE"
+	StageEnd     MessageType = "STAGE_END"
+	GameEnd      MessageType = "GAME_END"
+	ScoreUpdate  MessageType = "SCORE_UPDATE"
+	GuessResult  MessageType = "GUESS_RESULT" // How a typed guess was judged
+	CardDrawn    MessageType = "CARD_DRAWN"   // Next card after a correct guess
+	ClueFlagged  MessageType = "CLUE_FLAGGED" // Asks the spotters to judge a borderline clue
+	ClueSpoken   MessageType = "CLUE_SPOKEN"  // Transcript of a clue-giver's speech
+	TurnChange   MessageType = "TURN_CHANGE"
+	ErrorMessage MessageType = "ERROR"
+	SyncSnapshot MessageType = "SYNC_SNAPSHOT" // Full state for a client that missed too much to replay
+
+	// Sent to the other players when a player joins, leaves, reconnects or goes idle
+	PresenceUpdate MessageType = "PRESENCE_UPDATE"
+
+	// Sent to the host when spectators start or stop watching
+	SpectatorCount MessageType = "SPECTATOR_COUNT"
+
+	// Voice chat. Offers, answers and ICE candidates are relayed to one peer
+	// and never replayed.
+	VoiceRooms     MessageType = "VOICE_ROOMS"
+	VoiceOffer     MessageType = "VOICE_OFFER"
+	VoiceAnswer    MessageType = "VOICE_ANSWER"
+	VoiceCandidate MessageType = "VOICE_CANDIDATE"
+
+	// Text chat on the game channel or a team's channel
+	ChatSend    MessageType = "CHAT_SEND"
+	ChatMessage MessageType = "CHAT_MESSAGE"
+	ChatHistory MessageType = "CHAT_HISTORY"
+
+	// Violation workflow
+	ReportViolation   MessageType = "REPORT_VIOLATION"
+	DisputeViolation  MessageType = "DISPUTE_VIOLATION"
+	VoteViolation     MessageType = "VOTE_VIOLATION"
+	ResolveViolation  MessageType = "RESOLVE_VIOLATION"
+	ViolationReported MessageType = "VIOLATION_REPORTED"
+	ViolationDisputed MessageType = "VIOLATION_DISPUTED"
+	ViolationVote     MessageType = "VIOLATION_VOTE"
+	ViolationResolved MessageType = "VIOLATION_RESOLVED"
+)
+
+// Error codes sent back to a client in the payload of an ERROR message
+const (
+	ErrorCodeInvalidMessage   = "invalid_message"
+	ErrorCodeIdentityMismatch = "identity_mismatch"
+	ErrorCodeUnknownType      = "unknown_type"
+	ErrorCodeInvalidPayload   = "invalid_payload"
+	ErrorCodeActionFailed     = "action_failed"
+	ErrorCodeUnavailable      = "unavailable"
+	ErrorCodeReadOnly         = "read_only"
+	ErrorCodeVersionMismatch  = "version_mismatch"
 )
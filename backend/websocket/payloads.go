@@ -0,0 +1,207 @@
+package websocket
+
+import "taboo-game/models"
+
+// MessageSpec describes one message type of the protocol
+type MessageSpec struct {
+	Payload    interface{} // Zero value of the payload struct
+	FromClient bool        // Clients may send it
+	FromServer bool        // The server sends it
+}
+
+// Messages lists every message type with its payload. The JSON Schema and
+// TypeScript definitions in the frontend are generated from it.
+var Messages = map[MessageType]MessageSpec{
+	StartStage:        {Payload: StartStagePayload{}, FromClient: true, FromServer: true},
+	GiveClue:          {Payload: GiveCluePayload{}, FromClient: true, FromServer: true},
+	MakeGuess:         {Payload: MakeGuessPayload{}, FromClient: true},
+	GuessResult:       {Payload: GuessResultPayload{}, FromServer: true},
+	CardDrawn:         {Payload: CardDrawnPayload{}, FromServer: true},
+	ClueFlagged:       {Payload: ClueFlaggedPayload{}, FromServer: true},
+	ClueSpoken:        {Payload: ClueSpokenPayload{}, FromClient: true},
+	VoiceRooms:        {Payload: VoiceRoomsPayload{}, FromClient: true, FromServer: true},
+	VoiceOffer:        {Payload: VoiceSignalPayload{}, FromClient: true, FromServer: true},
+	VoiceAnswer:       {Payload: VoiceSignalPayload{}, FromClient: true, FromServer: true},
+	VoiceCandidate:    {Payload: VoiceSignalPayload{}, FromClient: true, FromServer: true},
+	ChatSend:          {Payload: ChatSendPayload{}, FromClient: true},
+	ChatMessage:       {Payload: ChatMessagePayload{}, FromServer: true},
+	ChatHistory:       {Payload: ChatHistoryPayload{}, FromClient: true, FromServer: true},
+	ReportViolation:   {Payload: ReportViolationPayload{}, FromClient: true},
+	DisputeViolation:  {Payload: DisputeViolationPayload{}, FromClient: true},
+	VoteViolation:     {Payload: ViolationDecisionPayload{}, FromClient: true},
+	ResolveViolation:  {Payload: ViolationDecisionPayload{}, FromClient: true},
+	TimerUpdate:       {Payload: TimerUpdatePayload{}, FromServer: true},
+	StageEnd:          {Payload: StageEndPayload{}, FromServer: true},
+	GameEnd:           {Payload: EmptyPayload{}, FromServer: true},
+	ScoreUpdate:       {Payload: ScoreUpdatePayload{}, FromServer: true},
+	TurnChange:        {Payload: TurnChangePayload{}, FromServer: true},
+	ErrorMessage:      {Payload: ErrorPayload{}, FromServer: true},
+	SyncSnapshot:      {Payload: SyncSnapshotPayload{}, FromServer: true},
+	PresenceUpdate:    {Payload: PresenceUpdatePayload{}, FromServer: true},
+	SpectatorCount:    {Payload: SpectatorCountPayload{}, FromServer: true},
+	ViolationReported: {Payload: ViolationPayload{}, FromServer: true},
+	ViolationDisputed: {Payload: ViolationPayload{}, FromServer: true},
+	ViolationVote:     {Payload: ViolationPayload{}, FromServer: true},
+	ViolationResolved: {Payload: ViolationPayload{}, FromServer: true},
+}
+
+// EmptyPayload is the payload of messages that carry nothing but their type
+type EmptyPayload struct{}
+
+// StartStagePayload asks to start a stage, and announces it. Only
+// clue-givers and spotters are sent the card.
+type StartStagePayload struct {
+	StageNum int              `json:"stageNum"`
+	Duration int              `json:"duration,omitempty"` // Seconds
+	WordCard *models.WordCard `json:"wordCard,omitempty"`
+}
+
+type GiveCluePayload struct {
+	Clue string `json:"clue"`
+}
+
+// ClueFlaggedPayload shows the spotters the words of a clue that came
+// close to the card. For a spoken clue it also gives when it was said.
+type ClueFlaggedPayload struct {
+	Flags       []ClueFlag `json:"flags"`
+	TimestampMS int64      `json:"timestampMs,omitempty"`
+}
+
+// ClueSpokenPayload is a chunk of a clue-giver's speech, transcribed by
+// their client
+type ClueSpokenPayload struct {
+	Text        string `json:"text"`
+	TimestampMS int64  `json:"timestampMs"` // When the speech started
+	DurationMS  int64  `json:"durationMs,omitempty"`
+}
+
+// ClueFlag is one borderline match and the category to call if the
+// spotters judge it a violation
+type ClueFlag struct {
+	Evidence      *models.ClueEvidence `json:"evidence"`
+	ViolationType models.ViolationType `json:"violationType"`
+}
+
+type MakeGuessPayload struct {
+	Guess string `json:"guess"`
+}
+
+// GuessResultPayload tells the game how a guess was judged. The answer is
+// only revealed once guessed.
+type GuessResultPayload struct {
+	Guess   string              `json:"guess"`
+	Verdict models.GuessVerdict `json:"verdict"`
+	Answer  string              `json:"answer,omitempty"`
+}
+
+// CardDrawnPayload announces the next card of a stage. Only clue-givers and
+// spotters are sent the card.
+type CardDrawnPayload struct {
+	StageNum int              `json:"stageNum"`
+	WordCard *models.WordCard `json:"wordCard,omitempty"`
+}
+
+// VoiceRoomsPayload lists the player's voice rooms. Clients send it empty to
+// ask for them; the server also sends it whenever the stage roles change.
+type VoiceRoomsPayload struct {
+	Rooms []models.VoiceRoom `json:"rooms,omitempty"`
+}
+
+// VoiceSignalPayload carries WebRTC signaling to one peer in a voice room.
+// The server relays it as is; the sender is the message's playerId.
+type VoiceSignalPayload struct {
+	Room      string        `json:"room"`
+	To        string        `json:"to"`
+	SDP       string        `json:"sdp,omitempty"`       // Offer or answer
+	Candidate *ICECandidate `json:"candidate,omitempty"` // ICE candidate
+}
+
+// ICECandidate mirrors the browser's RTCIceCandidateInit
+type ICECandidate struct {
+	Candidate     string `json:"candidate"`
+	SDPMid        string `json:"sdpMid,omitempty"`
+	SDPMLineIndex *int   `json:"sdpMLineIndex,omitempty"`
+}
+
+// StageEndPayload sums up the stage that ended
+type StageEndPayload struct {
+	Summary models.StageSummary `json:"summary"`
+}
+
+// ChatSendPayload posts a message to the game channel, or to the sender's
+// team channel ("teamA" or "teamB"). The channel defaults to the game.
+type ChatSendPayload struct {
+	Channel string `json:"channel,omitempty"`
+	Text    string `json:"text"`
+}
+
+type ChatMessagePayload struct {
+	Message *models.ChatMessage `json:"message"`
+}
+
+// ChatHistoryPayload holds the recent messages of the channels the player
+// can read. Clients send it empty to ask for them.
+type ChatHistoryPayload struct {
+	Messages []models.ChatMessage `json:"messages,omitempty"`
+}
+
+type ReportViolationPayload struct {
+	ViolationType string `json:"violationType"`
+}
+
+type DisputeViolationPayload struct {
+	ViolationID string `json:"violationId"`
+}
+
+// ViolationDecisionPayload is a vote on a disputed call, or the referee's
+// decision on it
+type ViolationDecisionPayload struct {
+	ViolationID string `json:"violationId"`
+	Uphold      *bool  `json:"uphold"` // Required
+}
+
+type TimerUpdatePayload struct {
+	Remaining int `json:"remaining"` // Seconds left in the stage
+}
+
+// ScoreUpdatePayload carries the totals after new ledger entries
+type ScoreUpdatePayload struct {
+	MatchID         string              `json:"matchId"`
+	TeamAScore      int                 `json:"teamAScore"`
+	TeamBScore      int                 `json:"teamBScore"`
+	ScoringTeam     string              `json:"scoringTeam"`
+	StageID         string              `json:"stageId,omitempty"`
+	StageTeamAScore int                 `json:"stageTeamAScore"`
+	StageTeamBScore int                 `json:"stageTeamBScore"`
+	Entries         []models.ScoreEntry `json:"entries"` // Ledger entries behind this update
+}
+
+type TurnChangePayload struct {
+	MatchID    string `json:"matchId"`
+	ActiveTeam string `json:"activeTeam"`
+	TimeLeft   int    `json:"timeLeft"` // Seconds
+}
+
+// ErrorPayload tells a client why its message was rejected
+type ErrorPayload struct {
+	Code        string      `json:"code"`
+	Message     string      `json:"message"`
+	RequestType MessageType `json:"requestType"`
+}
+
+type SyncSnapshotPayload struct {
+	State *models.GameSnapshot `json:"state"`
+}
+
+type PresenceUpdatePayload struct {
+	Event    models.PresenceEvent  `json:"event"`
+	Presence models.PlayerPresence `json:"presence"`
+}
+
+type SpectatorCountPayload struct {
+	Count int `json:"count"`
+}
+
+type ViolationPayload struct {
+	Violation *models.Violation `json:"violation"`
+}
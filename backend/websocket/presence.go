@@ -0,0 +1,240 @@
+package websocket
+
+import (
+	"sort"
+	"taboo-game/models"
+	"taboo-game/types"
+	"time"
+)
+
+const (
+	// How long a player who lost every connection is expected back
+	defaultGracePeriod = 30 * time.Second
+
+	// How long a connected player may send nothing before being idle
+	defaultIdleTimeout = 2 * time.Minute
+)
+
+type playerPresence struct {
+	models.PlayerPresence
+	graceTimer *time.Timer
+	idleTimer  *time.Timer
+}
+
+type presenceUpdate struct {
+	event    models.PresenceEvent
+	presence models.PlayerPresence
+}
+
+// SetGracePeriod changes how long a disconnected player stays reconnecting
+// before being considered gone
+func (m *Manager) SetGracePeriod(gracePeriod time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gracePeriod = gracePeriod
+}
+
+// SetIdleTimeout changes how long a connected player may stay silent
+// before being announced as idle
+func (m *Manager) SetIdleTimeout(idleTimeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.idleTimeout = idleTimeout
+}
+
+// PresenceState returns the presence of a player in a game. ok is false
+// when the player never connected.
+func (m *Manager) PresenceState(gameID, playerID string) (state models.PresenceState, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	player, exists := m.presence[gameID][playerID]
+	if !exists {
+		return "", false
+	}
+	return player.State, true
+}
+
+// GamePresence returns every player that has connected to a game
+func (m *Manager) GamePresence(gameID string) []models.PlayerPresence {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]models.PlayerPresence, 0, len(m.presence[gameID]))
+	for _, player := range m.presence[gameID] {
+		result = append(result, player.PlayerPresence)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].PlayerID < result[j].PlayerID
+	})
+	return result
+}
+
+// AllPresent reports whether every given player is connected to the game
+func (m *Manager) AllPresent(gameID string, playerIDs []string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, playerID := range playerIDs {
+		player, exists := m.presence[gameID][playerID]
+		if !exists || player.State != models.PresenceConnected {
+			return false
+		}
+	}
+	return true
+}
+
+// connectLocked marks a player connected and returns the state the player
+// was in before. Must be called with m.mu held.
+func (m *Manager) connectLocked(gameID, playerID string) (previous models.PresenceState) {
+	if _, exists := m.presence[gameID]; !exists {
+		m.presence[gameID] = make(map[string]*playerPresence)
+	}
+
+	player, exists := m.presence[gameID][playerID]
+	if !exists {
+		player = &playerPresence{PlayerPresence: models.PlayerPresence{GameID: gameID, PlayerID: playerID}}
+		m.presence[gameID][playerID] = player
+	}
+	previous = player.State
+
+	if player.graceTimer != nil {
+		player.graceTimer.Stop()
+		player.graceTimer = nil
+	}
+	player.Connections++
+	if player.State != models.PresenceConnected {
+		player.State = models.PresenceConnected
+		player.Since = time.Now()
+		if previous == "" {
+			m.notePresenceLocked(models.PresenceJoined, player)
+		} else {
+			m.notePresenceLocked(models.PresenceReconnected, player)
+		}
+	}
+	m.touchLocked(player)
+	return previous
+}
+
+// disconnectLocked drops one connection of a player. A player left without
+// connections is reconnecting until the grace period runs out.
+// Must be called with m.mu held.
+func (m *Manager) disconnectLocked(gameID, playerID string) {
+	player, exists := m.presence[gameID][playerID]
+	if !exists || player.Connections == 0 {
+		return
+	}
+
+	player.Connections--
+	if player.Connections > 0 {
+		return
+	}
+
+	if player.idleTimer != nil {
+		player.idleTimer.Stop()
+		player.idleTimer = nil
+	}
+	player.State = models.PresenceReconnecting
+	player.Idle = false
+	player.Since = time.Now()
+	player.graceTimer = time.AfterFunc(m.gracePeriod, func() {
+		m.expirePresence(player)
+	})
+	m.notePresenceLocked(models.PresenceDisconnected, player)
+}
+
+// markActive records activity from a player, ending any idle period
+func (m *Manager) markActive(gameID, playerID string) {
+	m.mu.Lock()
+	if player, exists := m.presence[gameID][playerID]; exists && player.State == models.PresenceConnected {
+		m.touchLocked(player)
+	}
+	m.mu.Unlock()
+	m.flushPresence()
+}
+
+// touchLocked must be called with m.mu held
+func (m *Manager) touchLocked(player *playerPresence) {
+	player.LastSeen = time.Now()
+	if player.Idle {
+		player.Idle = false
+		m.notePresenceLocked(models.PresenceActive, player)
+	}
+
+	if player.idleTimer != nil {
+		player.idleTimer.Stop()
+	}
+	player.idleTimer = time.AfterFunc(m.idleTimeout, func() {
+		m.markIdle(player)
+	})
+}
+
+func (m *Manager) markIdle(player *playerPresence) {
+	m.mu.Lock()
+	if player.State == models.PresenceConnected && !player.Idle && time.Since(player.LastSeen) >= m.idleTimeout {
+		player.Idle = true
+		m.notePresenceLocked(models.PresenceIdle, player)
+	}
+	m.mu.Unlock()
+	m.flushPresence()
+}
+
+func (m *Manager) expirePresence(player *playerPresence) {
+	m.mu.Lock()
+	if player.State == models.PresenceReconnecting && player.Connections == 0 {
+		player.State = models.PresenceGone
+		player.Since = time.Now()
+		player.graceTimer = nil
+		m.notePresenceLocked(models.PresenceLeft, player)
+	}
+	m.mu.Unlock()
+	m.flushPresence()
+}
+
+// notePresenceLocked queues a presence update to be broadcast once the lock
+// is released. Must be called with m.mu held.
+func (m *Manager) notePresenceLocked(event models.PresenceEvent, player *playerPresence) {
+	m.pendingPresence = append(m.pendingPresence, presenceUpdate{event: event, presence: player.PlayerPresence})
+}
+
+// SetPresenceListener sets who is told about presence changes, typically a
+// service that broadcasts them to the game
+func (m *Manager) SetPresenceListener(listener types.PresenceListener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.presenceListener = listener
+}
+
+// noteSpectatorsLocked queues a spectator count update for a game.
+// Must be called with m.mu held.
+func (m *Manager) noteSpectatorsLocked(gameID string) {
+	if m.pendingCounts == nil {
+		m.pendingCounts = make(map[string]bool)
+	}
+	m.pendingCounts[gameID] = true
+}
+
+// flushPresence hands queued presence and spectator count updates to the
+// listener
+func (m *Manager) flushPresence() {
+	m.mu.Lock()
+	updates := m.pendingPresence
+	m.pendingPresence = nil
+	counts := make(map[string]int, len(m.pendingCounts))
+	for gameID := range m.pendingCounts {
+		counts[gameID] = m.spectatorCountLocked(gameID)
+	}
+	m.pendingCounts = nil
+	listener := m.presenceListener
+	m.mu.Unlock()
+
+	if listener == nil {
+		return
+	}
+	for _, update := range updates {
+		listener.PresenceChanged(update.event, update.presence)
+	}
+	for gameID, count := range counts {
+		listener.SpectatorsChanged(gameID, count)
+	}
+}
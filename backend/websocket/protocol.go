@@ -0,0 +1,117 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+//go:generate go run ../cmd/protocolgen -ts ../../frontend/src/types/protocol.ts -schema ../../frontend/src/types/protocol.schema.json
+
+// ProtocolVersion is the version of the message envelope and payloads
+// this server speaks
+const ProtocolVersion = 1
+
+// SupportedVersions lists the protocol versions clients may negotiate
+var SupportedVersions = []int{1}
+
+const subprotocolPrefix = "taboo.v"
+
+// Subprotocol names a protocol version as a WebSocket subprotocol. Adding
+// "." and an encoding picks that encoding, e.g. "taboo.v1.msgpack".
+func Subprotocol(version int) string {
+	return subprotocolPrefix + strconv.Itoa(version)
+}
+
+// connectionProtocol is what a client negotiated when connecting
+type connectionProtocol struct {
+	version     int
+	codec       Codec
+	subprotocol string // Echoed back when the client offered subprotocols
+}
+
+// negotiate picks the protocol version and encoding of a connection: the
+// first supported "taboo.vN" or "taboo.vN.<encoding>" subprotocol the client
+// offers, or ?v=N and ?encoding= for clients that cannot set one. Clients
+// that ask for neither get the current version in JSON.
+func negotiate(r *http.Request) (connectionProtocol, error) {
+	if offered := r.Header.Values("Sec-WebSocket-Protocol"); len(offered) > 0 {
+		for _, header := range offered {
+			for _, name := range strings.Split(header, ",") {
+				name = strings.TrimSpace(name)
+				if !strings.HasPrefix(name, subprotocolPrefix) {
+					continue
+				}
+				version, encoding, _ := strings.Cut(strings.TrimPrefix(name, subprotocolPrefix), ".")
+				v, ok := parseVersion(version)
+				codec, known := codecs[encoding]
+				if encoding == "" {
+					codec, known = codecs[EncodingJSON], true
+				}
+				if ok && known {
+					return connectionProtocol{version: v, codec: codec, subprotocol: name}, nil
+				}
+			}
+		}
+		return connectionProtocol{}, errors.New("no supported protocol version offered")
+	}
+
+	negotiated := connectionProtocol{version: ProtocolVersion, codec: codecs[EncodingJSON]}
+	query := r.URL.Query()
+	if param := query.Get("v"); param != "" {
+		v, ok := parseVersion(param)
+		if !ok {
+			return connectionProtocol{}, fmt.Errorf("unsupported protocol version %s", param)
+		}
+		negotiated.version = v
+	}
+	if encoding := query.Get("encoding"); encoding != "" {
+		codec, known := codecs[encoding]
+		if !known {
+			return connectionProtocol{}, fmt.Errorf("unsupported encoding %s", encoding)
+		}
+		negotiated.codec = codec
+	}
+	return negotiated, nil
+}
+
+// upgrade negotiates the protocol and upgrades the connection, offering
+// permessage-deflate to clients that ask for it. It writes the rejection
+// itself when either fails.
+func upgrade(w http.ResponseWriter, r *http.Request) (*websocket.Conn, connectionProtocol, bool) {
+	negotiated, err := negotiate(r)
+	if err != nil {
+		rejectRequest(w, http.StatusBadRequest, err.Error())
+		return nil, negotiated, false
+	}
+
+	var header http.Header
+	if negotiated.subprotocol != "" {
+		header = http.Header{"Sec-WebSocket-Protocol": {negotiated.subprotocol}}
+	}
+	conn, err := upgrader.Upgrade(w, r, header)
+	if err != nil {
+		log.Printf("Failed to upgrade connection: %v", err)
+		return nil, negotiated, false
+	}
+	return conn, negotiated, true
+}
+
+func parseVersion(value string) (int, bool) {
+	version, err := strconv.Atoi(value)
+	return version, err == nil && supportsVersion(version)
+}
+
+func supportsVersion(version int) bool {
+	for _, supported := range SupportedVersions {
+		if version == supported {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// How long a game's sequence counter is kept after its last message
+const redisSeqTTL = 24 * time.Hour
+
+// RedisBroadcaster carries deliveries between instances over Redis pub/sub.
+// Sequence numbers come from a per-game Redis counter so every instance
+// stamps a message the same way.
+type RedisBroadcaster struct {
+	client *redis.Client
+	prefix string
+	pubsub *redis.PubSub
+}
+
+// NewRedisBroadcaster uses keys and channels starting with prefix, so
+// several deployments can share a Redis
+func NewRedisBroadcaster(client *redis.Client, prefix string) *RedisBroadcaster {
+	return &RedisBroadcaster{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (b *RedisBroadcaster) Publish(delivery Delivery) error {
+	ctx := context.Background()
+
+	if !delivery.Transient {
+		seqKey := b.prefix + "seq:" + delivery.GameID
+		pipe := b.client.TxPipeline()
+		seq := pipe.Incr(ctx, seqKey)
+		pipe.Expire(ctx, seqKey, redisSeqTTL)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("error numbering message: %v", err)
+		}
+		delivery.Seq = seq.Val()
+	}
+
+	payload, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("error encoding delivery: %v", err)
+	}
+	if err := b.client.Publish(ctx, b.prefix+"game:"+delivery.GameID, payload).Err(); err != nil {
+		return fmt.Errorf("error publishing message: %v", err)
+	}
+	return nil
+}
+
+// Subscribe returns once the subscription is confirmed, so nothing
+// published afterwards is missed
+func (b *RedisBroadcaster) Subscribe(deliver func(Delivery)) error {
+	ctx := context.Background()
+	pubsub := b.client.PSubscribe(ctx, b.prefix+"game:*")
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("error subscribing to broadcasts: %v", err)
+	}
+	b.pubsub = pubsub
+
+	go func() {
+		for msg := range pubsub.Channel() {
+			var delivery Delivery
+			if err := json.Unmarshal([]byte(msg.Payload), &delivery); err != nil {
+				log.Printf("Error decoding broadcast on %s: %v", msg.Channel, err)
+				continue
+			}
+			deliver(delivery)
+		}
+	}()
+	return nil
+}
+
+func (b *RedisBroadcaster) Close() error {
+	if b.pubsub == nil {
+		return nil
+	}
+	return b.pubsub.Close()
+}
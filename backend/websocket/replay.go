@@ -0,0 +1,89 @@
+package websocket
+
+import "encoding/json"
+
+// Number of outbound messages kept per game for reconnecting clients
+const defaultReplayBufferSize = 256
+
+// bufferedMessage is a stamped outbound message together with the players
+// it was addressed to, so a replay never reaches anyone else
+type bufferedMessage struct {
+	seq     int64
+	data    []byte
+	include func(playerID string) bool
+}
+
+// replayBuffer keeps the most recent outbound messages of one game
+type replayBuffer struct {
+	lastSeq  int64
+	messages []bufferedMessage
+
+	// A client that last saw a message numbered below this one missed a
+	// message that could not be sent, and needs a snapshot
+	resyncBelow int64
+}
+
+// markMissed records that a message after the last one kept was lost
+func (b *replayBuffer) markMissed() {
+	b.resyncBelow = b.lastSeq + 1
+}
+
+// add stamps a message with seq, or the game's next sequence number when
+// seq is 0, and keeps it, dropping the oldest message once the buffer is full
+func (b *replayBuffer) add(data []byte, include func(playerID string) bool, limit int, seq int64) bufferedMessage {
+	if seq == 0 {
+		seq = b.lastSeq + 1
+	}
+	if seq > b.lastSeq {
+		b.lastSeq = seq
+	}
+	msg := bufferedMessage{
+		seq:     seq,
+		data:    stampSequence(data, seq),
+		include: include,
+	}
+
+	b.messages = append(b.messages, msg)
+	if len(b.messages) > limit {
+		b.messages = append([]bufferedMessage(nil), b.messages[len(b.messages)-limit:]...)
+	}
+	return msg
+}
+
+// since returns the buffered messages for a player sent after seq. complete
+// is false when some of them have already been dropped from the buffer or
+// seq is ahead of the game or before a lost message, in which case the
+// client needs a snapshot.
+func (b *replayBuffer) since(seq int64, playerID string) (missed []bufferedMessage, complete bool) {
+	if seq > b.lastSeq || seq < b.resyncBelow {
+		return nil, false
+	}
+
+	complete = true
+	if len(b.messages) == 0 || b.messages[0].seq > seq+1 {
+		complete = seq == b.lastSeq
+	}
+
+	for _, msg := range b.messages {
+		if msg.seq > seq && msg.include(playerID) {
+			missed = append(missed, msg)
+		}
+	}
+	return missed, complete
+}
+
+// stampSequence adds the sequence number to a JSON object message. Anything
+// else is delivered as is.
+func stampSequence(data []byte, seq int64) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return data
+	}
+
+	fields["seq"], _ = json.Marshal(seq)
+	stamped, err := json.Marshal(fields)
+	if err != nil {
+		return data
+	}
+	return stamped
+}
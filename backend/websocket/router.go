@@ -0,0 +1,228 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"taboo-game/models"
+	"taboo-game/types"
+)
+
+// routeError is sent back to the sender when an inbound message cannot be handled
+type routeError struct {
+	code    string
+	message string
+}
+
+func (e *routeError) Error() string {
+	return e.message
+}
+
+// SetGameEvents sets the service inbound actions are dispatched to
+func (m *Manager) SetGameEvents(gameEvents types.GameEventsServiceInterface) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gameEvents = gameEvents
+}
+
+// handleInbound parses a frame from a client and dispatches it to the game
+// events service. Frames may leave out the version, which then defaults to
+// the connection's. Failures are reported back to the sender only.
+func (m *Manager) handleInbound(client *Client, data []byte) {
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		client.sendError("", &routeError{ErrorCodeInvalidMessage, "message must be a JSON object"})
+		return
+	}
+
+	if client.Spectator {
+		client.sendError(msg.Type, &routeError{ErrorCodeReadOnly, "spectators cannot send actions"})
+		return
+	}
+
+	if msg.Version != 0 && msg.Version != client.Version {
+		client.sendError(msg.Type, versionMismatch(msg.Version))
+		return
+	}
+
+	if msg.GameID != client.GameID || msg.PlayerID != client.ID {
+		client.sendError(msg.Type, &routeError{ErrorCodeIdentityMismatch, "gameId and playerId must match the connection"})
+		return
+	}
+
+	m.markActive(client.GameID, client.ID)
+	if err := m.dispatch(msg); err != nil {
+		client.sendError(msg.Type, err)
+	}
+}
+
+func (m *Manager) dispatch(msg Message) error {
+	m.mu.RLock()
+	gameEvents := m.gameEvents
+	m.mu.RUnlock()
+
+	if gameEvents == nil {
+		return &routeError{ErrorCodeUnavailable, "game events are not available"}
+	}
+
+	spec, known := Messages[msg.Type]
+	if !known || !spec.FromClient {
+		return &routeError{ErrorCodeUnknownType, "unknown message type: " + string(msg.Type)}
+	}
+
+	var err error
+	switch msg.Type {
+	case StartStage:
+		var payload StartStagePayload
+		if err := msg.DecodePayload(&payload); err != nil || payload.StageNum <= 0 {
+			return invalidPayload("stageNum")
+		}
+		err = gameEvents.StartStage(msg.GameID, msg.PlayerID, payload.StageNum)
+
+	case GiveClue:
+		var payload GiveCluePayload
+		if err := msg.DecodePayload(&payload); err != nil || payload.Clue == "" {
+			return invalidPayload("clue")
+		}
+		err = gameEvents.HandleClue(msg.GameID, msg.PlayerID, payload.Clue)
+
+	case MakeGuess:
+		var payload MakeGuessPayload
+		if err := msg.DecodePayload(&payload); err != nil || payload.Guess == "" {
+			return invalidPayload("guess")
+		}
+		err = gameEvents.HandleGuess(msg.GameID, msg.PlayerID, payload.Guess)
+
+	case ReportViolation:
+		var payload ReportViolationPayload
+		if err := msg.DecodePayload(&payload); err != nil || payload.ViolationType == "" {
+			return invalidPayload("violationType")
+		}
+		err = gameEvents.HandleViolation(msg.GameID, msg.PlayerID, payload.ViolationType)
+
+	case ClueSpoken:
+		transcripts, ok := gameEvents.(types.TranscriptServiceInterface)
+		if !ok {
+			return &routeError{ErrorCodeUnavailable, "clue transcripts are not supported"}
+		}
+		var payload ClueSpokenPayload
+		if err := msg.DecodePayload(&payload); err != nil || payload.Text == "" {
+			return invalidPayload("text")
+		}
+		err = transcripts.HandleTranscript(msg.GameID, msg.PlayerID, models.TranscriptChunk{
+			Text:        payload.Text,
+			TimestampMS: payload.TimestampMS,
+			DurationMS:  payload.DurationMS,
+		})
+
+	case ChatSend, ChatHistory:
+		chat, ok := gameEvents.(types.ChatServiceInterface)
+		if !ok {
+			return &routeError{ErrorCodeUnavailable, "chat is not supported"}
+		}
+		if msg.Type == ChatHistory {
+			err = chat.SendChatHistory(msg.GameID, msg.PlayerID)
+			break
+		}
+		var payload ChatSendPayload
+		if err := msg.DecodePayload(&payload); err != nil || payload.Text == "" {
+			return invalidPayload("text")
+		}
+		if payload.Channel == "" {
+			payload.Channel = models.ChatChannelGame
+		}
+		_, err = chat.SendChat(msg.GameID, msg.PlayerID, payload.Channel, payload.Text)
+
+	case VoiceRooms, VoiceOffer, VoiceAnswer, VoiceCandidate:
+		voice, ok := gameEvents.(types.VoiceServiceInterface)
+		if !ok {
+			return &routeError{ErrorCodeUnavailable, "voice chat is not supported"}
+		}
+		if msg.Type == VoiceRooms {
+			err = voice.SendVoiceRooms(msg.GameID, msg.PlayerID)
+		} else {
+			err = m.relaySignal(voice, msg)
+		}
+
+	case DisputeViolation, VoteViolation, ResolveViolation:
+		violations, ok := gameEvents.(types.ViolationServiceInterface)
+		if !ok {
+			return &routeError{ErrorCodeUnavailable, "violation disputes are not supported"}
+		}
+		err = dispatchViolationAction(violations, msg)
+	}
+
+	if err != nil {
+		var routeErr *routeError
+		if errors.As(err, &routeErr) {
+			return err
+		}
+		return &routeError{ErrorCodeActionFailed, err.Error()}
+	}
+	return nil
+}
+
+// relaySignal forwards WebRTC signaling to the one peer it is addressed to,
+// once the voice service allows the two players to talk in the room
+func (m *Manager) relaySignal(voice types.VoiceServiceInterface, msg Message) error {
+	var payload VoiceSignalPayload
+	if err := msg.DecodePayload(&payload); err != nil || payload.Room == "" {
+		return invalidPayload("room")
+	}
+	if payload.To == "" || payload.To == msg.PlayerID {
+		return invalidPayload("to")
+	}
+	if msg.Type == VoiceCandidate && payload.Candidate == nil {
+		return invalidPayload("candidate")
+	}
+	if msg.Type != VoiceCandidate && payload.SDP == "" {
+		return invalidPayload("sdp")
+	}
+
+	if err := voice.AuthorizeSignal(msg.GameID, msg.PlayerID, payload.To, payload.Room); err != nil {
+		return err
+	}
+
+	relayed := NewMessage(msg.Type, msg.GameID, msg.PlayerID, payload)
+	m.publish(Delivery{
+		GameID:    msg.GameID,
+		Audience:  AudiencePlayers,
+		Players:   []string{payload.To},
+		Transient: true,
+		Message:   relayed.Encode(),
+	})
+	return nil
+}
+
+func dispatchViolationAction(violations types.ViolationServiceInterface, msg Message) error {
+	if msg.Type == DisputeViolation {
+		var payload DisputeViolationPayload
+		if err := msg.DecodePayload(&payload); err != nil || payload.ViolationID == "" {
+			return invalidPayload("violationId")
+		}
+		_, err := violations.DisputeViolation(msg.GameID, payload.ViolationID, msg.PlayerID)
+		return err
+	}
+
+	var payload ViolationDecisionPayload
+	if err := msg.DecodePayload(&payload); err != nil || payload.ViolationID == "" {
+		return invalidPayload("violationId")
+	}
+	if payload.Uphold == nil {
+		return invalidPayload("uphold")
+	}
+	if msg.Type == VoteViolation {
+		_, err := violations.VoteOnViolation(msg.GameID, payload.ViolationID, msg.PlayerID, *payload.Uphold)
+		return err
+	}
+	_, err := violations.ResolveViolation(msg.GameID, payload.ViolationID, msg.PlayerID, *payload.Uphold)
+	return err
+}
+
+func invalidPayload(field string) error {
+	return &routeError{ErrorCodeInvalidPayload, "payload field " + field + " is missing or invalid"}
+}
+
+func versionMismatch(version int) error {
+	return &routeError{ErrorCodeVersionMismatch, fmt.Sprintf("protocol version %d is not supported", version)}
+}
@@ -0,0 +1,150 @@
+package websocket
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Longest delay a spectator can ask for
+const maxSpectatorDelay = 5 * time.Minute
+
+// Room for messages waiting out a spectator's delay
+const delayQueueSize = 1024
+
+// spectatorClient is implemented by clients that only watch a game
+type spectatorClient interface {
+	IsSpectator() bool
+}
+
+func isSpectator(client interface{}) bool {
+	spectator, ok := client.(spectatorClient)
+	return ok && spectator.IsSpectator()
+}
+
+// newSpectatorID gives each spectator its own ID. It never matches a
+// player, so spectators only get what is sent to the whole game.
+func newSpectatorID() string {
+	return "spectator-" + uuid.New().String()
+}
+
+// spectatorDelay reads the ?delay=N seconds query parameter
+func spectatorDelay(r *http.Request) time.Duration {
+	seconds, err := strconv.Atoi(r.URL.Query().Get("delay"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	delay := time.Duration(seconds) * time.Second
+	if delay > maxSpectatorDelay {
+		return maxSpectatorDelay
+	}
+	return delay
+}
+
+// HandleSpectator upgrades a read-only connection that watches a game,
+// optionally delayed by ?delay=N seconds
+func (m *Manager) HandleSpectator(w http.ResponseWriter, r *http.Request, gameID string) {
+	if !m.admit(w, r, gameID, "") {
+		return
+	}
+
+	conn, negotiated, ok := upgrade(w, r)
+	if !ok {
+		return
+	}
+
+	client := NewClient(newSpectatorID(), gameID, conn)
+	client.manager = m
+	client.Version = negotiated.version
+	client.codec = negotiated.codec
+	client.Spectator = true
+	if delay := spectatorDelay(r); delay > 0 {
+		client.delay = startDelayLine(delay, client.Send)
+	}
+	m.Register(client)
+
+	go client.Read()
+	go client.Write()
+}
+
+// SpectatorCount returns how many spectators are watching a game
+func (m *Manager) SpectatorCount(gameID string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.spectatorCountLocked(gameID)
+}
+
+// spectatorCountLocked must be called with m.mu held
+func (m *Manager) spectatorCountLocked(gameID string) int {
+	count := 0
+	for client := range m.gameConnections[gameID] {
+		if isSpectator(client) {
+			count++
+		}
+	}
+	return count
+}
+
+type delayedMessage struct {
+	at   time.Time
+	data []byte
+}
+
+// delayLine forwards messages to a client's send queue a fixed time after
+// they were sent, keeping their order
+type delayLine struct {
+	delay time.Duration
+	in    chan delayedMessage
+	done  chan struct{}
+}
+
+// startDelayLine forwards to out until stopped, then closes out
+func startDelayLine(delay time.Duration, out chan []byte) *delayLine {
+	line := &delayLine{
+		delay: delay,
+		in:    make(chan delayedMessage, delayQueueSize),
+		done:  make(chan struct{}),
+	}
+	go line.run(out)
+	return line
+}
+
+func (l *delayLine) push(data []byte) bool {
+	select {
+	case l.in <- delayedMessage{at: time.Now().Add(l.delay), data: data}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *delayLine) stop() {
+	close(l.done)
+}
+
+func (l *delayLine) run(out chan []byte) {
+	defer close(out)
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case msg := <-l.in:
+			timer := time.NewTimer(time.Until(msg.at))
+			select {
+			case <-timer.C:
+			case <-l.done:
+				timer.Stop()
+				return
+			}
+
+			select {
+			case out <- msg.data:
+			case <-l.done:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,516 @@
+// Code generated by protocolgen from backend/websocket. DO NOT EDIT.
+
+export const PROTOCOL_VERSION = 1;
+export const PROTOCOL_SUBPROTOCOL = 'taboo.v1';
+export const PROTOCOL_ENCODINGS = ['json', 'msgpack'] as const;
+
+export type MessageType =
+  | 'CARD_DRAWN'
+  | 'CHAT_HISTORY'
+  | 'CHAT_MESSAGE'
+  | 'CHAT_SEND'
+  | 'CLUE_FLAGGED'
+  | 'CLUE_SPOKEN'
+  | 'DISPUTE_VIOLATION'
+  | 'ERROR'
+  | 'GAME_END'
+  | 'GIVE_CLUE'
+  | 'GUESS_RESULT'
+  | 'MAKE_GUESS'
+  | 'PRESENCE_UPDATE'
+  | 'REPORT_VIOLATION'
+  | 'RESOLVE_VIOLATION'
+  | 'SCORE_UPDATE'
+  | 'SPECTATOR_COUNT'
+  | 'STAGE_END'
+  | 'START_STAGE'
+  | 'SYNC_SNAPSHOT'
+  | 'TIMER_UPDATE'
+  | 'TURN_CHANGE'
+  | 'VIOLATION_DISPUTED'
+  | 'VIOLATION_REPORTED'
+  | 'VIOLATION_RESOLVED'
+  | 'VIOLATION_VOTE'
+  | 'VOICE_ANSWER'
+  | 'VOICE_CANDIDATE'
+  | 'VOICE_OFFER'
+  | 'VOICE_ROOMS'
+  | 'VOTE_VIOLATION';
+
+export interface Message<T extends MessageType = MessageType, P = unknown> {
+  v: typeof PROTOCOL_VERSION;
+  type: T;
+  gameId: string;
+  playerId: string;
+  payload: P;
+  seq?: number;
+}
+
+export interface CardDrawnPayload {
+  stageNum: number;
+  wordCard?: WordCard;
+}
+
+export interface ChatHistoryPayload {
+  messages?: ChatMessage[];
+}
+
+export interface ChatMessage {
+  id: string;
+  gameId: string;
+  channel: string;
+  playerId: string;
+  text: string;
+  sentAt: string;
+}
+
+export interface ChatMessagePayload {
+  message: ChatMessage | null;
+}
+
+export interface ChatSendPayload {
+  channel?: string;
+  text: string;
+}
+
+export interface ClueEvidence {
+  clue: string;
+  clueWord: string;
+  cardWord: string;
+  match: ClueMatch;
+  reason: string;
+}
+
+export interface ClueFlag {
+  evidence: ClueEvidence | null;
+  violationType: ViolationType;
+}
+
+export interface ClueFlaggedPayload {
+  flags: ClueFlag[];
+  timestampMs?: number;
+}
+
+export type ClueMatch = string;
+
+export interface ClueRules {
+  soundsLike: SoundsLikeSensitivity;
+}
+
+export interface ClueSpokenPayload {
+  text: string;
+  timestampMs: number;
+  durationMs?: number;
+}
+
+export interface DisputeViolationPayload {
+  violationId: string;
+}
+
+export type EmptyPayload = Record<string, never>;
+
+export interface ErrorPayload {
+  code: string;
+  message: string;
+  requestType: MessageType;
+}
+
+export interface Game {
+  id: string;
+  createdAt: string;
+  status: GameStatus;
+  teams: Team[];
+  matches: Match[];
+  rules: GameRules;
+  hostId: string;
+  version: number;
+}
+
+export interface GameRules {
+  violationRules: Record<string, ViolationRule>;
+  guessing?: GuessRules;
+  clues?: ClueRules;
+}
+
+export interface GameSnapshot {
+  game: Game | null;
+  match?: MatchDetails;
+  scores: ScoreEntry[];
+  wordCard?: WordCard;
+  remaining: number;
+  restoredAt?: string;
+}
+
+export type GameStatus = string;
+
+export interface GiveCluePayload {
+  clue: string;
+}
+
+export interface GuessResultPayload {
+  guess: string;
+  verdict: GuessVerdict;
+  answer?: string;
+}
+
+export interface GuessRules {
+  maxTypos: number;
+  closeDistance: number;
+}
+
+export type GuessVerdict = string;
+
+export interface ICECandidate {
+  candidate: string;
+  sdpMid?: string;
+  sdpMLineIndex?: number;
+}
+
+export interface MakeGuessPayload {
+  guess: string;
+}
+
+export interface Match {
+  id: string;
+  gameId: string;
+  number: number;
+  status: MatchStatus;
+  stages: Stage[];
+  startedAt: string;
+  endedAt: string;
+}
+
+export interface MatchDetails {
+  id: string;
+  gameId: string;
+  status: MatchStatus;
+  teamATurn: boolean;
+  teamAScore: number;
+  teamBScore: number;
+  teamAPlayers: string[];
+  teamBPlayers: string[];
+  currentWord: string;
+  currentStage: MatchStage | null;
+  violationCounts: Record<string, number>;
+}
+
+export interface MatchStage {
+  id: string;
+  matchId: string;
+  number: number;
+  activeTeamId: string;
+  spottingTeamId: string;
+  clueGivers: string[];
+  guessers: string[];
+  spotters: string[];
+  status: string;
+  teamAScore: number;
+  teamBScore: number;
+  violationCounts: Record<string, number>;
+  transcript?: TranscriptChunk[];
+}
+
+export type MatchStatus = string;
+
+export interface Player {
+  id: string;
+  name: string;
+  teamId: string;
+  joinedAt: string;
+}
+
+export interface PlayerPresence {
+  gameId: string;
+  playerId: string;
+  state: PresenceState;
+  connections: number;
+  idle: boolean;
+  since: string;
+  lastSeen: string;
+}
+
+export type PresenceEvent = string;
+
+export type PresenceState = string;
+
+export interface PresenceUpdatePayload {
+  event: PresenceEvent;
+  presence: PlayerPresence;
+}
+
+export interface ReportViolationPayload {
+  violationType: string;
+}
+
+export interface ScoreEntry {
+  seq: number;
+  gameId: string;
+  matchId: string;
+  stageId?: string;
+  teamId: string;
+  points: number;
+  reason: ScoreReason;
+  violationType?: ViolationType;
+  cardId?: string;
+  timestamp: string;
+}
+
+export type ScoreReason = string;
+
+export interface ScoreUpdatePayload {
+  matchId: string;
+  teamAScore: number;
+  teamBScore: number;
+  scoringTeam: string;
+  stageId?: string;
+  stageTeamAScore: number;
+  stageTeamBScore: number;
+  entries: ScoreEntry[];
+}
+
+export type SoundsLikeSensitivity = string;
+
+export interface SpectatorCountPayload {
+  count: number;
+}
+
+export interface Stage {
+  id: string;
+  matchId: string;
+  number: number;
+  activeTeamId: string;
+  clueGivers: string[];
+  guessers: string[];
+  spottingTeamId: string;
+  spotters: string[];
+  score: number;
+  duration: number;
+  status: StageStatus;
+  startedAt: string;
+  endedAt: string;
+}
+
+export interface StageEndPayload {
+  summary: StageSummary;
+}
+
+export type StageStatus = string;
+
+export interface StageSummary {
+  stageId: string;
+  stageNumber: number;
+  activeTeamId: string;
+  teamAScore: number;
+  teamBScore: number;
+  transcript: TranscriptChunk[];
+}
+
+export interface StartStagePayload {
+  stageNum: number;
+  duration?: number;
+  wordCard?: WordCard;
+}
+
+export interface SyncSnapshotPayload {
+  state: GameSnapshot | null;
+}
+
+export interface Team {
+  id: string;
+  name: string;
+  gameId: string;
+  players: Player[];
+  score: number;
+  size: number;
+}
+
+export interface TimerUpdatePayload {
+  remaining: number;
+}
+
+export interface TranscriptChunk {
+  playerId: string;
+  text: string;
+  timestampMs: number;
+  durationMs?: number;
+  flags?: ClueEvidence[];
+}
+
+export interface TurnChangePayload {
+  matchId: string;
+  activeTeam: string;
+  timeLeft: number;
+}
+
+export interface Violation {
+  id: string;
+  gameId: string;
+  matchId: string;
+  stageId: string;
+  cardId: string;
+  type: ViolationType;
+  reporterId: string;
+  automatic?: boolean;
+  evidence?: ClueEvidence;
+  offendingTeamId: string;
+  status: ViolationStatus;
+  disputedBy?: string;
+  votes: Record<string, boolean>;
+  eligibleVoters: string[];
+  resolvedBy?: string;
+  reportedAt: string;
+  disputeDeadline: string;
+  resolvedAt: string;
+}
+
+export interface ViolationDecisionPayload {
+  violationId: string;
+  uphold: boolean | null;
+}
+
+export interface ViolationPayload {
+  violation: Violation | null;
+}
+
+export interface ViolationRule {
+  spotterReward: number;
+  offenderPenalty: number;
+}
+
+export type ViolationStatus = string;
+
+export type ViolationType = string;
+
+export interface VoiceRoom {
+  id: string;
+  hears: string[];
+  heardBy: string[];
+}
+
+export interface VoiceRoomsPayload {
+  rooms?: VoiceRoom[];
+}
+
+export interface VoiceSignalPayload {
+  room: string;
+  to: string;
+  sdp?: string;
+  candidate?: ICECandidate;
+}
+
+export interface WordCard {
+  id: string;
+  targetWord: string;
+  alternateAnswers?: string[];
+  tabooWords: string[];
+  difficulty: number;
+  category: string;
+}
+
+export type CardDrawnMessage = Message<'CARD_DRAWN', CardDrawnPayload>;
+export type ChatHistoryMessage = Message<'CHAT_HISTORY', ChatHistoryPayload>;
+export type ChatMessageMessage = Message<'CHAT_MESSAGE', ChatMessagePayload>;
+export type ChatSendMessage = Message<'CHAT_SEND', ChatSendPayload>;
+export type ClueFlaggedMessage = Message<'CLUE_FLAGGED', ClueFlaggedPayload>;
+export type ClueSpokenMessage = Message<'CLUE_SPOKEN', ClueSpokenPayload>;
+export type DisputeViolationMessage = Message<'DISPUTE_VIOLATION', DisputeViolationPayload>;
+export type ErrorMessage = Message<'ERROR', ErrorPayload>;
+export type GameEndMessage = Message<'GAME_END', EmptyPayload>;
+export type GiveClueMessage = Message<'GIVE_CLUE', GiveCluePayload>;
+export type GuessResultMessage = Message<'GUESS_RESULT', GuessResultPayload>;
+export type MakeGuessMessage = Message<'MAKE_GUESS', MakeGuessPayload>;
+export type PresenceUpdateMessage = Message<'PRESENCE_UPDATE', PresenceUpdatePayload>;
+export type ReportViolationMessage = Message<'REPORT_VIOLATION', ReportViolationPayload>;
+export type ResolveViolationMessage = Message<'RESOLVE_VIOLATION', ViolationDecisionPayload>;
+export type ScoreUpdateMessage = Message<'SCORE_UPDATE', ScoreUpdatePayload>;
+export type SpectatorCountMessage = Message<'SPECTATOR_COUNT', SpectatorCountPayload>;
+export type StageEndMessage = Message<'STAGE_END', StageEndPayload>;
+export type StartStageMessage = Message<'START_STAGE', StartStagePayload>;
+export type SyncSnapshotMessage = Message<'SYNC_SNAPSHOT', SyncSnapshotPayload>;
+export type TimerUpdateMessage = Message<'TIMER_UPDATE', TimerUpdatePayload>;
+export type TurnChangeMessage = Message<'TURN_CHANGE', TurnChangePayload>;
+export type ViolationDisputedMessage = Message<'VIOLATION_DISPUTED', ViolationPayload>;
+export type ViolationReportedMessage = Message<'VIOLATION_REPORTED', ViolationPayload>;
+export type ViolationResolvedMessage = Message<'VIOLATION_RESOLVED', ViolationPayload>;
+export type ViolationVoteMessage = Message<'VIOLATION_VOTE', ViolationPayload>;
+export type VoiceAnswerMessage = Message<'VOICE_ANSWER', VoiceSignalPayload>;
+export type VoiceCandidateMessage = Message<'VOICE_CANDIDATE', VoiceSignalPayload>;
+export type VoiceOfferMessage = Message<'VOICE_OFFER', VoiceSignalPayload>;
+export type VoiceRoomsMessage = Message<'VOICE_ROOMS', VoiceRoomsPayload>;
+export type VoteViolationMessage = Message<'VOTE_VIOLATION', ViolationDecisionPayload>;
+
+export interface PayloadByType {
+  CARD_DRAWN: CardDrawnPayload;
+  CHAT_HISTORY: ChatHistoryPayload;
+  CHAT_MESSAGE: ChatMessagePayload;
+  CHAT_SEND: ChatSendPayload;
+  CLUE_FLAGGED: ClueFlaggedPayload;
+  CLUE_SPOKEN: ClueSpokenPayload;
+  DISPUTE_VIOLATION: DisputeViolationPayload;
+  ERROR: ErrorPayload;
+  GAME_END: EmptyPayload;
+  GIVE_CLUE: GiveCluePayload;
+  GUESS_RESULT: GuessResultPayload;
+  MAKE_GUESS: MakeGuessPayload;
+  PRESENCE_UPDATE: PresenceUpdatePayload;
+  REPORT_VIOLATION: ReportViolationPayload;
+  RESOLVE_VIOLATION: ViolationDecisionPayload;
+  SCORE_UPDATE: ScoreUpdatePayload;
+  SPECTATOR_COUNT: SpectatorCountPayload;
+  STAGE_END: StageEndPayload;
+  START_STAGE: StartStagePayload;
+  SYNC_SNAPSHOT: SyncSnapshotPayload;
+  TIMER_UPDATE: TimerUpdatePayload;
+  TURN_CHANGE: TurnChangePayload;
+  VIOLATION_DISPUTED: ViolationPayload;
+  VIOLATION_REPORTED: ViolationPayload;
+  VIOLATION_RESOLVED: ViolationPayload;
+  VIOLATION_VOTE: ViolationPayload;
+  VOICE_ANSWER: VoiceSignalPayload;
+  VOICE_CANDIDATE: VoiceSignalPayload;
+  VOICE_OFFER: VoiceSignalPayload;
+  VOICE_ROOMS: VoiceRoomsPayload;
+  VOTE_VIOLATION: ViolationDecisionPayload;
+}
+
+export type ClientMessage =
+  | ChatHistoryMessage
+  | ChatSendMessage
+  | ClueSpokenMessage
+  | DisputeViolationMessage
+  | GiveClueMessage
+  | MakeGuessMessage
+  | ReportViolationMessage
+  | ResolveViolationMessage
+  | StartStageMessage
+  | VoiceAnswerMessage
+  | VoiceCandidateMessage
+  | VoiceOfferMessage
+  | VoiceRoomsMessage
+  | VoteViolationMessage;
+
+export type ServerMessage =
+  | CardDrawnMessage
+  | ChatHistoryMessage
+  | ChatMessageMessage
+  | ClueFlaggedMessage
+  | ErrorMessage
+  | GameEndMessage
+  | GiveClueMessage
+  | GuessResultMessage
+  | PresenceUpdateMessage
+  | ScoreUpdateMessage
+  | SpectatorCountMessage
+  | StageEndMessage
+  | StartStageMessage
+  | SyncSnapshotMessage
+  | TimerUpdateMessage
+  | TurnChangeMessage
+  | ViolationDisputedMessage
+  | ViolationReportedMessage
+  | ViolationResolvedMessage
+  | ViolationVoteMessage
+  | VoiceAnswerMessage
+  | VoiceCandidateMessage
+  | VoiceOfferMessage
+  | VoiceRoomsMessage;